@@ -2,8 +2,15 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
@@ -13,6 +20,7 @@ import (
 	"github.com/cousine/neovim-mcp/internal/mcp/resources"
 	"github.com/cousine/neovim-mcp/internal/mcp/tools"
 	"github.com/cousine/neovim-mcp/internal/nvim"
+	"github.com/cousine/neovim-mcp/internal/types"
 )
 
 // Build information, set via -ldflags during build
@@ -47,6 +55,11 @@ func run() error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	// Command line flags override the environment
+	if fErr := parseFlags(cfg, os.Args[1:]); fErr != nil {
+		return fErr
+	}
+
 	// Initialize logger
 	logLevel := logger.ParseLevel(cfg.Log.Level)
 	if lErr := logger.Init(logger.Config{
@@ -70,28 +83,51 @@ func run() error {
 		"built", date)
 	logger.Debug("Configuration loaded",
 		"socket", cfg.SocketAddress,
+		"instances", len(cfg.Instances),
+		"timeout", cfg.Timeout,
+		"transport", cfg.Transport,
+		"discover", cfg.Discover,
+		"embed", cfg.Embed.Enabled,
+		"reconnect", !cfg.Reconnect.Disabled,
 		"log_level", cfg.Log.Level,
 		"log_file", cfg.Log.FilePath)
 
-	// Connect to Neovim
-	nvimClient, err := nvim.NewClient(cfg.SocketAddress)
-	if err != nil {
-		logger.Error("Failed to connect to Neovim", "error", err)
-		return fmt.Errorf("failed to connect to neovim: %w", err)
-	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	defer func() {
-		cErr := nvimClient.Close()
-		if cErr != nil {
-			logger.Error("Failed to close neovim client", "error", cErr)
-			panic(cErr.Error())
-		}
-	}()
+	// Connect to Neovim, the clients keep reconnecting when neovim restarts
+	nvimOpts := []nvim.Option{nvim.WithHealthCheck(cfg.Reconnect.Interval)}
+	if !cfg.Reconnect.Disabled {
+		nvimOpts = append(nvimOpts, nvim.WithReconnect(cfg.Reconnect.Backoff))
+	}
 
-	logger.Info("Connected to Neovim", "address", cfg.SocketAddress)
+	defer closeInstances()
 
 	// Create MCP server
-	server := mcpserver.NewServer(nvimClient)
+	discover := discoverInstance(nvimOpts)
+
+	serverOpts := []mcpserver.ServerOption{mcpserver.WithToolTimeout(cfg.Timeout)}
+	if cfg.Discover && !cfg.Embed.Enabled {
+		serverOpts = append(serverOpts, mcpserver.WithDiscovery(discover))
+	}
+
+	server := mcpserver.NewServer(nil, serverOpts...)
+
+	switch {
+	case cfg.Embed.Enabled:
+		if eErr := addEmbeddedInstance(cfg.Embed, nvimOpts); eErr != nil {
+			return eErr
+		}
+	case cfg.Discover:
+		// a failed discovery is retried with the roots of each client session
+		if dErr := mcpserver.DiscoverInstance(ctx, discover, nil); dErr != nil {
+			logger.Warn("Failed to discover neovim, waiting for client roots", "error", dErr)
+		}
+	default:
+		if aErr := addInstances(cfg.Instances, nvimOpts); aErr != nil {
+			return aErr
+		}
+	}
 
 	// Register all tools
 	tools.RegisterAllTools(server)
@@ -101,14 +137,173 @@ func run() error {
 	resources.RegisterAllResources(server)
 	logger.Debug("Registered all resources")
 
-	// Run server on stdio
-	ctx := context.Background()
-	transport := &mcp.StdioTransport{}
+	switch cfg.Transport {
+	case config.TransportStdio:
+		return serveStdio(ctx, server)
+	case config.TransportHTTP:
+		return serveHTTP(ctx, server, cfg.HTTP)
+	default:
+		return fmt.Errorf("unknown transport `%s`, expected %s or %s",
+			cfg.Transport, config.TransportStdio, config.TransportHTTP)
+	}
+}
+
+// addInstances connects to the configured neovim instances and registers them
+func addInstances(instances []config.InstanceConfig, nvimOpts []nvim.Option) error {
+	for _, instance := range instances {
+		nvimClient, err := nvim.NewClient(instance.Address, nvimOpts...)
+		if err != nil {
+			logger.Error("Failed to connect to Neovim", "instance", instance.Name, "error", err)
+			return fmt.Errorf("failed to connect to neovim instance `%s`: %w", instance.Name, err)
+		}
+
+		err = mcpserver.AddInstance(&types.Instance{
+			Name:    instance.Name,
+			Address: instance.Address,
+			Client:  nvimClient,
+		})
+		if err != nil {
+			_ = nvimClient.Close()
+			return fmt.Errorf("failed to add neovim instance: %w", err)
+		}
+
+		logger.Info("Neovim client started", "instance", instance.Name, "address", instance.Address)
+	}
+
+	return nil
+}
+
+// addEmbeddedInstance starts a headless neovim owned by the server and registers it
+func addEmbeddedInstance(cfg config.EmbedConfig, nvimOpts []nvim.Option) error {
+	nvimClient, err := nvim.NewEmbeddedClient(nvim.EmbedConfig{
+		Command:  cfg.Command,
+		InitFile: cfg.Init,
+		Listen:   cfg.Listen,
+	}, nvimOpts...)
+	if err != nil {
+		logger.Error("Failed to start embedded Neovim", "error", err)
+		return fmt.Errorf("failed to start embedded neovim: %w", err)
+	}
+
+	address := cfg.Listen
+	if address == "" {
+		address = nvim.EmbedAddress
+	}
+
+	err = mcpserver.AddInstance(&types.Instance{
+		Name:    mcpserver.DefaultInstance,
+		Address: address,
+		Client:  nvimClient,
+	})
+	if err != nil {
+		_ = nvimClient.Close()
+		return fmt.Errorf("failed to add embedded neovim instance: %w", err)
+	}
+
+	if cfg.Listen != "" {
+		logger.Info("Embedded Neovim started", "command", cfg.Command, "init", cfg.Init,
+			"attach", "nvim --remote-ui --server "+cfg.Listen)
+	} else {
+		logger.Info("Embedded Neovim started", "command", cfg.Command, "init", cfg.Init)
+	}
+
+	return nil
+}
+
+// discoverInstance connects to the running neovim inherited through $NVIM or working
+// in the server's directory or the client roots
+func discoverInstance(nvimOpts []nvim.Option) mcpserver.DiscoverFunc {
+	return func(ctx context.Context, dirs []string) (*types.Instance, error) {
+		if cwd, err := os.Getwd(); err == nil {
+			dirs = append(slices.Clip(dirs), cwd)
+		}
+
+		candidate, err := nvim.Discover(ctx, nvim.DiscoverOptions{
+			Dirs:      dirs,
+			Inherited: os.Getenv("NVIM"),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		nvimClient, err := nvim.NewClient(candidate.Address, nvimOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to discovered neovim: %w", err)
+		}
+
+		return &types.Instance{
+			Name:    mcpserver.DefaultInstance,
+			Address: candidate.Address,
+			Client:  nvimClient,
+		}, nil
+	}
+}
+
+// closeInstances closes the clients of all registered neovim instances
+func closeInstances() {
+	instances, _ := mcpserver.ListInstances()
+	for _, instance := range instances {
+		if err := instance.Client.Close(); err != nil {
+			logger.Error("Failed to close neovim client", "instance", instance.Name, "error", err)
+		}
+	}
+}
+
+// parseFlags applies the command line flags to cfg
+func parseFlags(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("neovim-mcp", flag.ContinueOnError)
+	flags.StringVar(&cfg.Transport, "transport", cfg.Transport, "MCP transport: stdio or http")
+	flags.StringVar(&cfg.HTTP.Address, "http-address", cfg.HTTP.Address, "bind address of the http transport")
+	flags.BoolVar(&cfg.Embed.Enabled, "embed", cfg.Embed.Enabled, "start and own a headless neovim")
+	flags.StringVar(&cfg.Embed.Init, "embed-init", cfg.Embed.Init, "init file of the embedded neovim")
+	flags.BoolVar(&cfg.Discover, "discover", cfg.Discover, "find the running neovim instead of using the socket address")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
 
+	return nil
+}
+
+// serveStdio runs the MCP server for a single client on stdin and stdout
+func serveStdio(ctx context.Context, server *mcp.Server) error {
 	logger.Info("Starting MCP server on stdio")
-	if rErr := server.Run(ctx, transport); rErr != nil {
-		logger.Error("Server error", "error", rErr)
-		return fmt.Errorf("server error: %w", rErr)
+	if err := server.Run(ctx, &mcp.StdioTransport{}); err != nil && !errors.Is(err, context.Canceled) {
+		logger.Error("Server error", "error", err)
+		return fmt.Errorf("server error: %w", err)
+	}
+
+	return nil
+}
+
+// serveHTTP runs the MCP server on the streamable HTTP transport until ctx is done
+func serveHTTP(ctx context.Context, server *mcp.Server, cfg config.HTTPConfig) error {
+	handler, err := mcpserver.NewHTTPHandler(server, cfg.Token)
+	if err != nil {
+		return fmt.Errorf("failed to create http transport: %w (set NVIM_MCP_HTTP_TOKEN)", err)
+	}
+
+	httpServer := &http.Server{
+		Addr:              cfg.Address,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if sErr := httpServer.Shutdown(shutdownCtx); sErr != nil {
+			logger.Error("Failed to shut down http server", "error", sErr)
+		}
+	}()
+
+	logger.Info("Starting MCP server on http", "address", cfg.Address, "path", mcpserver.HTTPPath)
+	if lErr := httpServer.ListenAndServe(); lErr != nil && !errors.Is(lErr, http.ErrServerClosed) {
+		logger.Error("Server error", "error", lErr)
+		return fmt.Errorf("server error: %w", lErr)
 	}
 
 	return nil
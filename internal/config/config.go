@@ -2,16 +2,67 @@
 package config
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/knadh/koanf/providers/env/v2"
 	"github.com/knadh/koanf/v2"
 )
 
+// DefaultInstance is the name of the instance connected to the socket address
+const DefaultInstance = "default"
+
+// DefaultTimeout is the default deadline of a tool call or resource read
+const DefaultTimeout = 30 * time.Second
+
+const (
+	// TransportStdio serves MCP to a single client over stdin and stdout
+	TransportStdio = "stdio"
+	// TransportHTTP serves MCP to any number of clients over streamable HTTP
+	TransportHTTP = "http"
+)
+
 // Config holds the application configuration
 type Config struct {
-	SocketAddress string    `koanf:"socketAddress"`
-	Log           LogConfig `koanf:"log"`
+	SocketAddress string           `koanf:"socketAddress"`
+	Instances     []InstanceConfig `koanf:"-"`
+	Discover      bool             `koanf:"discover"`
+	Embed         EmbedConfig      `koanf:"embed"`
+	Timeout       time.Duration    `koanf:"timeout"`
+	Transport     string           `koanf:"transport"`
+	HTTP          HTTPConfig       `koanf:"http"`
+	Reconnect     ReconnectConfig  `koanf:"reconnect"`
+	Log           LogConfig        `koanf:"log"`
+}
+
+// InstanceConfig names a neovim instance served by the mcp server
+type InstanceConfig struct {
+	Name    string
+	Address string
+}
+
+// EmbedConfig holds the configuration of the neovim started in embedded mode
+type EmbedConfig struct {
+	Enabled bool   `koanf:"enabled"`
+	Command string `koanf:"command"`
+	Init    string `koanf:"init"`
+	Listen  string `koanf:"listen"`
+}
+
+// HTTPConfig holds the streamable HTTP transport configuration
+type HTTPConfig struct {
+	Address string `koanf:"address"`
+	Token   string `koanf:"token"`
+}
+
+// ReconnectConfig holds the neovim connection supervision configuration
+type ReconnectConfig struct {
+	Disabled bool          `koanf:"disabled"`
+	Backoff  time.Duration `koanf:"backoff"`
+	Interval time.Duration `koanf:"interval"`
 }
 
 // LogConfig holds logging configuration
@@ -24,6 +75,19 @@ type LogConfig struct {
 // Load loads configuration from environment variables
 // Environment variables use the NVIM_MCP_ prefix:
 //   - NVIM_MCP_LISTEN_ADDRESS or NVIM_MCP_SOCKET_ADDRESS
+//   - NVIM_MCP_INSTANCES (comma separated name=address pairs, overrides the socket address)
+//   - NVIM_MCP_DISCOVER (find the running neovim instead of using the socket address)
+//   - NVIM_MCP_EMBED_ENABLED (start and own a headless neovim instead of connecting to one)
+//   - NVIM_MCP_EMBED_COMMAND (neovim executable of the embedded mode)
+//   - NVIM_MCP_EMBED_INIT (init file of the embedded neovim, NONE for no config)
+//   - NVIM_MCP_EMBED_LISTEN (socket the embedded neovim serves for `nvim --remote-ui`)
+//   - NVIM_MCP_TIMEOUT (default tool timeout as a duration, e.g. 30s, 0 disables it)
+//   - NVIM_MCP_TRANSPORT (stdio or http)
+//   - NVIM_MCP_HTTP_ADDRESS (bind address of the http transport)
+//   - NVIM_MCP_HTTP_TOKEN (bearer token required by the http transport)
+//   - NVIM_MCP_RECONNECT_DISABLED (stay disconnected instead of reconnecting when neovim goes away)
+//   - NVIM_MCP_RECONNECT_BACKOFF (longest delay between reconnection attempts)
+//   - NVIM_MCP_RECONNECT_INTERVAL (health check interval, 0 disables health checks)
 //   - NVIM_MCP_LOG_LEVEL
 func Load() (*Config, error) {
 	k := koanf.New(".")
@@ -52,6 +116,24 @@ func Load() (*Config, error) {
 	// Create config with defaults
 	cfg := &Config{
 		SocketAddress: "/tmp/nvim.sock",
+		Discover:      false,
+		Embed: EmbedConfig{
+			Enabled: false,
+			Command: "nvim",
+			Init:    "",
+			Listen:  filepath.Join(os.TempDir(), fmt.Sprintf("nvim-mcp.%d.sock", os.Getpid())),
+		},
+		Timeout:   DefaultTimeout,
+		Transport: TransportStdio,
+		HTTP: HTTPConfig{
+			Address: "127.0.0.1:8808",
+			Token:   "",
+		},
+		Reconnect: ReconnectConfig{
+			Disabled: false,
+			Backoff:  30 * time.Second,
+			Interval: 10 * time.Second,
+		},
 		Log: LogConfig{
 			Level:    "info",
 			FilePath: "",
@@ -71,5 +153,41 @@ func Load() (*Config, error) {
 		}
 	}
 
+	cfg.Instances = []InstanceConfig{{Name: DefaultInstance, Address: cfg.SocketAddress}}
+	if instances := k.String("instances"); instances != "" {
+		cfg.Instances, err = parseInstances(instances)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return cfg, nil
 }
+
+// parseInstances parses a comma separated list of name=address pairs
+func parseInstances(value string) ([]InstanceConfig, error) {
+	var instances []InstanceConfig
+
+	for entry := range strings.SplitSeq(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, address, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		address = strings.TrimSpace(address)
+
+		if !ok || name == "" || address == "" {
+			return nil, fmt.Errorf("invalid instance `%s` in NVIM_MCP_INSTANCES, expected name=address", entry)
+		}
+
+		instances = append(instances, InstanceConfig{Name: name, Address: address})
+	}
+
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("NVIM_MCP_INSTANCES does not list any instance")
+	}
+
+	return instances, nil
+}
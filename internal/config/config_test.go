@@ -1,8 +1,10 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -43,3 +45,164 @@ func TestLoad_WithSocketAddress(t *testing.T) {
 
 	require.Equal(t, "/tmp/alt.sock", cfg.SocketAddress)
 }
+
+func TestLoad_WithTimeout(t *testing.T) {
+	t.Run("defaults to DefaultTimeout", func(t *testing.T) {
+		os.Clearenv()
+
+		cfg, err := Load()
+		require.NoError(t, err)
+
+		require.Equal(t, DefaultTimeout, cfg.Timeout)
+	})
+
+	t.Run("parses durations", func(t *testing.T) {
+		t.Setenv("NVIM_MCP_TIMEOUT", "1m30s")
+
+		cfg, err := Load()
+		require.NoError(t, err)
+
+		require.Equal(t, 90*time.Second, cfg.Timeout)
+	})
+
+	t.Run("zero disables the timeout", func(t *testing.T) {
+		t.Setenv("NVIM_MCP_TIMEOUT", "0s")
+
+		cfg, err := Load()
+		require.NoError(t, err)
+
+		require.Zero(t, cfg.Timeout)
+	})
+}
+
+func TestLoad_WithReconnect(t *testing.T) {
+	t.Run("enables reconnect by default", func(t *testing.T) {
+		os.Clearenv()
+
+		cfg, err := Load()
+		require.NoError(t, err)
+
+		require.False(t, cfg.Reconnect.Disabled)
+		require.Equal(t, 30*time.Second, cfg.Reconnect.Backoff)
+		require.Equal(t, 10*time.Second, cfg.Reconnect.Interval)
+	})
+
+	t.Run("reads reconnect settings", func(t *testing.T) {
+		t.Setenv("NVIM_MCP_RECONNECT_DISABLED", "true")
+		t.Setenv("NVIM_MCP_RECONNECT_BACKOFF", "5s")
+		t.Setenv("NVIM_MCP_RECONNECT_INTERVAL", "0")
+
+		cfg, err := Load()
+		require.NoError(t, err)
+
+		require.True(t, cfg.Reconnect.Disabled)
+		require.Equal(t, 5*time.Second, cfg.Reconnect.Backoff)
+		require.Zero(t, cfg.Reconnect.Interval)
+	})
+}
+
+func TestLoad_WithHTTPTransport(t *testing.T) {
+	t.Run("defaults to stdio", func(t *testing.T) {
+		os.Clearenv()
+
+		cfg, err := Load()
+		require.NoError(t, err)
+
+		require.Equal(t, TransportStdio, cfg.Transport)
+		require.Equal(t, "127.0.0.1:8808", cfg.HTTP.Address)
+		require.Empty(t, cfg.HTTP.Token)
+	})
+
+	t.Run("reads http settings", func(t *testing.T) {
+		t.Setenv("NVIM_MCP_TRANSPORT", "http")
+		t.Setenv("NVIM_MCP_HTTP_ADDRESS", "0.0.0.0:9000")
+		t.Setenv("NVIM_MCP_HTTP_TOKEN", "s3cret")
+
+		cfg, err := Load()
+		require.NoError(t, err)
+
+		require.Equal(t, TransportHTTP, cfg.Transport)
+		require.Equal(t, "0.0.0.0:9000", cfg.HTTP.Address)
+		require.Equal(t, "s3cret", cfg.HTTP.Token)
+	})
+}
+
+func TestLoad_WithInstances(t *testing.T) {
+	t.Run("defaults to the socket address", func(t *testing.T) {
+		os.Clearenv()
+		t.Setenv("NVIM_MCP_SOCKET_ADDRESS", "/tmp/alt.sock")
+
+		cfg, err := Load()
+		require.NoError(t, err)
+
+		require.Equal(t, []InstanceConfig{{Name: DefaultInstance, Address: "/tmp/alt.sock"}}, cfg.Instances)
+	})
+
+	t.Run("parses named instances", func(t *testing.T) {
+		t.Setenv("NVIM_MCP_INSTANCES", "work=/tmp/work.sock, notes = 127.0.0.1:6666,")
+
+		cfg, err := Load()
+		require.NoError(t, err)
+
+		require.Equal(t, []InstanceConfig{
+			{Name: "work", Address: "/tmp/work.sock"},
+			{Name: "notes", Address: "127.0.0.1:6666"},
+		}, cfg.Instances)
+	})
+
+	t.Run("rejects malformed instances", func(t *testing.T) {
+		t.Setenv("NVIM_MCP_INSTANCES", "work=/tmp/work.sock,/tmp/notes.sock")
+
+		_, err := Load()
+		require.Error(t, err)
+	})
+}
+
+func TestLoad_WithDiscover(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		os.Clearenv()
+
+		cfg, err := Load()
+		require.NoError(t, err)
+
+		require.False(t, cfg.Discover)
+	})
+
+	t.Run("reads the discover setting", func(t *testing.T) {
+		t.Setenv("NVIM_MCP_DISCOVER", "true")
+
+		cfg, err := Load()
+		require.NoError(t, err)
+
+		require.True(t, cfg.Discover)
+	})
+}
+
+func TestLoad_WithEmbed(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		os.Clearenv()
+
+		cfg, err := Load()
+		require.NoError(t, err)
+
+		require.False(t, cfg.Embed.Enabled)
+		require.Equal(t, "nvim", cfg.Embed.Command)
+		require.Empty(t, cfg.Embed.Init)
+		require.Contains(t, cfg.Embed.Listen, fmt.Sprintf("nvim-mcp.%d.sock", os.Getpid()))
+	})
+
+	t.Run("reads embed settings", func(t *testing.T) {
+		t.Setenv("NVIM_MCP_EMBED_ENABLED", "true")
+		t.Setenv("NVIM_MCP_EMBED_COMMAND", "/opt/nvim/bin/nvim")
+		t.Setenv("NVIM_MCP_EMBED_INIT", "/etc/nvim-mcp/init.lua")
+		t.Setenv("NVIM_MCP_EMBED_LISTEN", "/tmp/agent.sock")
+
+		cfg, err := Load()
+		require.NoError(t, err)
+
+		require.True(t, cfg.Embed.Enabled)
+		require.Equal(t, "/opt/nvim/bin/nvim", cfg.Embed.Command)
+		require.Equal(t, "/etc/nvim-mcp/init.lua", cfg.Embed.Init)
+		require.Equal(t, "/tmp/agent.sock", cfg.Embed.Listen)
+	})
+}
@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/cousine/neovim-mcp/internal/logger"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// discoveryTimeout bounds a discovery run triggered by a client session
+const discoveryTimeout = 30 * time.Second
+
+// DiscoverFunc finds the neovim instance to use for an MCP client working in dirs
+type DiscoverFunc func(ctx context.Context, dirs []string) (*types.Instance, error)
+
+var (
+	// discoveryMu serializes discovery runs
+	discoveryMu sync.Mutex
+	// discoveryErr is the error of the last failed discovery, guarded by instancesMu
+	discoveryErr error
+)
+
+// WithDiscovery retries discover with the roots of every client session initialized
+// while no instance is registered
+func WithDiscovery(discover DiscoverFunc) ServerOption {
+	return func(cfg *serverConfig) {
+		cfg.discover = discover
+	}
+}
+
+// DiscoverInstance registers the instance found by discover unless an instance is
+// already registered. Failures are kept and reported by requests needing an instance.
+func DiscoverInstance(ctx context.Context, discover DiscoverFunc, dirs []string) error {
+	discoveryMu.Lock()
+	defer discoveryMu.Unlock()
+
+	if instances, _ := ListInstances(); len(instances) > 0 {
+		return nil
+	}
+
+	instance, err := discover(ctx, dirs)
+	if err == nil {
+		if err = AddInstance(instance); err != nil {
+			_ = instance.Client.Close()
+		}
+	}
+
+	instancesMu.Lock()
+	discoveryErr = err
+	instancesMu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("failed to discover neovim: %w", err)
+	}
+
+	logger.Info("Discovered neovim instance", "name", instance.Name, "address", instance.Address)
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+
+// discoveryHandler runs discovery with the roots of newly initialized sessions
+func discoveryHandler(discover DiscoverFunc) func(context.Context, *mcp.InitializedRequest) {
+	return func(_ context.Context, req *mcp.InitializedRequest) {
+		if instances, _ := ListInstances(); len(instances) > 0 {
+			return
+		}
+
+		// listing roots waits for the client, which must not block the notification
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), discoveryTimeout)
+			defer cancel()
+
+			dirs := sessionRoots(ctx, req.Session)
+			if err := DiscoverInstance(ctx, discover, dirs); err != nil {
+				logger.Warn("Failed to discover neovim for session", "roots", dirs, "error", err)
+			}
+		}()
+	}
+}
+
+// sessionRoots returns the local directories of the roots of a client session
+func sessionRoots(ctx context.Context, session *mcp.ServerSession) []string {
+	result, err := session.ListRoots(ctx, nil)
+	if err != nil {
+		logger.Debug("Client did not list its roots", "error", err)
+		return nil
+	}
+
+	return rootDirs(result.Roots)
+}
+
+// rootDirs converts file:// roots to directories, ignoring other schemes
+func rootDirs(roots []*mcp.Root) []string {
+	var dirs []string
+
+	for _, root := range roots {
+		u, err := url.Parse(root.URI)
+		if err != nil || u.Scheme != "file" || u.Path == "" {
+			continue
+		}
+
+		dirs = append(dirs, u.Path)
+	}
+
+	return dirs
+}
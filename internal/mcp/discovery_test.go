@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestDiscoverInstance(t *testing.T) {
+	errNotFound := errors.New("no matching neovim instance found")
+
+	var calls [][]string
+	found := false
+	discover := func(_ context.Context, dirs []string) (*types.Instance, error) {
+		calls = append(calls, dirs)
+		if !found {
+			return nil, errNotFound
+		}
+
+		return &types.Instance{Name: DefaultInstance, Address: "/run/nvim.1.0", Client: nvimtest.NewMockClient()}, nil
+	}
+
+	NewServer(nil, WithDiscovery(discover))
+
+	t.Run("reports discovery failures", func(t *testing.T) {
+		err := DiscoverInstance(t.Context(), discover, nil)
+		require.ErrorIs(t, err, errNotFound)
+
+		_, err = GetInstanceClient("")
+		assert.ErrorIs(t, err, ErrNoInstance)
+		assert.ErrorIs(t, err, errNotFound)
+	})
+
+	t.Run("registers the discovered instance", func(t *testing.T) {
+		found = true
+
+		require.NoError(t, DiscoverInstance(t.Context(), discover, []string{"/src/project"}))
+
+		instance, err := GetInstance("")
+		require.NoError(t, err)
+		assert.Equal(t, "/run/nvim.1.0", instance.Address)
+		assert.Equal(t, []string{"/src/project"}, calls[len(calls)-1])
+	})
+
+	t.Run("keeps the registered instance", func(t *testing.T) {
+		require.NoError(t, DiscoverInstance(t.Context(), discover, nil))
+
+		assert.Len(t, calls, 2)
+	})
+}
+
+func TestRootDirs(t *testing.T) {
+	dirs := rootDirs([]*mcp.Root{
+		{URI: "file:///src/project"},
+		{URI: "file:///src/with%20space"},
+		{URI: "https://example.com/repo"},
+		{URI: "::invalid"},
+	})
+
+	assert.Equal(t, []string{"/src/project", "/src/with space"}, dirs)
+}
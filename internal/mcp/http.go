@@ -0,0 +1,59 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/cousine/neovim-mcp/internal/logger"
+)
+
+// HTTPPath is the endpoint of the streamable HTTP transport
+const HTTPPath = "/mcp"
+
+// ErrMissingToken is returned when the HTTP transport is configured without a bearer token
+var ErrMissingToken = errors.New("http transport requires a bearer token")
+
+// NewHTTPHandler serves server over the streamable HTTP transport at HTTPPath. Every
+// request must carry token as a bearer token, all sessions share the same neovim
+// clients. Sessions name the instance of each request rather than selecting one, since
+// the selection would apply to every session.
+func NewHTTPHandler(server *mcp.Server, token string) (http.Handler, error) {
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+
+	shareSelection()
+
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
+		return server
+	}, &mcp.StreamableHTTPOptions{
+		Logger: logger.GetLogger(),
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle(HTTPPath, auth.RequireBearerToken(staticTokenVerifier(token), nil)(handler))
+
+	return mux, nil
+}
+
+// ----------------------------------------------------------------------------
+
+// staticTokenVerifier accepts only token, compared in constant time
+func staticTokenVerifier(token string) auth.TokenVerifier {
+	want := []byte(token)
+
+	return func(_ context.Context, got string, _ *http.Request) (*auth.TokenInfo, error) {
+		if subtle.ConstantTimeCompare([]byte(got), want) != 1 {
+			return nil, auth.ErrInvalidToken
+		}
+
+		// the token never expires, the expiration only satisfies auth.RequireBearerToken
+		return &auth.TokenInfo{Expiration: time.Now().Add(time.Hour)}, nil
+	}
+}
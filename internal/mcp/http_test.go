@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// bearerTransport adds a bearer token to every request
+type bearerTransport struct {
+	token string
+}
+
+func (b *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+b.token)
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestNewHTTPHandler(t *testing.T) {
+	server := NewServer(nil)
+
+	t.Run("requires a token", func(t *testing.T) {
+		_, err := NewHTTPHandler(server, "")
+
+		assert.ErrorIs(t, err, ErrMissingToken)
+	})
+
+	handler, err := NewHTTPHandler(server, "s3cret")
+	require.NoError(t, err)
+
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+
+	endpoint := httpServer.URL + HTTPPath
+
+	post := func(t *testing.T, authorization string) int {
+		t.Helper()
+
+		req, rerr := http.NewRequestWithContext(t.Context(), http.MethodPost, endpoint, strings.NewReader("{}"))
+		require.NoError(t, rerr)
+		req.Header.Set("Content-Type", "application/json")
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+
+		resp, rerr := http.DefaultClient.Do(req)
+		require.NoError(t, rerr)
+		defer resp.Body.Close()
+
+		return resp.StatusCode
+	}
+
+	t.Run("rejects requests without a token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, post(t, ""))
+	})
+
+	t.Run("rejects requests with a wrong token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, post(t, "Bearer guess"))
+	})
+
+	t.Run("serves sessions with the token", func(t *testing.T) {
+		client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "v0.0.1"}, nil)
+		session, cerr := client.Connect(t.Context(), &mcp.StreamableClientTransport{
+			Endpoint:   endpoint,
+			HTTPClient: &http.Client{Transport: &bearerTransport{token: "s3cret"}},
+		}, nil)
+		require.NoError(t, cerr)
+		defer session.Close()
+
+		assert.NoError(t, session.Ping(t.Context(), nil))
+	})
+
+	t.Run("keeps the selected instance of shared sessions", func(t *testing.T) {
+		require.NoError(t, AddInstance(&types.Instance{Name: "work", Client: nvimtest.NewMockClient()}))
+		require.NoError(t, AddInstance(&types.Instance{Name: "notes", Client: nvimtest.NewMockClient()}))
+
+		_, err := SelectInstance(context.Background(), "notes")
+		require.ErrorIs(t, err, ErrSharedSelection)
+
+		_, selected := ListInstances()
+		assert.Equal(t, "work", selected)
+
+		instance, err := GetInstance("notes")
+		require.NoError(t, err)
+		assert.Equal(t, "notes", instance.Name)
+	})
+}
@@ -0,0 +1,226 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/cousine/neovim-mcp/internal/config"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// DefaultInstance is the name of the instance registered by NewServer
+const DefaultInstance = config.DefaultInstance
+
+// ResourceScheme prefixes every resource uri
+const ResourceScheme = "nvim://"
+
+var (
+	// ErrUnknownInstance is returned when a request names an instance that is not registered
+	ErrUnknownInstance = errors.New("unknown neovim instance")
+
+	// ErrNoInstance is returned when no neovim instance is registered
+	ErrNoInstance = errors.New("no neovim instance available")
+
+	// ErrInvalidInstance is returned when an instance cannot be registered
+	ErrInvalidInstance = errors.New("invalid neovim instance")
+
+	// ErrSharedSelection is returned when selecting an instance while several sessions
+	// share the selection
+	ErrSharedSelection = errors.New("the selected neovim instance is shared by every session")
+)
+
+// instanceNamePattern restricts instance names to values usable as a uri segment
+var instanceNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// instancesMu guards serverContext and sharedSelection
+var instancesMu sync.RWMutex
+
+// sharedSelection tells whether several sessions share the selected instance, as over
+// the HTTP transport, where it stays the first registered instance
+var sharedSelection bool
+
+// InstanceInput is embedded in tool inputs to target a specific neovim instance
+type InstanceInput struct {
+	Instance string `json:"instance,omitempty" jsonschema:"name of the neovim instance to use (see list_instances), defaults to the selected instance"`
+}
+
+// AddInstance registers a neovim instance, the first registered instance is selected
+func AddInstance(instance *types.Instance) error {
+	if !instanceNamePattern.MatchString(instance.Name) {
+		return fmt.Errorf("%w: name `%s` must only contain letters, digits, '_', '.' and '-'",
+			ErrInvalidInstance, instance.Name)
+	}
+
+	if instance.Client == nil {
+		return fmt.Errorf("%w: instance `%s` has no client", ErrInvalidInstance, instance.Name)
+	}
+
+	instancesMu.Lock()
+	defer instancesMu.Unlock()
+
+	if findInstance(instance.Name) != nil {
+		return fmt.Errorf("%w: instance `%s` already exists", ErrInvalidInstance, instance.Name)
+	}
+
+	serverContext.Instances = append(serverContext.Instances, instance)
+	if serverContext.Selected == "" {
+		serverContext.Selected = instance.Name
+	}
+
+	return nil
+}
+
+// ListInstances returns the registered instances in registration order and the
+// name of the selected one
+func ListInstances() ([]*types.Instance, string) {
+	instancesMu.RLock()
+	defer instancesMu.RUnlock()
+
+	return slices.Clone(serverContext.Instances), serverContext.Selected
+}
+
+// SelectInstance makes name the instance used by requests that do not name one and
+// tells the SelectionListener subscribers. It fails with ErrSharedSelection once the
+// selection is shared, since one session would then retarget the requests of every
+// other session.
+func SelectInstance(ctx context.Context, name string) (*types.Instance, error) {
+	instance, err := selectInstance(name)
+	if err != nil {
+		return nil, err
+	}
+
+	selectionChanged(ctx)
+
+	return instance, nil
+}
+
+// GetInstance returns the instance called name, or the selected instance for an empty name
+func GetInstance(name string) (*types.Instance, error) {
+	instancesMu.RLock()
+	defer instancesMu.RUnlock()
+
+	return lookupInstance(name)
+}
+
+// GetInstanceClient returns the client of the instance called name, or of the selected
+// instance for an empty name
+func GetInstanceClient(name string) (types.NeovimClient, error) {
+	instance, err := GetInstance(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return instance.Client, nil
+}
+
+// DescribeInstance reports the name, address, selection and connection of instance
+func DescribeInstance(ctx context.Context, instance *types.Instance) types.InstanceInfo {
+	instancesMu.RLock()
+	selected := serverContext.Selected == instance.Name
+	instancesMu.RUnlock()
+
+	status, err := instance.Client.GetConnectionStatus(ctx)
+	if err != nil {
+		status = types.ConnectionStatus{Address: instance.Address, LastError: err.Error()}
+	}
+
+	return types.InstanceInfo{
+		Name:       instance.Name,
+		Address:    instance.Address,
+		Selected:   selected,
+		Connection: status,
+	}
+}
+
+// ParseResourceURI splits a resource uri into its instance and resource path.
+// nvim://buffers targets the selected instance while nvim://work/buffers targets
+// the instance called work, instance names shadow resource names.
+func ParseResourceURI(uri string) (instance, path string) {
+	path = strings.TrimPrefix(uri, ResourceScheme)
+
+	host, rest, ok := strings.Cut(path, "/")
+	if !ok {
+		return "", path
+	}
+
+	instancesMu.RLock()
+	defer instancesMu.RUnlock()
+
+	if findInstance(host) == nil {
+		return "", path
+	}
+
+	return host, rest
+}
+
+// shareSelection fixes the selected instance for servers whose sessions share it
+func shareSelection() {
+	instancesMu.Lock()
+	defer instancesMu.Unlock()
+
+	sharedSelection = true
+}
+
+// ----------------------------------------------------------------------------
+
+// selectInstance changes the selected instance to name
+func selectInstance(name string) (*types.Instance, error) {
+	instancesMu.Lock()
+	defer instancesMu.Unlock()
+
+	if sharedSelection {
+		return nil, fmt.Errorf("%w, pass `instance` to each tool or name it in resource uris (nvim://%s/buffers) instead",
+			ErrSharedSelection, name)
+	}
+
+	instance, err := lookupInstance(name)
+	if err != nil {
+		return nil, err
+	}
+
+	serverContext.Selected = instance.Name
+
+	return instance, nil
+}
+
+// lookupInstance resolves name to a registered instance, callers must hold instancesMu
+func lookupInstance(name string) (*types.Instance, error) {
+	if len(serverContext.Instances) == 0 {
+		if discoveryErr != nil {
+			return nil, fmt.Errorf("%w: %w", ErrNoInstance, discoveryErr)
+		}
+
+		return nil, ErrNoInstance
+	}
+
+	if name == "" {
+		name = serverContext.Selected
+	}
+
+	if instance := findInstance(name); instance != nil {
+		return instance, nil
+	}
+
+	names := make([]string, 0, len(serverContext.Instances))
+	for _, instance := range serverContext.Instances {
+		names = append(names, instance.Name)
+	}
+
+	return nil, fmt.Errorf("%w `%s`, available instances: %s", ErrUnknownInstance, name, strings.Join(names, ", "))
+}
+
+// findInstance returns the instance called name or nil, callers must hold instancesMu
+func findInstance(name string) *types.Instance {
+	for _, instance := range serverContext.Instances {
+		if instance.Name == name {
+			return instance
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestInstances(t *testing.T) {
+	work, notes := nvimtest.NewMockClient(), nvimtest.NewMockClient()
+
+	NewServer(nil)
+
+	t.Run("reports missing instances", func(t *testing.T) {
+		_, err := GetInstanceClient("")
+
+		assert.ErrorIs(t, err, ErrNoInstance)
+		assert.Nil(t, GetNvimClient())
+	})
+
+	require.NoError(t, AddInstance(&types.Instance{Name: "work", Address: "/tmp/work.sock", Client: work}))
+	require.NoError(t, AddInstance(&types.Instance{Name: "notes", Address: "/tmp/notes.sock", Client: notes}))
+
+	t.Run("selects the first instance", func(t *testing.T) {
+		client, err := GetInstanceClient("")
+
+		require.NoError(t, err)
+		assert.Same(t, work, client)
+		assert.Same(t, work, GetNvimClient())
+	})
+
+	t.Run("resolves instances by name", func(t *testing.T) {
+		client, err := GetInstanceClient("notes")
+
+		require.NoError(t, err)
+		assert.Same(t, notes, client)
+	})
+
+	t.Run("lists unknown instance candidates", func(t *testing.T) {
+		_, err := GetInstanceClient("missing")
+
+		require.ErrorIs(t, err, ErrUnknownInstance)
+		assert.Contains(t, err.Error(), "work, notes")
+	})
+
+	t.Run("rejects invalid instances", func(t *testing.T) {
+		assert.ErrorIs(t, AddInstance(&types.Instance{Name: "work", Client: work}), ErrInvalidInstance)
+		assert.ErrorIs(t, AddInstance(&types.Instance{Name: "a/b", Client: work}), ErrInvalidInstance)
+		assert.ErrorIs(t, AddInstance(&types.Instance{Name: "nil"}), ErrInvalidInstance)
+	})
+
+	t.Run("changes the selected instance", func(t *testing.T) {
+		_, err := SelectInstance(context.Background(), "notes")
+		require.NoError(t, err)
+
+		instances, selected := ListInstances()
+		assert.Len(t, instances, 2)
+		assert.Equal(t, "notes", selected)
+		assert.Same(t, notes, GetNvimClient())
+	})
+
+	t.Run("registers the client passed to NewServer as default", func(t *testing.T) {
+		NewServer(work)
+
+		instances, selected := ListInstances()
+		require.Len(t, instances, 1)
+		assert.Equal(t, DefaultInstance, selected)
+	})
+}
+
+func TestParseResourceURI(t *testing.T) {
+	NewServer(nil)
+	require.NoError(t, AddInstance(&types.Instance{Name: "work", Client: nvimtest.NewMockClient()}))
+
+	tests := []struct {
+		uri      string
+		instance string
+		path     string
+	}{
+		{uri: "nvim://buffers", instance: "", path: "buffers"},
+		{uri: "nvim://work/buffers", instance: "work", path: "buffers"},
+		{uri: "nvim://diagnostics/main.go", instance: "", path: "diagnostics/main.go"},
+		{uri: "nvim://work/diagnostics/main.go", instance: "work", path: "diagnostics/main.go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			instance, path := ParseResourceURI(tt.uri)
+
+			assert.Equal(t, tt.instance, instance)
+			assert.Equal(t, tt.path, path)
+		})
+	}
+}
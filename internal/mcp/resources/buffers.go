@@ -6,13 +6,14 @@ import (
 	"encoding/json"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
-
-	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
 )
 
 // BuffersResource provides the nvim://buffers resource
 func BuffersResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-	nvimClient := mcpserver.GetNvimClient()
+	nvimClient, err := instanceClient(req.Params.URI)
+	if err != nil {
+		return nil, err
+	}
 
 	buffers, err := nvimClient.GetBuffers(ctx)
 	if err != nil {
@@ -27,7 +28,7 @@ func BuffersResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.Re
 	return &mcp.ReadResourceResult{
 		Contents: []*mcp.ResourceContents{
 			{
-				URI:      "nvim://buffers",
+				URI:      req.Params.URI,
 				MIMEType: "application/json",
 				Text:     string(jsonBuffers),
 			},
@@ -42,4 +43,11 @@ func RegisterBuffersResource(server *mcp.Server) {
 		URI:      "nvim://buffers",
 		MIMEType: "application/json",
 	}, BuffersResource)
+
+	addInstanceTemplate(server, "buffers", &mcp.ResourceTemplate{
+		Name:        "instance_buffers",
+		URITemplate: "nvim://{instance}/buffers",
+		MIMEType:    "application/json",
+		Description: "Open buffers of a Neovim instance",
+	}, BuffersResource)
 }
@@ -2,27 +2,52 @@ package resources
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// ConfigResource provides the nvim://config resource
 func ConfigResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-	// TODO: Implement
+	nvimClient, err := instanceClient(req.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := nvimClient.GetEditorConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonConfig, marshalErr := json.Marshal(cfg)
+	if marshalErr != nil {
+		return nil, marshalErr
+	}
+
 	return &mcp.ReadResourceResult{
 		Contents: []*mcp.ResourceContents{
 			{
-				URI:      "nvim://config",
+				URI:      req.Params.URI,
 				MIMEType: "application/json",
-				Text:     "{}",
+				Text:     string(jsonConfig),
 			},
 		},
 	}, nil
 }
 
+// RegisterConfigResource registers the config resource
 func RegisterConfigResource(server *mcp.Server) {
 	server.AddResource(&mcp.Resource{
-		Name:     "config",
-		URI:      "nvim://config",
-		MIMEType: "application/json",
+		Name:        "config",
+		URI:         "nvim://config",
+		MIMEType:    "application/json",
+		Description: "Neovim version, init file, runtimepath, standard directories, leader keys, cwd and option values",
+	}, ConfigResource)
+
+	addInstanceTemplate(server, "config", &mcp.ResourceTemplate{
+		Name:        "instance_config",
+		URITemplate: "nvim://{instance}/config",
+		MIMEType:    "application/json",
+		Description: "Configuration of a Neovim instance",
 	}, ConfigResource)
 }
@@ -1,7 +1,54 @@
 package resources
 
-import "testing"
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
 
 func TestConfigResource(t *testing.T) {
-	t.Skip("Not implemented")
+	req := &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: "nvim://config"}}
+
+	t.Run("returns editor config", func(t *testing.T) {
+		cfg := types.EditorConfig{
+			Version:       types.EditorVersion{Version: "0.11.4", Major: 0, Minor: 11, Patch: 4, APILevel: 13},
+			InitFile:      "/home/dev/.config/nvim/init.lua",
+			Runtimepath:   []string{"/home/dev/.config/nvim", "/usr/share/nvim/runtime"},
+			Leader:        " ",
+			Cwd:           "/home/dev/project",
+			GlobalOptions: map[string]any{"expandtab": true},
+			BufferOptions: map[string]any{"shiftwidth": float64(4)},
+		}
+		client := nvimtest.NewMockClient()
+		client.SetupGetEditorConfig(cfg, nil)
+		mcpserver.NewServer(client)
+
+		result, err := ConfigResource(t.Context(), req)
+		require.NoError(t, err)
+		require.Len(t, result.Contents, 1)
+		assert.Equal(t, "nvim://config", result.Contents[0].URI)
+
+		var got types.EditorConfig
+		require.NoError(t, json.Unmarshal([]byte(result.Contents[0].Text), &got))
+		assert.Equal(t, cfg, got)
+		client.AssertExpectations(t)
+	})
+
+	t.Run("returns client errors", func(t *testing.T) {
+		client := nvimtest.NewMockClient()
+		client.SetupGetEditorConfig(types.EditorConfig{}, errors.New("boom"))
+		mcpserver.NewServer(client)
+
+		_, err := ConfigResource(t.Context(), req)
+
+		assert.EqualError(t, err, "boom")
+	})
 }
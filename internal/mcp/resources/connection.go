@@ -0,0 +1,53 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ConnectionResource provides the nvim://connection resource
+func ConnectionResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	nvimClient, err := instanceClient(req.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := nvimClient.GetConnectionStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStatus, marshalErr := json.Marshal(status)
+	if marshalErr != nil {
+		return nil, marshalErr
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      req.Params.URI,
+				MIMEType: "application/json",
+				Text:     string(jsonStatus),
+			},
+		},
+	}, nil
+}
+
+// RegisterConnectionResource registers the connection resource
+func RegisterConnectionResource(server *mcp.Server) {
+	server.AddResource(&mcp.Resource{
+		Name:        "connection",
+		URI:         "nvim://connection",
+		MIMEType:    "application/json",
+		Description: "State and health of the connection to Neovim, including reconnection attempts and the last error",
+	}, ConnectionResource)
+
+	addInstanceTemplate(server, "connection", &mcp.ResourceTemplate{
+		Name:        "instance_connection",
+		URITemplate: "nvim://{instance}/connection",
+		MIMEType:    "application/json",
+		Description: "Connection state and health of a Neovim instance",
+	}, ConnectionResource)
+}
@@ -0,0 +1,55 @@
+package resources
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestConnectionResource(t *testing.T) {
+	req := &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: "nvim://connection"}}
+
+	t.Run("returns connection status", func(t *testing.T) {
+		status := types.ConnectionStatus{
+			State:       "disconnected",
+			Address:     "/tmp/nvim.sock",
+			ConnectedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+			Attempts:    3,
+			Reconnects:  1,
+			LastError:   "connection closed by neovim",
+		}
+		client := nvimtest.NewMockClient()
+		client.SetupGetConnectionStatus(status, nil)
+		mcpserver.NewServer(client)
+
+		result, err := ConnectionResource(t.Context(), req)
+		require.NoError(t, err)
+		require.Len(t, result.Contents, 1)
+		assert.Equal(t, "nvim://connection", result.Contents[0].URI)
+
+		var got types.ConnectionStatus
+		require.NoError(t, json.Unmarshal([]byte(result.Contents[0].Text), &got))
+		assert.Equal(t, status, got)
+		client.AssertExpectations(t)
+	})
+
+	t.Run("omits unset timestamps", func(t *testing.T) {
+		client := nvimtest.NewMockClient()
+		client.SetupGetConnectionStatus(types.ConnectionStatus{State: "connecting"}, nil)
+		mcpserver.NewServer(client)
+
+		result, err := ConnectionResource(t.Context(), req)
+		require.NoError(t, err)
+
+		assert.NotContains(t, result.Contents[0].Text, "connected_at")
+		assert.NotContains(t, result.Contents[0].Text, "last_check")
+	})
+}
@@ -2,27 +2,271 @@ package resources
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/cousine/neovim-mcp/internal/logger"
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+const (
+	// DiagnosticsURI is the uri of the diagnostics resource for all buffers
+	DiagnosticsURI = "nvim://diagnostics"
+	// DiagnosticsURITemplate is the uri template of the per-buffer diagnostics resource
+	DiagnosticsURITemplate = "nvim://diagnostics/{buffer}"
 )
 
+// DiagnosticsResource provides the nvim://diagnostics and nvim://diagnostics/{buffer} resources
+// and their nvim://{instance}/... variants
 func DiagnosticsResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-	// TODO: Implement
+	bufferTitle, err := diagnosticsBuffer(req.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	nvimClient, err := instanceClient(req.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	diagnostics, err := nvimClient.GetDiagnostics(ctx, bufferTitle)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonDiagnostics, marshalErr := json.Marshal(diagnostics)
+	if marshalErr != nil {
+		return nil, marshalErr
+	}
+
 	return &mcp.ReadResourceResult{
 		Contents: []*mcp.ResourceContents{
 			{
-				URI:      "nvim://diagnostics",
+				URI:      req.Params.URI,
 				MIMEType: "application/json",
-				Text:     "[]",
+				Text:     string(jsonDiagnostics),
 			},
 		},
 	}, nil
 }
 
+// RegisterDiagnosticsResource registers the diagnostics resources and their subscriptions
 func RegisterDiagnosticsResource(server *mcp.Server) {
 	server.AddResource(&mcp.Resource{
-		Name:     "diagnostics",
-		URI:      "nvim://diagnostics",
-		MIMEType: "application/json",
+		Name:        "diagnostics",
+		URI:         DiagnosticsURI,
+		MIMEType:    "application/json",
+		Description: "Diagnostics for all buffers grouped by buffer path",
 	}, DiagnosticsResource)
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "buffer_diagnostics",
+		URITemplate: DiagnosticsURITemplate,
+		MIMEType:    "application/json",
+		Description: "Diagnostics for a single buffer by handle, path or unique filename",
+	}, DiagnosticsResource)
+
+	addInstanceTemplate(server, "diagnostics", &mcp.ResourceTemplate{
+		Name:        "instance_diagnostics",
+		URITemplate: "nvim://{instance}/diagnostics",
+		MIMEType:    "application/json",
+		Description: "Diagnostics for all buffers of a Neovim instance",
+	}, DiagnosticsResource)
+
+	addInstanceTemplate(server, "diagnostics", &mcp.ResourceTemplate{
+		Name:        "instance_buffer_diagnostics",
+		URITemplate: "nvim://{instance}/diagnostics/{buffer}",
+		MIMEType:    "application/json",
+		Description: "Diagnostics for a single buffer of a Neovim instance",
+	}, DiagnosticsResource)
+
+	mcpserver.AddResourceSubscriber(DiagnosticsURI, newDiagnosticsSubscriber(server))
+}
+
+// ----------------------------------------------------------------------------
+
+// diagnosticsBuffer extracts the buffer title from a diagnostics uri, empty for all buffers
+func diagnosticsBuffer(uri string) (string, error) {
+	if !strings.HasPrefix(uri, mcpserver.ResourceScheme) {
+		return "", fmt.Errorf("invalid diagnostics uri `%s`", uri)
+	}
+
+	_, path := mcpserver.ParseResourceURI(uri)
+	if path == "diagnostics" {
+		return "", nil
+	}
+
+	escaped, ok := strings.CutPrefix(path, "diagnostics/")
+	if !ok {
+		return "", fmt.Errorf("invalid diagnostics uri `%s`", uri)
+	}
+
+	title, err := url.PathUnescape(escaped)
+	if err != nil {
+		return "", fmt.Errorf("invalid diagnostics uri `%s`: %w", uri, err)
+	}
+
+	return title, nil
+}
+
+// diagnosticsSubscriber notifies subscribed clients when neovim diagnostics change. It
+// watches the instances its uris resolve to, following the selected instance for uris
+// without one, and stops a watch once no uri resolves to its instance.
+type diagnosticsSubscriber struct {
+	server  *mcp.Server
+	mu      sync.Mutex
+	uris    map[string]int
+	watches map[string]func(context.Context) error
+}
+
+// newDiagnosticsSubscriber creates a subscriber sending updates through server
+func newDiagnosticsSubscriber(server *mcp.Server) *diagnosticsSubscriber {
+	return &diagnosticsSubscriber{
+		server:  server,
+		uris:    make(map[string]int),
+		watches: make(map[string]func(context.Context) error),
+	}
+}
+
+// Subscribe starts watching the diagnostics of an instance on its first subscription
+func (d *diagnosticsSubscriber) Subscribe(ctx context.Context, uri string) error {
+	if _, err := diagnosticsBuffer(uri); err != nil {
+		return err
+	}
+
+	name, _ := mcpserver.ParseResourceURI(uri)
+
+	instance, err := mcpserver.GetInstance(name)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to `%s`: %w", uri, err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.watch(ctx, instance); err != nil {
+		return fmt.Errorf("failed to subscribe to `%s`: %w", uri, err)
+	}
+
+	d.uris[uri]++
+
+	return nil
+}
+
+// Unsubscribe stops sending updates for uri once all its subscribers are gone, and
+// stops watching its instance once no other uri resolves to it
+func (d *diagnosticsSubscriber) Unsubscribe(ctx context.Context, uri string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.uris[uri]--
+	if d.uris[uri] <= 0 {
+		delete(d.uris, uri)
+	}
+
+	if err := d.release(ctx); err != nil {
+		return fmt.Errorf("failed to unsubscribe from `%s`: %w", uri, err)
+	}
+
+	return nil
+}
+
+// SelectionChanged watches the newly selected instance for the uris without an
+// instance and stops watching the previous one when nothing else resolves to it
+func (d *diagnosticsSubscriber) SelectionChanged(ctx context.Context) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	follows := false
+	for uri := range d.uris {
+		if name, _ := mcpserver.ParseResourceURI(uri); name == "" {
+			follows = true
+		}
+	}
+
+	if follows {
+		instance, err := mcpserver.GetInstance("")
+		if err == nil {
+			err = d.watch(ctx, instance)
+		}
+
+		if err != nil {
+			logger.Error("failed to watch diagnostics of the selected instance", "error", err)
+		}
+	}
+
+	if err := d.release(ctx); err != nil {
+		logger.Error("failed to stop watching diagnostics", "error", err)
+	}
+}
+
+// notify sends a resources/updated notification for every subscribed diagnostics uri
+// of the instance called name. Uris without an instance follow the selected instance.
+func (d *diagnosticsSubscriber) notify(name string) {
+	d.mu.Lock()
+	uris := slices.Collect(maps.Keys(d.uris))
+	d.mu.Unlock()
+
+	for _, uri := range uris {
+		target, _ := mcpserver.ParseResourceURI(uri)
+		if instance, err := mcpserver.GetInstance(target); err != nil || instance.Name != name {
+			continue
+		}
+
+		err := d.server.ResourceUpdated(context.Background(), &mcp.ResourceUpdatedNotificationParams{URI: uri})
+		if err != nil {
+			logger.Error("failed to send diagnostics update", "uri", uri, "error", err)
+		}
+	}
+}
+
+// watch starts watching the diagnostics of instance unless it is already watched,
+// d.mu must be held
+func (d *diagnosticsSubscriber) watch(ctx context.Context, instance *types.Instance) error {
+	if _, ok := d.watches[instance.Name]; ok {
+		return nil
+	}
+
+	stop, err := instance.Client.WatchDiagnostics(ctx, func() { d.notify(instance.Name) })
+	if err != nil {
+		return err
+	}
+
+	d.watches[instance.Name] = stop
+
+	return nil
+}
+
+// release stops the watches of instances no subscribed uri resolves to, d.mu must be held
+func (d *diagnosticsSubscriber) release(ctx context.Context) error {
+	targets := make(map[string]bool)
+	for uri := range d.uris {
+		name, _ := mcpserver.ParseResourceURI(uri)
+		if instance, err := mcpserver.GetInstance(name); err == nil {
+			targets[instance.Name] = true
+		}
+	}
+
+	var errs []error
+	for name, stop := range d.watches {
+		if targets[name] {
+			continue
+		}
+
+		delete(d.watches, name)
+		if err := stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("instance `%s`: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
 }
@@ -1,7 +1,174 @@
 package resources
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// watchedClient returns a mock client that counts its active diagnostics watches
+func watchedClient() (*nvimtest.MockClient, *int) {
+	client := nvimtest.NewMockClient()
+	watches := new(int)
+
+	client.SetupWatchDiagnostics(func(context.Context) error {
+		*watches--
+		return nil
+	}, nil).Run(func(mock.Arguments) { *watches++ })
+
+	return client, watches
+}
+
+func readDiagnostics(t *testing.T, uri string) []types.BufferDiagnostics {
+	t.Helper()
+
+	result, err := DiagnosticsResource(t.Context(), &mcp.ReadResourceRequest{
+		Params: &mcp.ReadResourceParams{URI: uri},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Contents, 1)
+	assert.Equal(t, uri, result.Contents[0].URI)
+
+	var diagnostics []types.BufferDiagnostics
+	require.NoError(t, json.Unmarshal([]byte(result.Contents[0].Text), &diagnostics))
+
+	return diagnostics
+}
 
 func TestDiagnosticsResource(t *testing.T) {
-	t.Skip("Not implemented")
+	all := []types.BufferDiagnostics{
+		{Handle: 1, Path: "/src/main.go", Diagnostics: []types.Diagnostic{{Severity: "error", Message: "boom"}}},
+		{Handle: 2, Path: "/src/util.go", Diagnostics: []types.Diagnostic{{Severity: "hint", Message: "meh"}}},
+	}
+	client := nvimtest.NewMockClient()
+	client.SetupGetDiagnostics("", all, nil)
+	client.SetupGetDiagnostics("util.go", all[1:], nil)
+	client.SetupGetDiagnostics("/src/main file", all[:1], nil)
+	mcpserver.NewServer(client)
+
+	t.Run("returns diagnostics for all buffers", func(t *testing.T) {
+		assert.Equal(t, all, readDiagnostics(t, DiagnosticsURI))
+	})
+
+	t.Run("filters diagnostics by buffer", func(t *testing.T) {
+		assert.Equal(t, all[1:], readDiagnostics(t, "nvim://diagnostics/util.go"))
+	})
+
+	t.Run("unescapes buffer titles", func(t *testing.T) {
+		assert.Equal(t, all[:1], readDiagnostics(t, "nvim://diagnostics/%2Fsrc%2Fmain%20file"))
+	})
+
+	t.Run("rejects foreign uris", func(t *testing.T) {
+		_, err := DiagnosticsResource(t.Context(), &mcp.ReadResourceRequest{
+			Params: &mcp.ReadResourceParams{URI: "nvim://diagnosticsfoo"},
+		})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestDiagnosticsSubscriber(t *testing.T) {
+	client, watches := watchedClient()
+	server := mcpserver.NewServer(client)
+	subscriber := newDiagnosticsSubscriber(server)
+
+	t.Run("watches diagnostics once", func(t *testing.T) {
+		require.NoError(t, subscriber.Subscribe(t.Context(), DiagnosticsURI))
+		require.NoError(t, subscriber.Subscribe(t.Context(), "nvim://diagnostics/main.go"))
+
+		client.AssertNumberOfCalls(t, "WatchDiagnostics", 1)
+		assert.Equal(t, 1, *watches)
+		assert.Len(t, subscriber.uris, 2)
+	})
+
+	t.Run("forgets uris without subscribers", func(t *testing.T) {
+		require.NoError(t, subscriber.Unsubscribe(t.Context(), "nvim://diagnostics/main.go"))
+
+		assert.Equal(t, map[string]int{DiagnosticsURI: 1}, subscriber.uris)
+	})
+
+	t.Run("stops watching without subscribers", func(t *testing.T) {
+		require.NoError(t, subscriber.Unsubscribe(t.Context(), DiagnosticsURI))
+
+		assert.Empty(t, subscriber.uris)
+		assert.Zero(t, *watches)
+	})
+
+	t.Run("rejects foreign uris", func(t *testing.T) {
+		assert.Error(t, subscriber.Subscribe(t.Context(), "nvim://buffers"))
+	})
+}
+
+func TestDiagnosticsSubscriber_Instances(t *testing.T) {
+	work, _ := watchedClient()
+	notes, _ := watchedClient()
+
+	server := mcpserver.NewServer(nil)
+	require.NoError(t, mcpserver.AddInstance(&types.Instance{Name: "work", Client: work}))
+	require.NoError(t, mcpserver.AddInstance(&types.Instance{Name: "notes", Client: notes}))
+	subscriber := newDiagnosticsSubscriber(server)
+
+	t.Run("watches each instance once", func(t *testing.T) {
+		require.NoError(t, subscriber.Subscribe(t.Context(), DiagnosticsURI))
+		require.NoError(t, subscriber.Subscribe(t.Context(), "nvim://work/diagnostics"))
+		require.NoError(t, subscriber.Subscribe(t.Context(), "nvim://notes/diagnostics/main.go"))
+
+		work.AssertNumberOfCalls(t, "WatchDiagnostics", 1)
+		notes.AssertNumberOfCalls(t, "WatchDiagnostics", 1)
+	})
+
+	t.Run("rejects unknown instances", func(t *testing.T) {
+		assert.Error(t, subscriber.Subscribe(t.Context(), "nvim://scratch/diagnostics"))
+	})
+
+	t.Run("reads the named instance", func(t *testing.T) {
+		diagnostics := []types.BufferDiagnostics{{Handle: 3, Path: "/notes/main.go"}}
+		notes.SetupGetDiagnostics("main.go", diagnostics, nil)
+
+		assert.Equal(t, diagnostics, readDiagnostics(t, "nvim://notes/diagnostics/main.go"))
+	})
+}
+
+func TestDiagnosticsSubscriber_Selection(t *testing.T) {
+	work, workWatches := watchedClient()
+	notes, notesWatches := watchedClient()
+
+	server := mcpserver.NewServer(nil)
+	require.NoError(t, mcpserver.AddInstance(&types.Instance{Name: "work", Client: work}))
+	require.NoError(t, mcpserver.AddInstance(&types.Instance{Name: "notes", Client: notes}))
+	subscriber := newDiagnosticsSubscriber(server)
+	mcpserver.AddResourceSubscriber(DiagnosticsURI, subscriber)
+
+	require.NoError(t, subscriber.Subscribe(t.Context(), DiagnosticsURI))
+	require.NoError(t, subscriber.Subscribe(t.Context(), "nvim://work/diagnostics"))
+
+	t.Run("watches the newly selected instance", func(t *testing.T) {
+		_, err := mcpserver.SelectInstance(t.Context(), "notes")
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, *workWatches)
+		assert.Equal(t, 1, *notesWatches)
+	})
+
+	t.Run("stops watching instances nothing resolves to", func(t *testing.T) {
+		require.NoError(t, subscriber.Unsubscribe(t.Context(), "nvim://work/diagnostics"))
+
+		assert.Zero(t, *workWatches)
+		assert.Equal(t, 1, *notesWatches)
+
+		_, err := mcpserver.SelectInstance(t.Context(), "work")
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, *workWatches)
+		assert.Zero(t, *notesWatches)
+	})
 }
@@ -0,0 +1,41 @@
+package resources
+
+import (
+	"context"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// instanceResources maps resource names to the handlers serving them for any instance
+var instanceResources = map[string]mcp.ResourceHandler{}
+
+// addInstanceTemplate registers the nvim://{instance}/<name> template of the resource
+// called name. Templates can match uris meant for another resource, such as
+// nvim://diagnostics/buffers, so every template is served by routeResource.
+func addInstanceTemplate(server *mcp.Server, name string, template *mcp.ResourceTemplate, handler mcp.ResourceHandler) {
+	instanceResources[name] = handler
+	server.AddResourceTemplate(template, routeResource)
+}
+
+// routeResource dispatches a resource read to the handler of the resource named by its uri
+func routeResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	_, path := mcpserver.ParseResourceURI(req.Params.URI)
+	name, _, _ := strings.Cut(path, "/")
+
+	handler, ok := instanceResources[name]
+	if !ok {
+		return nil, mcp.ResourceNotFoundError(req.Params.URI)
+	}
+
+	return handler(ctx, req)
+}
+
+// instanceClient returns the client of the instance addressed by a resource uri
+func instanceClient(uri string) (types.NeovimClient, error) {
+	instance, _ := mcpserver.ParseResourceURI(uri)
+	return mcpserver.GetInstanceClient(instance)
+}
@@ -0,0 +1,57 @@
+package resources
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestRouteResource(t *testing.T) {
+	server := mcpserver.NewServer(nil)
+	for _, name := range []string{"work", "notes"} {
+		client := nvimtest.NewMockClient()
+		client.SetupGetConnectionStatus(types.ConnectionStatus{Address: "/tmp/" + name + ".sock"}, nil)
+		require.NoError(t, mcpserver.AddInstance(&types.Instance{Name: name, Client: client}))
+	}
+	RegisterAllResources(server)
+
+	read := func(uri string) (*mcp.ReadResourceResult, error) {
+		return routeResource(t.Context(), &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: uri}})
+	}
+
+	t.Run("serves the named instance", func(t *testing.T) {
+		result, err := read("nvim://notes/connection")
+		require.NoError(t, err)
+		require.Len(t, result.Contents, 1)
+		assert.Equal(t, "nvim://notes/connection", result.Contents[0].URI)
+
+		var status types.ConnectionStatus
+		require.NoError(t, json.Unmarshal([]byte(result.Contents[0].Text), &status))
+		assert.Equal(t, "/tmp/notes.sock", status.Address)
+	})
+
+	t.Run("serves the selected instance without a name", func(t *testing.T) {
+		result, err := ConnectionResource(t.Context(), &mcp.ReadResourceRequest{
+			Params: &mcp.ReadResourceParams{URI: "nvim://connection"},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, result.Contents[0].Text, "/tmp/work.sock")
+	})
+
+	t.Run("rejects unknown instances", func(t *testing.T) {
+		_, err := read("nvim://scratch/connection")
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects unknown resources", func(t *testing.T) {
+		_, err := read("nvim://work/registers")
+		assert.Error(t, err)
+	})
+}
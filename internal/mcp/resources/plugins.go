@@ -2,27 +2,52 @@ package resources
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// PluginsResource provides the nvim://plugins resource
 func PluginsResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-	// TODO: Implement
+	nvimClient, err := instanceClient(req.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	inventory, err := nvimClient.GetPlugins(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonInventory, marshalErr := json.Marshal(inventory)
+	if marshalErr != nil {
+		return nil, marshalErr
+	}
+
 	return &mcp.ReadResourceResult{
 		Contents: []*mcp.ResourceContents{
 			{
-				URI:      "nvim://plugins",
+				URI:      req.Params.URI,
 				MIMEType: "application/json",
-				Text:     "[]",
+				Text:     string(jsonInventory),
 			},
 		},
 	}, nil
 }
 
+// RegisterPluginsResource registers the plugins resource
 func RegisterPluginsResource(server *mcp.Server) {
 	server.AddResource(&mcp.Resource{
-		Name:     "plugins",
-		URI:      "nvim://plugins",
-		MIMEType: "application/json",
+		Name:        "plugins",
+		URI:         "nvim://plugins",
+		MIMEType:    "application/json",
+		Description: "Installed plugins with their plugin manager, load state, lazy-load triggers, version and commit",
+	}, PluginsResource)
+
+	addInstanceTemplate(server, "plugins", &mcp.ResourceTemplate{
+		Name:        "instance_plugins",
+		URITemplate: "nvim://{instance}/plugins",
+		MIMEType:    "application/json",
+		Description: "Installed plugins of a Neovim instance",
 	}, PluginsResource)
 }
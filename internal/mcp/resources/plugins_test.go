@@ -1,7 +1,58 @@
 package resources
 
-import "testing"
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
 
 func TestPluginsResource(t *testing.T) {
-	t.Skip("Not implemented")
+	req := &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: "nvim://plugins"}}
+
+	t.Run("returns plugin inventory", func(t *testing.T) {
+		inventory := types.PluginInventory{
+			Managers: []string{"lazy.nvim"},
+			Plugins: []types.PluginInfo{
+				{
+					Name:     "telescope.nvim",
+					Path:     "/home/dev/.local/share/nvim/lazy/telescope.nvim",
+					Manager:  "lazy.nvim",
+					Lazy:     true,
+					Triggers: []string{"cmd:Telescope"},
+					Commit:   "a0bbec2",
+				},
+			},
+		}
+		client := nvimtest.NewMockClient()
+		client.SetupGetPlugins(inventory, nil)
+		mcpserver.NewServer(client)
+
+		result, err := PluginsResource(t.Context(), req)
+		require.NoError(t, err)
+		require.Len(t, result.Contents, 1)
+		assert.Equal(t, "nvim://plugins", result.Contents[0].URI)
+
+		var got types.PluginInventory
+		require.NoError(t, json.Unmarshal([]byte(result.Contents[0].Text), &got))
+		assert.Equal(t, inventory, got)
+		client.AssertExpectations(t)
+	})
+
+	t.Run("returns client errors", func(t *testing.T) {
+		client := nvimtest.NewMockClient()
+		client.SetupGetPlugins(types.PluginInventory{}, errors.New("boom"))
+		mcpserver.NewServer(client)
+
+		_, err := PluginsResource(t.Context(), req)
+
+		assert.EqualError(t, err, "boom")
+	})
 }
@@ -7,8 +7,8 @@ import (
 // RegisterAllResources registers all MCP resources with the server
 func RegisterAllResources(server *mcp.Server) {
 	RegisterBuffersResource(server)
-	// TODO: Implement
-	// RegisterConfigResource(server)
-	// RegisterPluginsResource(server)
-	// RegisterDiagnosticsResource(server)
+	RegisterConfigResource(server)
+	RegisterPluginsResource(server)
+	RegisterConnectionResource(server)
+	RegisterDiagnosticsResource(server)
 }
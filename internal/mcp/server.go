@@ -2,34 +2,198 @@
 package mcp
 
 import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/cousine/neovim-mcp/internal/logger"
 	"github.com/cousine/neovim-mcp/internal/types"
 )
 
-// serverContext holds the nvim client for tool handlers
-var serverContext *types.ServerMeta
+// serverContext holds the neovim instances for tool handlers, guarded by instancesMu
+var serverContext = &types.ServerMeta{}
+
+// ResourceSubscriber reacts to clients subscribing to and unsubscribing from resources
+type ResourceSubscriber interface {
+	Subscribe(ctx context.Context, uri string) error
+	Unsubscribe(ctx context.Context, uri string) error
+}
+
+// SelectionListener is implemented by resource subscribers whose uris without an
+// instance follow the selected instance, SelectInstance calls SelectionChanged once
+// the selection changed
+type SelectionListener interface {
+	SelectionChanged(ctx context.Context)
+}
+
+// subscribers maps resource URI prefixes to their subscribers
+var (
+	subscribersMu sync.RWMutex
+	subscribers   map[string]ResourceSubscriber
+)
+
+// ServerOption configures the MCP server created by NewServer
+type ServerOption func(*serverConfig)
+
+// serverConfig holds the settings applied by ServerOption
+type serverConfig struct {
+	toolTimeout time.Duration
+	discover    DiscoverFunc
+}
 
-// NewServer creates a new MCP server with the Neovim client
-func NewServer(nvimClient types.NeovimClient) *mcp.Server {
-	opts := &mcp.ServerOptions{
-		Logger:       logger.GetLogger(),
-		HasResources: true,
-		HasTools:     true,
+// WithToolTimeout sets the deadline of tool calls and resource reads whose context
+// has none, a zero timeout leaves them unbounded
+func WithToolTimeout(timeout time.Duration) ServerOption {
+	return func(cfg *serverConfig) {
+		cfg.toolTimeout = timeout
 	}
+}
 
-	serverContext = &types.ServerMeta{
-		NvimClient: nvimClient,
+// NewServer creates a new MCP server, a non-nil Neovim client is registered as
+// DefaultInstance and more instances can be added with AddInstance
+func NewServer(nvimClient types.NeovimClient, opts ...ServerOption) *mcp.Server {
+	cfg := &serverConfig{}
+	for _, opt := range opts {
+		opt(cfg)
 	}
 
-	return mcp.NewServer(&mcp.Implementation{
+	serverOpts := &mcp.ServerOptions{
+		Logger:             logger.GetLogger(),
+		HasResources:       true,
+		HasTools:           true,
+		SubscribeHandler:   subscribeHandler,
+		UnsubscribeHandler: unsubscribeHandler,
+	}
+
+	if cfg.discover != nil {
+		serverOpts.InitializedHandler = discoveryHandler(cfg.discover)
+	}
+
+	instancesMu.Lock()
+	serverContext = &types.ServerMeta{}
+	sharedSelection = false
+	discoveryErr = nil
+	instancesMu.Unlock()
+
+	if nvimClient != nil {
+		// the default instance name is always valid and the registry is empty
+		_ = AddInstance(&types.Instance{Name: DefaultInstance, Client: nvimClient})
+	}
+
+	subscribersMu.Lock()
+	subscribers = make(map[string]ResourceSubscriber)
+	subscribersMu.Unlock()
+
+	server := mcp.NewServer(&mcp.Implementation{
 		Name:    "github.com/cousine/neovim-mcp",
 		Version: "v0.1.0",
-	}, opts)
+	}, serverOpts)
+
+	if cfg.toolTimeout > 0 {
+		server.AddReceivingMiddleware(timeoutMiddleware(cfg.toolTimeout))
+	}
+
+	return server
 }
 
-// GetNvimClient extracts the Neovim client from the request
+// GetNvimClient returns the Neovim client of the selected instance, nil without instances
 func GetNvimClient() types.NeovimClient {
-	return serverContext.NvimClient
+	client, err := GetInstanceClient("")
+	if err != nil {
+		return nil
+	}
+
+	return client
+}
+
+// AddResourceSubscriber registers a subscriber for resource URIs starting with prefix
+func AddResourceSubscriber(prefix string, subscriber ResourceSubscriber) {
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+
+	subscribers[prefix] = subscriber
+}
+
+// ----------------------------------------------------------------------------
+
+// timeoutMiddleware bounds tools/call and resources/read requests by timeout unless the
+// request context already carries a deadline
+func timeoutMiddleware(timeout time.Duration) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" && method != "resources/read" {
+				return next(ctx, method, req)
+			}
+
+			if _, ok := ctx.Deadline(); ok {
+				return next(ctx, method, req)
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// findSubscriber returns the subscriber with the longest prefix matching the resource
+// path of uri, so subscribers also receive the uris of every instance
+func findSubscriber(uri string) (ResourceSubscriber, bool) {
+	_, path := ParseResourceURI(uri)
+	uri = ResourceScheme + path
+
+	subscribersMu.RLock()
+	defer subscribersMu.RUnlock()
+
+	var found ResourceSubscriber
+	longest := -1
+	for prefix, subscriber := range subscribers {
+		if strings.HasPrefix(uri, prefix) && len(prefix) > longest {
+			found = subscriber
+			longest = len(prefix)
+		}
+	}
+
+	return found, found != nil
+}
+
+// selectionChanged calls SelectionChanged on the subscribers that implement it
+func selectionChanged(ctx context.Context) {
+	subscribersMu.RLock()
+	var listeners []SelectionListener
+	for _, subscriber := range subscribers {
+		if listener, ok := subscriber.(SelectionListener); ok {
+			listeners = append(listeners, listener)
+		}
+	}
+	subscribersMu.RUnlock()
+
+	for _, listener := range listeners {
+		listener.SelectionChanged(ctx)
+	}
+}
+
+// subscribeHandler dispatches resources/subscribe requests to the registered subscriber
+func subscribeHandler(ctx context.Context, req *mcp.SubscribeRequest) error {
+	subscriber, ok := findSubscriber(req.Params.URI)
+	if !ok {
+		return fmt.Errorf("resource `%s` does not support subscriptions", req.Params.URI)
+	}
+
+	return subscriber.Subscribe(ctx, req.Params.URI)
+}
+
+// unsubscribeHandler dispatches resources/unsubscribe requests to the registered subscriber
+func unsubscribeHandler(ctx context.Context, req *mcp.UnsubscribeRequest) error {
+	subscriber, ok := findSubscriber(req.Params.URI)
+	if !ok {
+		return fmt.Errorf("resource `%s` does not support subscriptions", req.Params.URI)
+	}
+
+	return subscriber.Unsubscribe(ctx, req.Params.URI)
 }
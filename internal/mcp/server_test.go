@@ -1,7 +1,13 @@
 package mcp
 
 import (
+	"context"
 	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewServer(t *testing.T) {
@@ -13,3 +19,52 @@ func TestGetNvimClient(t *testing.T) {
 	// TODO: Implement tests
 	t.Skip("Not implemented")
 }
+
+func TestTimeoutMiddleware(t *testing.T) {
+	// deadline records the deadline of the context passed to the wrapped handler
+	deadline := func(t *testing.T, ctx context.Context, method string) (time.Time, bool) {
+		t.Helper()
+
+		var got time.Time
+		var ok bool
+		handler := timeoutMiddleware(time.Minute)(func(ctx context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+			got, ok = ctx.Deadline()
+			return nil, nil
+		})
+
+		_, err := handler(ctx, method, nil)
+		require.NoError(t, err)
+
+		return got, ok
+	}
+
+	t.Run("bounds tool calls", func(t *testing.T) {
+		got, ok := deadline(t, t.Context(), "tools/call")
+
+		require.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), got, 5*time.Second)
+	})
+
+	t.Run("bounds resource reads", func(t *testing.T) {
+		_, ok := deadline(t, t.Context(), "resources/read")
+
+		assert.True(t, ok)
+	})
+
+	t.Run("keeps existing deadlines", func(t *testing.T) {
+		want := time.Now().Add(time.Hour)
+		ctx, cancel := context.WithDeadline(t.Context(), want)
+		defer cancel()
+
+		got, ok := deadline(t, ctx, "tools/call")
+
+		require.True(t, ok)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("ignores other methods", func(t *testing.T) {
+		_, ok := deadline(t, t.Context(), "tools/list")
+
+		assert.False(t, ok)
+	})
+}
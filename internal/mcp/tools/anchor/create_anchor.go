@@ -0,0 +1,59 @@
+// Package anchor implements neovim anchor mcp tools
+package anchor
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// CreateAnchorInput dto for create anchor request
+type CreateAnchorInput struct {
+	mcpserver.InstanceInput
+
+	Name        string `json:"name" jsonschema:"anchor name, an existing anchor of the same name is moved"`
+	BufferTitle string `json:"buffer_title" jsonschema:"buffer handle, absolute or cwd-relative path, file:// URI, or unique filename"`
+	StartLine   int    `json:"start_line" jsonschema:"starting line number (1-based, inclusive)"`
+	StartColumn int    `json:"start_column,omitempty" jsonschema:"starting byte column (1-based, inclusive), leave out both columns to anchor whole lines"`
+	EndLine     int    `json:"end_line" jsonschema:"ending line number (1-based, inclusive)"`
+	EndColumn   int    `json:"end_column,omitempty" jsonschema:"ending byte column (1-based, exclusive)"`
+}
+
+// CreateAnchorOutput dto for create anchor response
+type CreateAnchorOutput struct {
+	Anchor types.Anchor `json:"anchor" jsonschema:"the created anchor"`
+}
+
+// CreateAnchorHandler handles create anchor
+func CreateAnchorHandler(ctx context.Context, req *mcp.CallToolRequest, input CreateAnchorInput) (*mcp.CallToolResult, CreateAnchorOutput, error) {
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, CreateAnchorOutput{}, err
+	}
+
+	anchor, err := nvimClient.CreateAnchor(ctx, input.Name, input.BufferTitle, types.TextRange{
+		StartLine:   input.StartLine,
+		StartColumn: input.StartColumn,
+		EndLine:     input.EndLine,
+		EndColumn:   input.EndColumn,
+	})
+	if err != nil {
+		return nil, CreateAnchorOutput{}, err
+	}
+
+	return nil, CreateAnchorOutput{
+		Anchor: anchor,
+	}, nil
+}
+
+// RegisterCreateAnchorTool registers the create anchor tool
+func RegisterCreateAnchorTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "create_anchor",
+		Description: "Name a range of a buffer so later reads and edits can refer to it by the anchor name. " +
+			"The anchor is an extmark, it follows the code as lines above it are added or removed.",
+	}, CreateAnchorHandler)
+}
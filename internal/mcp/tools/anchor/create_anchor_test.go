@@ -0,0 +1,30 @@
+package anchor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestCreateAnchorHandler(t *testing.T) {
+	anchor := types.Anchor{Name: "init", Buffer: 3, Range: types.TextRange{StartLine: 4, StartColumn: 1, EndLine: 9, EndColumn: 2}}
+	client := nvimtest.NewMockClient()
+	client.SetupCreateAnchor("init", "main.go", types.TextRange{StartLine: 4, EndLine: 9}, anchor, nil)
+	mcpserver.NewServer(client)
+
+	_, output, err := CreateAnchorHandler(t.Context(), nil, CreateAnchorInput{
+		Name:        "init",
+		BufferTitle: "main.go",
+		StartLine:   4,
+		EndLine:     9,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, anchor, output.Anchor)
+	client.AssertExpectations(t)
+}
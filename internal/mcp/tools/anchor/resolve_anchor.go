@@ -0,0 +1,47 @@
+package anchor
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// ResolveAnchorInput dto for resolve anchor request
+type ResolveAnchorInput struct {
+	mcpserver.InstanceInput
+
+	Name string `json:"name" jsonschema:"anchor name"`
+}
+
+// ResolveAnchorOutput dto for resolve anchor response
+type ResolveAnchorOutput struct {
+	Anchor types.Anchor `json:"anchor" jsonschema:"current range and lines of the anchor"`
+}
+
+// ResolveAnchorHandler handles resolve anchor
+func ResolveAnchorHandler(ctx context.Context, req *mcp.CallToolRequest, input ResolveAnchorInput) (*mcp.CallToolResult, ResolveAnchorOutput, error) {
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, ResolveAnchorOutput{}, err
+	}
+
+	anchor, err := nvimClient.ResolveAnchor(ctx, input.Name)
+	if err != nil {
+		return nil, ResolveAnchorOutput{}, err
+	}
+
+	return nil, ResolveAnchorOutput{
+		Anchor: anchor,
+	}, nil
+}
+
+// RegisterResolveAnchorTool registers the resolve anchor tool
+func RegisterResolveAnchorTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "resolve_anchor",
+		Description: "Return the current range, lines and buffer version of an anchor",
+	}, ResolveAnchorHandler)
+}
@@ -0,0 +1,37 @@
+package anchor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestResolveAnchorHandler(t *testing.T) {
+	t.Run("returns the anchor", func(t *testing.T) {
+		anchor := types.Anchor{Name: "init", Lines: []string{"func init() {}"}, Version: 5}
+		client := nvimtest.NewMockClient()
+		client.SetupResolveAnchor("init", anchor, nil)
+		mcpserver.NewServer(client)
+
+		_, output, err := ResolveAnchorHandler(t.Context(), nil, ResolveAnchorInput{Name: "init"})
+		require.NoError(t, err)
+
+		assert.Equal(t, anchor, output.Anchor)
+		client.AssertExpectations(t)
+	})
+
+	t.Run("reports missing anchors", func(t *testing.T) {
+		client := nvimtest.NewMockClient()
+		client.SetupResolveAnchor("gone", types.Anchor{}, nvim.ErrAnchorNotFound)
+		mcpserver.NewServer(client)
+
+		_, _, err := ResolveAnchorHandler(t.Context(), nil, ResolveAnchorInput{Name: "gone"})
+		assert.ErrorIs(t, err, nvim.ErrAnchorNotFound)
+	})
+}
@@ -11,7 +11,9 @@ import (
 
 // CloseBufferInput dto for closing a neovim buffer request
 type CloseBufferInput struct {
-	Title string `json:"title" jsonschema:"buffer title or filename to close"`
+	mcpserver.InstanceInput
+
+	Title string `json:"title" jsonschema:"buffer handle, absolute or cwd-relative path, file:// URI, or unique filename of the buffer to close"`
 }
 
 // CloseBufferOutput dto for closing a neovim buffer response
@@ -22,9 +24,12 @@ type CloseBufferOutput struct {
 
 // CloseBufferHandler handles closing a neovim buffer
 func CloseBufferHandler(ctx context.Context, req *mcp.CallToolRequest, input CloseBufferInput) (*mcp.CallToolResult, CloseBufferOutput, error) {
-	nvimClient := mcpserver.GetNvimClient()
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, CloseBufferOutput{}, err
+	}
 
-	err := nvimClient.CloseBuffer(ctx, input.Title)
+	err = nvimClient.CloseBuffer(ctx, input.Title)
 	if err != nil {
 		return nil, CloseBufferOutput{}, err
 	}
@@ -38,6 +43,6 @@ func CloseBufferHandler(ctx context.Context, req *mcp.CallToolRequest, input Clo
 func RegisterCloseBufferTool(server *mcp.Server) {
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "close_buffer",
-		Description: "Close a buffer by its title or filename",
+		Description: "Close a buffer by handle, path, file:// URI or unique filename",
 	}, CloseBufferHandler)
 }
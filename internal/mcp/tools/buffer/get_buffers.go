@@ -9,8 +9,10 @@ import (
 	"github.com/cousine/neovim-mcp/internal/types"
 )
 
-// GetBuffersInput defines the input (no parameters needed)
-type GetBuffersInput struct{}
+// GetBuffersInput defines the input (only the target instance)
+type GetBuffersInput struct {
+	mcpserver.InstanceInput
+}
 
 // GetBuffersOutput defines the structured output
 type GetBuffersOutput struct {
@@ -23,7 +25,10 @@ func GetBuffersHandler(
 	req *mcp.CallToolRequest,
 	input GetBuffersInput,
 ) (*mcp.CallToolResult, GetBuffersOutput, error) {
-	nvimClient := mcpserver.GetNvimClient()
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, GetBuffersOutput{}, err
+	}
 
 	buffers, err := nvimClient.GetBuffers(ctx)
 	if err != nil {
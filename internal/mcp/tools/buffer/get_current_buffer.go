@@ -11,7 +11,9 @@ import (
 )
 
 // GetCurrentBufferInput dto for get current buffer request
-type GetCurrentBufferInput struct{}
+type GetCurrentBufferInput struct {
+	mcpserver.InstanceInput
+}
 
 // GetCurrentBufferOutput dto for get current buffer response
 type GetCurrentBufferOutput struct {
@@ -20,7 +22,10 @@ type GetCurrentBufferOutput struct {
 
 // GetCurrentBufferHandler handles get current buffer mcp tool request
 func GetCurrentBufferHandler(ctx context.Context, req *mcp.CallToolRequest, input GetCurrentBufferInput) (*mcp.CallToolResult, GetCurrentBufferOutput, error) {
-	nvimClient := mcpserver.GetNvimClient()
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, GetCurrentBufferOutput{}, err
+	}
 
 	bufInfo, err := nvimClient.GetCurrentBuffer(ctx)
 	if err != nil {
@@ -11,6 +11,8 @@ import (
 
 // OpenBufferInput dto for opening a neovim buffer request
 type OpenBufferInput struct {
+	mcpserver.InstanceInput
+
 	Path string `json:"path" jsonschema:"file path to open"`
 }
 
@@ -21,7 +23,10 @@ type OpenBufferOutput struct {
 
 // OpenBufferHandler handles opening a neovim buffer
 func OpenBufferHandler(ctx context.Context, req *mcp.CallToolRequest, input OpenBufferInput) (*mcp.CallToolResult, OpenBufferOutput, error) {
-	nvimClient := mcpserver.GetNvimClient()
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, OpenBufferOutput{}, err
+	}
 
 	bufInfo, err := nvimClient.OpenBuffer(ctx, input.Path)
 	if err != nil {
@@ -10,7 +10,9 @@ import (
 
 // SwitchBufferInput dto for switching a neovim buffer request
 type SwitchBufferInput struct {
-	Title string `json:"title" jsonschema:"buffer title or filename to switch to"`
+	mcpserver.InstanceInput
+
+	Title string `json:"title" jsonschema:"buffer handle, absolute or cwd-relative path, file:// URI, or unique filename of the buffer to switch to"`
 }
 
 // SwitchBufferOutput dto for switching a neovim buffer response
@@ -20,9 +22,12 @@ type SwitchBufferOutput struct {
 
 // SwitchBufferHandler handles switching a neovim buffer
 func SwitchBufferHandler(ctx context.Context, req *mcp.CallToolRequest, input SwitchBufferInput) (*mcp.CallToolResult, SwitchBufferOutput, error) {
-	nvimClient := mcpserver.GetNvimClient()
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, SwitchBufferOutput{}, err
+	}
 
-	err := nvimClient.SwitchBuffer(ctx, input.Title)
+	err = nvimClient.SwitchBuffer(ctx, input.Title)
 	if err != nil {
 		return nil, SwitchBufferOutput{}, err
 	}
@@ -36,6 +41,6 @@ func SwitchBufferHandler(ctx context.Context, req *mcp.CallToolRequest, input Sw
 func RegisterSwitchBufferTool(server *mcp.Server) {
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "switch_buffer",
-		Description: "Switch to a different buffer by its title or filename",
+		Description: "Switch to a different buffer by handle, path, file:// URI or unique filename",
 	}, SwitchBufferHandler)
 }
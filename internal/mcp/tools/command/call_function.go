@@ -11,6 +11,8 @@ import (
 
 // CallFunctionInput dto for calling a neovim function request
 type CallFunctionInput struct {
+	mcpserver.InstanceInput
+
 	FunctionName string `json:"function_name" jsonschema:"Vim/Neovim function name"`
 	Args         []any  `json:"args,omitempty" jsonschema:"function arguments"`
 }
@@ -22,7 +24,10 @@ type CallFunctionOutput struct {
 
 // CallFunctionHandler handles calling a neovim function
 func CallFunctionHandler(ctx context.Context, req *mcp.CallToolRequest, input CallFunctionInput) (*mcp.CallToolResult, CallFunctionOutput, error) {
-	nvimClient := mcpserver.GetNvimClient()
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, CallFunctionOutput{}, err
+	}
 
 	result, err := nvimClient.CallFunction(ctx, input.FunctionName, input.Args)
 	if err != nil {
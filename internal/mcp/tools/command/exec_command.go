@@ -10,6 +10,8 @@ import (
 
 // ExecCommandInput dto for exec neovim command request
 type ExecCommandInput struct {
+	mcpserver.InstanceInput
+
 	Command string `json:"command" jsonschema:"Ex command to execute (e.g., 'w', 'q', 'tabnew')"`
 }
 
@@ -20,7 +22,10 @@ type ExecCommandOutput struct {
 
 // ExecCommandHandler handles executing a neovim command
 func ExecCommandHandler(ctx context.Context, req *mcp.CallToolRequest, input ExecCommandInput) (*mcp.CallToolResult, ExecCommandOutput, error) {
-	nvimClient := mcpserver.GetNvimClient()
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, ExecCommandOutput{}, err
+	}
 
 	result, err := nvimClient.ExecCommand(ctx, input.Command)
 	if err != nil {
@@ -10,6 +10,8 @@ import (
 
 // ExecLuaInput dto for exec lua in neovim request
 type ExecLuaInput struct {
+	mcpserver.InstanceInput
+
 	Code string `json:"code" jsonschema:"Lua code to execute"`
 	Args []any  `json:"args,omitempty" jsonschema:"optional arguments to pass to Lua code"`
 }
@@ -21,7 +23,10 @@ type ExecLuaOutput struct {
 
 // ExecLuaHandler handles execuing lua in neovim
 func ExecLuaHandler(ctx context.Context, req *mcp.CallToolRequest, input ExecLuaInput) (*mcp.CallToolResult, ExecLuaOutput, error) {
-	nvimClient := mcpserver.GetNvimClient()
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, ExecLuaOutput{}, err
+	}
 
 	result, err := nvimClient.ExecLua(ctx, input.Code, input.Args)
 	if err != nil {
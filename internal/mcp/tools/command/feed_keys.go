@@ -0,0 +1,63 @@
+package command
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/neovim/go-client/nvim"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// FeedKeysInput dto for feed keys request
+type FeedKeysInput struct {
+	mcpserver.InstanceInput
+
+	Keys      string `json:"keys" jsonschema:"normal mode keys in key notation (e.g. 'dap', '>i{', 'gq}', 'ciwfoo<Esc>')"`
+	Method    string `json:"method,omitempty" jsonschema:"normal (default) runs the keys with :normal, feedkeys puts them in the typeahead and executes it"`
+	Remap     bool   `json:"remap,omitempty" jsonschema:"apply user mappings to the keys, off by default"`
+	Escape    bool   `json:"escape,omitempty" jsonschema:"end the keys with <Esc> so an incomplete command or insert mode is finished"`
+	Window    int    `json:"window,omitempty" jsonschema:"handle of the window to run the keys in, defaults to the current window"`
+	StartLine int    `json:"start_line,omitempty" jsonschema:"run the keys on each line from this line (1-based), normal method only"`
+	EndLine   int    `json:"end_line,omitempty" jsonschema:"last line to run the keys on (1-based, inclusive)"`
+}
+
+// FeedKeysOutput dto for feed keys response
+type FeedKeysOutput struct {
+	types.FeedKeysResult
+}
+
+// FeedKeysHandler handles running normal mode keys
+func FeedKeysHandler(ctx context.Context, req *mcp.CallToolRequest, input FeedKeysInput) (*mcp.CallToolResult, FeedKeysOutput, error) {
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, FeedKeysOutput{}, err
+	}
+
+	result, err := nvimClient.FeedKeys(ctx, input.Keys, types.FeedKeysOptions{
+		Method:    input.Method,
+		Remap:     input.Remap,
+		Escape:    input.Escape,
+		Window:    nvim.Window(input.Window),
+		StartLine: input.StartLine,
+		EndLine:   input.EndLine,
+	})
+	if err != nil {
+		return nil, FeedKeysOutput{}, err
+	}
+
+	return nil, FeedKeysOutput{
+		FeedKeysResult: result,
+	}, nil
+}
+
+// RegisterFeedKeysTool registers the feed keys tool
+func RegisterFeedKeysTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "feed_keys",
+		Description: "Run normal mode keys such as motions and operators that have no direct API, optionally in a window " +
+			"or on each line of a range. Reports whether they left neovim pending, in insert mode or blocked, " +
+			"then puts it back in normal mode.",
+	}, FeedKeysHandler)
+}
@@ -0,0 +1,52 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestFeedKeysHandler(t *testing.T) {
+	t.Run("maps the options", func(t *testing.T) {
+		result := types.FeedKeysResult{ModeBefore: "n", ModeAfter: "i", State: types.EditorStateInsert, Restored: true, Mode: "n"}
+		client := nvimtest.NewMockClient()
+		client.SetupFeedKeys("A;", types.FeedKeysOptions{
+			Method:    types.FeedKeysNormal,
+			Remap:     true,
+			Escape:    true,
+			Window:    1001,
+			StartLine: 2,
+			EndLine:   4,
+		}, result, nil)
+		mcpserver.NewServer(client)
+
+		_, output, err := FeedKeysHandler(t.Context(), nil, FeedKeysInput{
+			Keys:      "A;",
+			Method:    types.FeedKeysNormal,
+			Remap:     true,
+			Escape:    true,
+			Window:    1001,
+			StartLine: 2,
+			EndLine:   4,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, result, output.FeedKeysResult)
+		client.AssertExpectations(t)
+	})
+
+	t.Run("reports a blocked editor", func(t *testing.T) {
+		client := nvimtest.NewMockClient()
+		client.SetupFeedKeys("dd", types.FeedKeysOptions{}, types.FeedKeysResult{}, nvim.ErrEditorBlocked)
+		mcpserver.NewServer(client)
+
+		_, _, err := FeedKeysHandler(t.Context(), nil, FeedKeysInput{Keys: "dd"})
+		assert.ErrorIs(t, err, nvim.ErrEditorBlocked)
+	})
+}
@@ -0,0 +1,47 @@
+package command
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+)
+
+// SendInputInput dto for send input request
+type SendInputInput struct {
+	mcpserver.InstanceInput
+
+	Keys string `json:"keys" jsonschema:"keys to type, in the current mode and with mappings applied (e.g. 'ihello<Esc>')"`
+}
+
+// SendInputOutput dto for send input response
+type SendInputOutput struct {
+	Success bool `json:"success" jsonschema:"whether the keys were queued"`
+}
+
+// SendInputHandler handles sending keystrokes to neovim
+func SendInputHandler(ctx context.Context, req *mcp.CallToolRequest, input SendInputInput) (*mcp.CallToolResult, SendInputOutput, error) {
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, SendInputOutput{}, err
+	}
+
+	err = nvimClient.SendInput(ctx, input.Keys)
+	if err != nil {
+		return nil, SendInputOutput{}, err
+	}
+
+	return nil, SendInputOutput{
+		Success: true,
+	}, nil
+}
+
+// RegisterSendInputTool registers the send input tool
+func RegisterSendInputTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "send_input",
+		Description: "Type keys into neovim as if the user pressed them. What they do depends on the current mode, " +
+			"in normal mode they run as commands; use insert_text to add text.",
+	}, SendInputHandler)
+}
@@ -0,0 +1,7 @@
+package command
+
+import "testing"
+
+func TestSendInputHandler(t *testing.T) {
+	t.Skip("Not implemented")
+}
@@ -0,0 +1,77 @@
+package cursor
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// FindReplaceInput dto for find and replace request
+type FindReplaceInput struct {
+	mcpserver.InstanceInput
+
+	Pattern      string   `json:"pattern" jsonschema:"search pattern"`
+	Replacement  string   `json:"replacement" jsonschema:"replacement text, re2 replacements refer to capture groups as $1 or ${name}"`
+	Root         string   `json:"root,omitempty" jsonschema:"directory to search, absolute or relative to the neovim cwd; defaults to the cwd"`
+	Glob         string   `json:"glob,omitempty" jsonschema:"preview: gitignore-style glob files must match, such as *.go or internal/**/*.go"`
+	Flavor       string   `json:"flavor,omitempty" jsonschema:"pattern syntax: re2 (Go regexp, default, ^ and $ match at line boundaries) or literal"`
+	IgnoreCase   bool     `json:"ignore_case,omitempty" jsonschema:"match case-insensitively, case matters by default"`
+	ContextLines int      `json:"context_lines,omitempty" jsonschema:"preview: number of lines to return before and after each match"`
+	Offset       int      `json:"offset,omitempty" jsonschema:"preview: number of matches to skip, pass next_offset to get the next page"`
+	MaxResults   int      `json:"max_results,omitempty" jsonschema:"preview: maximum number of matches to return, defaults to 100"`
+	Apply        bool     `json:"apply,omitempty" jsonschema:"apply the accepted matches instead of previewing, with the pattern, replacement, root, flavor and ignore_case of the preview"`
+	Accept       []string `json:"accept,omitempty" jsonschema:"apply: IDs of the previewed matches to replace"`
+}
+
+// FindReplaceOutput dto for find and replace response
+type FindReplaceOutput struct {
+	Preview *types.FindReplacePreview `json:"preview,omitempty" jsonschema:"proposed replacements, when previewing"`
+	Result  *types.FindReplaceResult  `json:"result,omitempty" jsonschema:"applied replacements, when applying"`
+}
+
+// FindReplaceHandler handles find and replace
+func FindReplaceHandler(ctx context.Context, req *mcp.CallToolRequest, input FindReplaceInput) (*mcp.CallToolResult, FindReplaceOutput, error) {
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, FindReplaceOutput{}, err
+	}
+
+	opts := types.GrepOptions{
+		Root:         input.Root,
+		Glob:         input.Glob,
+		Flavor:       input.Flavor,
+		IgnoreCase:   input.IgnoreCase,
+		ContextLines: input.ContextLines,
+		Offset:       input.Offset,
+		MaxResults:   input.MaxResults,
+	}
+
+	if input.Apply {
+		result, err := nvimClient.ApplyFindReplace(ctx, input.Pattern, input.Replacement, input.Accept, opts)
+		if err != nil {
+			return nil, FindReplaceOutput{}, err
+		}
+
+		return nil, FindReplaceOutput{Result: &result}, nil
+	}
+
+	preview, err := nvimClient.PreviewFindReplace(ctx, input.Pattern, input.Replacement, opts)
+	if err != nil {
+		return nil, FindReplaceOutput{}, err
+	}
+
+	return nil, FindReplaceOutput{Preview: &preview}, nil
+}
+
+// RegisterFindReplaceTool registers the find and replace tool
+func RegisterFindReplaceTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "find_replace",
+		Description: "Find and replace across the project in two steps. First preview the matches with their replacements and IDs, " +
+			"then call again with apply and the accepted IDs to write them into buffers, loading files that are not open. " +
+			"Files changed since the preview are skipped. Changes are left unsaved, one undo step per file.",
+	}, FindReplaceHandler)
+}
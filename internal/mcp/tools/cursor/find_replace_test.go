@@ -0,0 +1,81 @@
+package cursor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestFindReplaceHandler(t *testing.T) {
+	t.Run("previews the replacements", func(t *testing.T) {
+		preview := types.FindReplacePreview{
+			Root: "/src",
+			Matches: []types.ReplaceMatch{{
+				ID:          "a.go:0123abcd:1",
+				GrepMatch:   types.GrepMatch{File: "a.go", SearchResult: types.SearchResult{Line: 2, Column: 6, EndLine: 2, EndColumn: 14, MatchText: "old_name"}},
+				Replacement: "new_name",
+			}},
+			Truncated:  true,
+			NextOffset: 1,
+		}
+		client := nvimtest.NewMockClient()
+		client.SetupPreviewFindReplace(`(\w+)_name`, "new_name", types.GrepOptions{
+			Root:         "/src",
+			Glob:         "*.go",
+			IgnoreCase:   true,
+			ContextLines: 2,
+			MaxResults:   1,
+		}, preview, nil)
+		mcpserver.NewServer(client)
+
+		_, output, err := FindReplaceHandler(t.Context(), nil, FindReplaceInput{
+			Pattern:      `(\w+)_name`,
+			Replacement:  "new_name",
+			Root:         "/src",
+			Glob:         "*.go",
+			IgnoreCase:   true,
+			ContextLines: 2,
+			MaxResults:   1,
+		})
+		require.NoError(t, err)
+
+		require.NotNil(t, output.Preview)
+		assert.Equal(t, preview, *output.Preview)
+		assert.Nil(t, output.Result)
+		client.AssertExpectations(t)
+	})
+
+	t.Run("applies the accepted matches", func(t *testing.T) {
+		accept := []string{"a.go:0123abcd:1", "b.go:4567ef01:2", "b.go:4567ef01:3"}
+		result := types.FindReplaceResult{
+			Replaced: 1,
+			Files:    []types.ReplacedFile{{File: "a.go", Buffer: 3, Replaced: 1, Version: 12, UndoSeq: 4}},
+			Skipped:  []types.SkippedFile{{File: "b.go", IDs: accept[1:], Reason: "content changed since the preview"}},
+		}
+		client := nvimtest.NewMockClient()
+		client.SetupApplyFindReplace("old", "new", accept, types.GrepOptions{Root: "/src", Flavor: types.SearchFlavorLiteral}, result, nil)
+		mcpserver.NewServer(client)
+
+		_, output, err := FindReplaceHandler(t.Context(), nil, FindReplaceInput{
+			Pattern:     "old",
+			Replacement: "new",
+			Root:        "/src",
+			Flavor:      types.SearchFlavorLiteral,
+			Apply:       true,
+			Accept:      accept,
+		})
+		require.NoError(t, err)
+
+		require.NotNil(t, output.Result)
+		assert.Nil(t, output.Preview)
+		assert.Equal(t, result, *output.Result)
+		require.Len(t, output.Result.Skipped, 1)
+		assert.Equal(t, []string{"b.go:4567ef01:2", "b.go:4567ef01:3"}, output.Result.Skipped[0].IDs)
+		client.AssertExpectations(t)
+	})
+}
@@ -10,7 +10,9 @@ import (
 )
 
 // GetCursorPositionInput dto for neovim cursor position request
-type GetCursorPositionInput struct{}
+type GetCursorPositionInput struct {
+	mcpserver.InstanceInput
+}
 
 // GetCursorPositionOutput dto for neovim cursor position response
 type GetCursorPositionOutput struct {
@@ -21,7 +23,10 @@ type GetCursorPositionOutput struct {
 
 // GetCursorPositionHandler handles getting neovim's cursor position
 func GetCursorPositionHandler(ctx context.Context, req *mcp.CallToolRequest, input GetCursorPositionInput) (*mcp.CallToolResult, GetCursorPositionOutput, error) {
-	nvimClient := mcpserver.GetNvimClient()
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, GetCursorPositionOutput{}, err
+	}
 
 	cursor, err := nvimClient.GetCursorPosition(ctx)
 	if err != nil {
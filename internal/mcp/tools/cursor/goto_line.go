@@ -10,6 +10,8 @@ import (
 
 // GotoLineInput dto for go to line in neovim request
 type GotoLineInput struct {
+	mcpserver.InstanceInput
+
 	Line int `json:"line" jsonschema:"line number to jump to (1-based)"`
 }
 
@@ -20,9 +22,12 @@ type GotoLineOutput struct {
 
 // GotoLineHandler handles go to line in neovim
 func GotoLineHandler(ctx context.Context, req *mcp.CallToolRequest, input GotoLineInput) (*mcp.CallToolResult, GotoLineOutput, error) {
-	nvimClient := mcpserver.GetNvimClient()
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, GotoLineOutput{}, err
+	}
 
-	err := nvimClient.GotoLine(ctx, input.Line)
+	err = nvimClient.GotoLine(ctx, input.Line)
 	if err != nil {
 		return nil, GotoLineOutput{}, err
 	}
@@ -0,0 +1,63 @@
+package cursor
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// GrepProjectInput dto for grep project request
+type GrepProjectInput struct {
+	mcpserver.InstanceInput
+
+	Pattern      string `json:"pattern" jsonschema:"search pattern"`
+	Root         string `json:"root,omitempty" jsonschema:"directory to search, absolute or relative to the neovim cwd; defaults to the cwd"`
+	Glob         string `json:"glob,omitempty" jsonschema:"gitignore-style glob files must match, such as *.go or internal/**/*.go"`
+	Flavor       string `json:"flavor,omitempty" jsonschema:"pattern syntax: re2 (Go regexp, default, ^ and $ match at line boundaries) or literal"`
+	IgnoreCase   bool   `json:"ignore_case,omitempty" jsonschema:"match case-insensitively, case matters by default"`
+	ContextLines int    `json:"context_lines,omitempty" jsonschema:"number of lines to return before and after each match"`
+	Offset       int    `json:"offset,omitempty" jsonschema:"number of matches to skip, pass next_offset to get the next page"`
+	MaxResults   int    `json:"max_results,omitempty" jsonschema:"maximum number of matches to return, defaults to 100"`
+}
+
+// GrepProjectOutput dto for grep project response
+type GrepProjectOutput struct {
+	types.GrepResults
+}
+
+// GrepProjectHandler handles grep project
+func GrepProjectHandler(ctx context.Context, req *mcp.CallToolRequest, input GrepProjectInput) (*mcp.CallToolResult, GrepProjectOutput, error) {
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, GrepProjectOutput{}, err
+	}
+
+	results, err := nvimClient.GrepProject(ctx, input.Pattern, types.GrepOptions{
+		Root:         input.Root,
+		Glob:         input.Glob,
+		Flavor:       input.Flavor,
+		IgnoreCase:   input.IgnoreCase,
+		ContextLines: input.ContextLines,
+		Offset:       input.Offset,
+		MaxResults:   input.MaxResults,
+	})
+	if err != nil {
+		return nil, GrepProjectOutput{}, err
+	}
+
+	return nil, GrepProjectOutput{
+		GrepResults: results,
+	}, nil
+}
+
+// RegisterGrepProjectTool registers the grep project tool
+func RegisterGrepProjectTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "grep_project",
+		Description: "Search the files of the project, skipping files ignored by git and binary files. " +
+			"Files with unsaved changes in neovim are searched as they are in the editor.",
+	}, GrepProjectHandler)
+}
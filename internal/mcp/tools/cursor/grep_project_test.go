@@ -0,0 +1,74 @@
+package cursor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestGrepProjectHandler(t *testing.T) {
+	t.Run("maps the options", func(t *testing.T) {
+		results := types.GrepResults{
+			Root: "/src",
+			Matches: []types.GrepMatch{{
+				File:         "internal/a.go",
+				SearchResult: types.SearchResult{Line: 3, Column: 4, EndLine: 3, EndColumn: 8, MatchText: "todo", Before: []string{"package a"}},
+			}},
+		}
+		client := nvimtest.NewMockClient()
+		client.SetupGrepProject("TODO", types.GrepOptions{
+			Root:         "/src",
+			Glob:         "internal/**/*.go",
+			Flavor:       types.SearchFlavorLiteral,
+			IgnoreCase:   true,
+			ContextLines: 1,
+		}, results, nil)
+		mcpserver.NewServer(client)
+
+		_, output, err := GrepProjectHandler(t.Context(), nil, GrepProjectInput{
+			Pattern:      "TODO",
+			Root:         "/src",
+			Glob:         "internal/**/*.go",
+			Flavor:       types.SearchFlavorLiteral,
+			IgnoreCase:   true,
+			ContextLines: 1,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, results, output.GrepResults)
+		client.AssertExpectations(t)
+	})
+
+	t.Run("pages with next_offset", func(t *testing.T) {
+		first := types.GrepResults{
+			Root:       "/src",
+			Matches:    []types.GrepMatch{{File: "a.go", SearchResult: types.SearchResult{Line: 1, Column: 1, EndLine: 1, EndColumn: 4, MatchText: "foo"}}},
+			Truncated:  true,
+			NextOffset: 1,
+		}
+		second := types.GrepResults{
+			Root:    "/src",
+			Matches: []types.GrepMatch{{File: "b.go", Unsaved: true, SearchResult: types.SearchResult{Line: 2, Column: 1, EndLine: 2, EndColumn: 4, MatchText: "foo"}}},
+		}
+		client := nvimtest.NewMockClient()
+		client.SetupGrepProject("foo", types.GrepOptions{MaxResults: 1}, first, nil)
+		client.SetupGrepProject("foo", types.GrepOptions{Offset: 1, MaxResults: 1}, second, nil)
+		mcpserver.NewServer(client)
+
+		_, output, err := GrepProjectHandler(t.Context(), nil, GrepProjectInput{Pattern: "foo", MaxResults: 1})
+		require.NoError(t, err)
+		assert.True(t, output.Truncated)
+		assert.Equal(t, 1, output.NextOffset)
+
+		_, output, err = GrepProjectHandler(t.Context(), nil, GrepProjectInput{Pattern: "foo", Offset: output.NextOffset, MaxResults: 1})
+		require.NoError(t, err)
+		assert.Equal(t, second, output.GrepResults)
+
+		client.AssertExpectations(t)
+	})
+}
@@ -11,33 +11,48 @@ import (
 
 // SearchInput dto for search in neovim request
 type SearchInput struct {
-	Pattern string `json:"pattern" jsonschema:"search pattern (Vim regex)"`
-	Flags   string `json:"flags,omitempty" jsonschema:"search flags: 'w' for wrap, 'b' for backward"`
+	mcpserver.InstanceInput
+
+	BufferTitle  string `json:"buffer_title,omitempty" jsonschema:"buffer handle, absolute or cwd-relative path, file:// URI, or unique filename; defaults to the current buffer"`
+	Pattern      string `json:"pattern" jsonschema:"search pattern"`
+	Flavor       string `json:"flavor,omitempty" jsonschema:"pattern syntax: vim (Vim regex, default), literal or re2 (Go regexp, ^ and $ match at line boundaries)"`
+	IgnoreCase   bool   `json:"ignore_case,omitempty" jsonschema:"match case-insensitively, case matters by default"`
+	MaxResults   int    `json:"max_results,omitempty" jsonschema:"maximum number of matches to return, defaults to 100"`
+	ContextLines int    `json:"context_lines,omitempty" jsonschema:"number of lines to return before and after each match"`
 }
 
 // SearchOutput dto for search in neovim response
 type SearchOutput struct {
-	Matches []types.SearchResult `json:"matches" jsonschema:"array of search matches"`
+	types.SearchResults
 }
 
 // SearchHandler handles search in neovim
 func SearchHandler(ctx context.Context, req *mcp.CallToolRequest, input SearchInput) (*mcp.CallToolResult, SearchOutput, error) {
-	nvimClient := mcpserver.GetNvimClient()
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, SearchOutput{}, err
+	}
 
-	results, err := nvimClient.Search(ctx, input.Pattern, input.Flags)
+	results, err := nvimClient.Search(ctx, input.BufferTitle, input.Pattern, types.SearchOptions{
+		Flavor:       input.Flavor,
+		IgnoreCase:   input.IgnoreCase,
+		MaxResults:   input.MaxResults,
+		ContextLines: input.ContextLines,
+	})
 	if err != nil {
 		return nil, SearchOutput{}, err
 	}
 
 	return nil, SearchOutput{
-		Matches: results,
+		SearchResults: results,
 	}, nil
 }
 
 // RegisterSearchTool registers the search tool
 func RegisterSearchTool(server *mcp.Server) {
 	mcp.AddTool(server, &mcp.Tool{
-		Name:        "search",
-		Description: "Search for a pattern in the current buffer using Vim regex",
+		Name: "search",
+		Description: "Find the matches of a pattern in any buffer with their exact positions, matched text and capture groups. " +
+			"Does not move the cursor or change the search register.",
 	}, SearchHandler)
 }
@@ -10,6 +10,8 @@ import (
 
 // SetCursorPositionInput dto for set cursor position request
 type SetCursorPositionInput struct {
+	mcpserver.InstanceInput
+
 	Line   int `json:"line" jsonschema:"line number (1-based)"`
 	Column int `json:"column" jsonschema:"column number (1-based)"`
 }
@@ -21,9 +23,12 @@ type SetCursorPositionOutput struct {
 
 // SetCursorPositionHandler handles setting cursor position in neovim
 func SetCursorPositionHandler(ctx context.Context, req *mcp.CallToolRequest, input SetCursorPositionInput) (*mcp.CallToolResult, SetCursorPositionOutput, error) {
-	nvimClient := mcpserver.GetNvimClient()
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, SetCursorPositionOutput{}, err
+	}
 
-	err := nvimClient.SetCursorPosition(ctx, input.Line, input.Column)
+	err = nvimClient.SetCursorPosition(ctx, input.Line, input.Column)
 	if err != nil {
 		return nil, SetCursorPositionOutput{}, err
 	}
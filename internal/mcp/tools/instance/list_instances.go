@@ -0,0 +1,41 @@
+// Package instance implements tools managing the neovim instances served by the mcp server
+package instance
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// ListInstancesInput dto for list instances request
+type ListInstancesInput struct{}
+
+// ListInstancesOutput dto for list instances response
+type ListInstancesOutput struct {
+	Instances []types.InstanceInfo `json:"instances" jsonschema:"neovim instances served by this server"`
+}
+
+// ListInstancesHandler handles listing the neovim instances
+func ListInstancesHandler(ctx context.Context, req *mcp.CallToolRequest, input ListInstancesInput) (*mcp.CallToolResult, ListInstancesOutput, error) {
+	instances, _ := mcpserver.ListInstances()
+
+	infos := make([]types.InstanceInfo, 0, len(instances))
+	for _, instance := range instances {
+		infos = append(infos, mcpserver.DescribeInstance(ctx, instance))
+	}
+
+	return nil, ListInstancesOutput{
+		Instances: infos,
+	}, nil
+}
+
+// RegisterListInstancesTool registers the list instances tool
+func RegisterListInstancesTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_instances",
+		Description: "List the Neovim instances this server is connected to, with their socket address, connection state and which one is selected",
+	}, ListInstancesHandler)
+}
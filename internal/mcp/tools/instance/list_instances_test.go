@@ -0,0 +1,45 @@
+package instance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// setupInstances registers a work and a notes instance, work is selected
+func setupInstances(t *testing.T) {
+	t.Helper()
+
+	mcpserver.NewServer(nil)
+	for _, name := range []string{"work", "notes"} {
+		client := nvimtest.NewMockClient()
+		client.SetupGetConnectionStatus(types.ConnectionStatus{State: "connected"}, nil)
+		require.NoError(t, mcpserver.AddInstance(&types.Instance{
+			Name:    name,
+			Address: "/tmp/" + name + ".sock",
+			Client:  client,
+		}))
+	}
+}
+
+func TestListInstancesHandler(t *testing.T) {
+	setupInstances(t)
+
+	_, output, err := ListInstancesHandler(t.Context(), nil, ListInstancesInput{})
+
+	require.NoError(t, err)
+	require.Len(t, output.Instances, 2)
+
+	assert.Equal(t, "work", output.Instances[0].Name)
+	assert.Equal(t, "/tmp/work.sock", output.Instances[0].Address)
+	assert.True(t, output.Instances[0].Selected)
+	assert.Equal(t, "connected", output.Instances[0].Connection.State)
+
+	assert.Equal(t, "notes", output.Instances[1].Name)
+	assert.False(t, output.Instances[1].Selected)
+}
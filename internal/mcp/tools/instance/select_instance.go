@@ -0,0 +1,40 @@
+package instance
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// SelectInstanceInput dto for select instance request
+type SelectInstanceInput struct {
+	Name string `json:"name" jsonschema:"name of the neovim instance to select"`
+}
+
+// SelectInstanceOutput dto for select instance response
+type SelectInstanceOutput struct {
+	Instance types.InstanceInfo `json:"instance" jsonschema:"the selected neovim instance"`
+}
+
+// SelectInstanceHandler handles selecting the default neovim instance
+func SelectInstanceHandler(ctx context.Context, req *mcp.CallToolRequest, input SelectInstanceInput) (*mcp.CallToolResult, SelectInstanceOutput, error) {
+	instance, err := mcpserver.SelectInstance(ctx, input.Name)
+	if err != nil {
+		return nil, SelectInstanceOutput{}, err
+	}
+
+	return nil, SelectInstanceOutput{
+		Instance: mcpserver.DescribeInstance(ctx, instance),
+	}, nil
+}
+
+// RegisterSelectInstanceTool registers the select instance tool
+func RegisterSelectInstanceTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "select_instance",
+		Description: "Select the Neovim instance used by tools and resources that do not name one. Not available over the HTTP transport, whose sessions share the selection: pass `instance` to each tool there",
+	}, SelectInstanceHandler)
+}
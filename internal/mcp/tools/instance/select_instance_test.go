@@ -0,0 +1,31 @@
+package instance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+)
+
+func TestSelectInstanceHandler(t *testing.T) {
+	setupInstances(t)
+
+	t.Run("selects an instance", func(t *testing.T) {
+		_, output, err := SelectInstanceHandler(t.Context(), nil, SelectInstanceInput{Name: "notes"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "notes", output.Instance.Name)
+		assert.True(t, output.Instance.Selected)
+
+		_, selected := mcpserver.ListInstances()
+		assert.Equal(t, "notes", selected)
+	})
+
+	t.Run("rejects unknown instances", func(t *testing.T) {
+		_, _, err := SelectInstanceHandler(t.Context(), nil, SelectInstanceInput{Name: "missing"})
+
+		assert.ErrorIs(t, err, mcpserver.ErrUnknownInstance)
+	})
+}
@@ -0,0 +1,34 @@
+package lsp
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// GotoDeclarationInput dto for goto declaration request
+type GotoDeclarationInput struct {
+	mcpserver.InstanceInput
+	PositionInput
+}
+
+// GotoDeclarationHandler handles goto declaration
+func GotoDeclarationHandler(ctx context.Context, req *mcp.CallToolRequest, input GotoDeclarationInput) (*mcp.CallToolResult, LocationsOutput, error) {
+	output, err := findLocations(ctx, input.Instance, types.LocationDeclaration, input.PositionInput, false)
+	if err != nil {
+		return nil, LocationsOutput{}, err
+	}
+
+	return nil, output, nil
+}
+
+// RegisterGotoDeclarationTool registers the goto declaration tool
+func RegisterGotoDeclarationTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "goto_declaration",
+		Description: "Ask the language servers where the symbol at a position, or a named workspace symbol, is declared. Returns the file, range and source line of each declaration and which servers answered.",
+	}, GotoDeclarationHandler)
+}
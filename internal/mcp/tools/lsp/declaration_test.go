@@ -0,0 +1,45 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestGotoDeclarationHandler(t *testing.T) {
+	t.Run("asks about a workspace symbol", func(t *testing.T) {
+		results := types.LocationResults{
+			Locations: []types.Location{{Path: "/src/vec.h", Range: types.TextRange{StartLine: 4, StartColumn: 6, EndLine: 4, EndColumn: 14}, Line: "void vec_push(struct vec *v, int x);", Client: "clangd"}},
+			Clients:   []types.LSPClientStatus{{Name: "clangd", Answered: true}},
+		}
+		client := nvimtest.NewMockClient()
+		client.SetupFindLocations(types.LocationDeclaration, "", types.LocationOptions{Symbol: "vec_push"}, results, nil)
+		mcpserver.NewServer(client)
+
+		_, output, err := GotoDeclarationHandler(t.Context(), nil, GotoDeclarationInput{
+			PositionInput: PositionInput{Symbol: "vec_push"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, results, output.LocationResults)
+		client.AssertExpectations(t)
+	})
+
+	t.Run("fails without a language server", func(t *testing.T) {
+		client := nvimtest.NewMockClient()
+		client.SetupFindLocations(types.LocationDeclaration, "vec.c",
+			types.LocationOptions{Line: 20, Column: 3}, types.LocationResults{}, nvim.ErrNoLanguageServer)
+		mcpserver.NewServer(client)
+
+		_, _, err := GotoDeclarationHandler(t.Context(), nil, GotoDeclarationInput{
+			PositionInput: PositionInput{BufferTitle: "vec.c", Line: 20, Column: 3},
+		})
+		assert.ErrorIs(t, err, nvim.ErrNoLanguageServer)
+	})
+}
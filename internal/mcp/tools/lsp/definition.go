@@ -0,0 +1,34 @@
+package lsp
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// GotoDefinitionInput dto for goto definition request
+type GotoDefinitionInput struct {
+	mcpserver.InstanceInput
+	PositionInput
+}
+
+// GotoDefinitionHandler handles goto definition
+func GotoDefinitionHandler(ctx context.Context, req *mcp.CallToolRequest, input GotoDefinitionInput) (*mcp.CallToolResult, LocationsOutput, error) {
+	output, err := findLocations(ctx, input.Instance, types.LocationDefinition, input.PositionInput, false)
+	if err != nil {
+		return nil, LocationsOutput{}, err
+	}
+
+	return nil, output, nil
+}
+
+// RegisterGotoDefinitionTool registers the goto definition tool
+func RegisterGotoDefinitionTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "goto_definition",
+		Description: "Ask the language servers where the symbol at a position, or a named workspace symbol, is defined. Returns the file, range and source line of each definition and which servers answered.",
+	}, GotoDefinitionHandler)
+}
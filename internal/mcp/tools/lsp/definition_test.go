@@ -0,0 +1,47 @@
+package lsp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestGotoDefinitionHandler(t *testing.T) {
+	t.Run("returns the definitions at a position", func(t *testing.T) {
+		results := types.LocationResults{
+			Locations: []types.Location{{Path: "/src/server.go", Range: types.TextRange{StartLine: 12, StartColumn: 6, EndLine: 12, EndColumn: 15}, Line: "func NewServer() *Server {", Client: "gopls"}},
+			Clients:   []types.LSPClientStatus{{Name: "gopls", Answered: true}},
+		}
+		client := nvimtest.NewMockClient()
+		client.SetupFindLocations(types.LocationDefinition, "main.go",
+			types.LocationOptions{Line: 8, Column: 14, Timeout: time.Second}, results, nil)
+		mcpserver.NewServer(client)
+
+		_, output, err := GotoDefinitionHandler(t.Context(), nil, GotoDefinitionInput{
+			PositionInput: PositionInput{BufferTitle: "main.go", Line: 8, Column: 14, TimeoutMs: 1000},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, results, output.LocationResults)
+		client.AssertExpectations(t)
+	})
+
+	t.Run("fails without a language server", func(t *testing.T) {
+		client := nvimtest.NewMockClient()
+		client.SetupFindLocations(types.LocationDefinition, "notes.txt",
+			types.LocationOptions{Line: 1, Column: 1}, types.LocationResults{}, nvim.ErrNoLanguageServer)
+		mcpserver.NewServer(client)
+
+		_, _, err := GotoDefinitionHandler(t.Context(), nil, GotoDefinitionInput{
+			PositionInput: PositionInput{BufferTitle: "notes.txt", Line: 1, Column: 1},
+		})
+		assert.ErrorIs(t, err, nvim.ErrNoLanguageServer)
+	})
+}
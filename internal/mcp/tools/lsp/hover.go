@@ -0,0 +1,44 @@
+package lsp
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// HoverInput dto for hover request
+type HoverInput struct {
+	mcpserver.InstanceInput
+	PositionInput
+}
+
+// HoverOutput dto for hover response
+type HoverOutput struct {
+	types.HoverResult
+}
+
+// HoverHandler handles hover
+func HoverHandler(ctx context.Context, req *mcp.CallToolRequest, input HoverInput) (*mcp.CallToolResult, HoverOutput, error) {
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, HoverOutput{}, err
+	}
+
+	result, err := nvimClient.Hover(ctx, input.BufferTitle, input.options())
+	if err != nil {
+		return nil, HoverOutput{}, err
+	}
+
+	return nil, HoverOutput{HoverResult: result}, nil
+}
+
+// RegisterHoverTool registers the hover tool
+func RegisterHoverTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "hover",
+		Description: "Ask the language servers for the hover information of the symbol at a position, or a named workspace symbol: its type, signature and documentation as markdown, like K shows in a floating window. Returns the contents of each server and which servers answered.",
+	}, HoverHandler)
+}
@@ -0,0 +1,31 @@
+package lsp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestHoverHandler(t *testing.T) {
+	result := types.HoverResult{
+		Hovers:  []types.Hover{{Client: "gopls", Contents: "```go\nfunc Println(a ...any) (n int, err error)\n```"}},
+		Clients: []types.LSPClientStatus{{Name: "gopls", Answered: true}},
+	}
+	client := nvimtest.NewMockClient()
+	client.SetupHover("main.go", types.LocationOptions{Line: 4, Column: 7, Encoding: "utf-16", Timeout: 500 * time.Millisecond}, result, nil)
+	mcpserver.NewServer(client)
+
+	_, output, err := HoverHandler(t.Context(), nil, HoverInput{
+		PositionInput: PositionInput{BufferTitle: "main.go", Line: 4, Column: 7, Encoding: "utf-16", TimeoutMs: 500},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, result, output.HoverResult)
+	client.AssertExpectations(t)
+}
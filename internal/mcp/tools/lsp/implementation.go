@@ -0,0 +1,34 @@
+package lsp
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// FindImplementationsInput dto for find implementations request
+type FindImplementationsInput struct {
+	mcpserver.InstanceInput
+	PositionInput
+}
+
+// FindImplementationsHandler handles find implementations
+func FindImplementationsHandler(ctx context.Context, req *mcp.CallToolRequest, input FindImplementationsInput) (*mcp.CallToolResult, LocationsOutput, error) {
+	output, err := findLocations(ctx, input.Instance, types.LocationImplementation, input.PositionInput, false)
+	if err != nil {
+		return nil, LocationsOutput{}, err
+	}
+
+	return nil, output, nil
+}
+
+// RegisterFindImplementationsTool registers the find implementations tool
+func RegisterFindImplementationsTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "find_implementations",
+		Description: "Ask the language servers for the implementations of the interface or method at a position, or of a named workspace symbol. Returns the file, range and source line of each implementation and which servers answered.",
+	}, FindImplementationsHandler)
+}
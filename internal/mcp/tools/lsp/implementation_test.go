@@ -0,0 +1,52 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestFindImplementationsHandler(t *testing.T) {
+	t.Run("returns the implementations of every server", func(t *testing.T) {
+		results := types.LocationResults{
+			Locations: []types.Location{
+				{Path: "/src/file.go", Range: types.TextRange{StartLine: 30, StartColumn: 16, EndLine: 30, EndColumn: 20}, Line: "func (f *File) Read(p []byte) (int, error) {", Client: "gopls"},
+				{Path: "/src/pipe.go", Range: types.TextRange{StartLine: 9, StartColumn: 16, EndLine: 9, EndColumn: 20}, Line: "func (r *pipe) Read(p []byte) (int, error) {", Client: "gopls"},
+			},
+			Clients: []types.LSPClientStatus{
+				{Name: "gopls", Answered: true},
+				{Name: "golangci_lint_ls", Error: "method not supported"},
+			},
+		}
+		client := nvimtest.NewMockClient()
+		client.SetupFindLocations(types.LocationImplementation, "io.go",
+			types.LocationOptions{Line: 3, Column: 2, Encoding: "utf-16"}, results, nil)
+		mcpserver.NewServer(client)
+
+		_, output, err := FindImplementationsHandler(t.Context(), nil, FindImplementationsInput{
+			PositionInput: PositionInput{BufferTitle: "io.go", Line: 3, Column: 2, Encoding: "utf-16"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, results, output.LocationResults)
+		client.AssertExpectations(t)
+	})
+
+	t.Run("fails without a language server", func(t *testing.T) {
+		client := nvimtest.NewMockClient()
+		client.SetupFindLocations(types.LocationImplementation, "io.go",
+			types.LocationOptions{Line: 3}, types.LocationResults{}, nvim.ErrNoLanguageServer)
+		mcpserver.NewServer(client)
+
+		_, _, err := FindImplementationsHandler(t.Context(), nil, FindImplementationsInput{
+			PositionInput: PositionInput{BufferTitle: "io.go", Line: 3},
+		})
+		assert.ErrorIs(t, err, nvim.ErrNoLanguageServer)
+	})
+}
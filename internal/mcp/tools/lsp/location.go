@@ -0,0 +1,54 @@
+// Package lsp implements neovim language server mcp tools
+package lsp
+
+import (
+	"context"
+	"time"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// PositionInput selects what an LSP request asks the language servers about
+type PositionInput struct {
+	BufferTitle string `json:"buffer_title,omitempty" jsonschema:"buffer handle, absolute or cwd-relative path, file:// URI, or unique filename; defaults to the current buffer"`
+	Line        int    `json:"line,omitempty" jsonschema:"line of the symbol (1-based), defaults to the cursor line"`
+	Column      int    `json:"column,omitempty" jsonschema:"column of the symbol (1-based), defaults to the line start or the cursor column"`
+	Encoding    string `json:"encoding,omitempty" jsonschema:"unit of the column: byte (default), codepoint or utf-16"`
+	Symbol      string `json:"symbol,omitempty" jsonschema:"name of a workspace symbol to ask about instead of a position"`
+	TimeoutMs   int    `json:"timeout_ms,omitempty" jsonschema:"how long to wait for the language servers in milliseconds, defaults to 5000"`
+}
+
+// LocationsOutput dto for location requests responses
+type LocationsOutput struct {
+	types.LocationResults
+}
+
+// findLocations asks the language servers of an instance for the locations of a kind
+func findLocations(ctx context.Context, instance, kind string, input PositionInput, includeDeclaration bool) (LocationsOutput, error) {
+	nvimClient, err := mcpserver.GetInstanceClient(instance)
+	if err != nil {
+		return LocationsOutput{}, err
+	}
+
+	opts := input.options()
+	opts.IncludeDeclaration = includeDeclaration
+
+	results, err := nvimClient.FindLocations(ctx, kind, input.BufferTitle, opts)
+	if err != nil {
+		return LocationsOutput{}, err
+	}
+
+	return LocationsOutput{LocationResults: results}, nil
+}
+
+// options returns the location options selecting the position of input
+func (input PositionInput) options() types.LocationOptions {
+	return types.LocationOptions{
+		Line:     input.Line,
+		Column:   input.Column,
+		Encoding: input.Encoding,
+		Symbol:   input.Symbol,
+		Timeout:  time.Duration(input.TimeoutMs) * time.Millisecond,
+	}
+}
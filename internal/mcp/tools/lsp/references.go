@@ -0,0 +1,36 @@
+package lsp
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// FindReferencesInput dto for find references request
+type FindReferencesInput struct {
+	mcpserver.InstanceInput
+	PositionInput
+
+	IncludeDeclaration bool `json:"include_declaration,omitempty" jsonschema:"also return the declaration of the symbol"`
+}
+
+// FindReferencesHandler handles find references
+func FindReferencesHandler(ctx context.Context, req *mcp.CallToolRequest, input FindReferencesInput) (*mcp.CallToolResult, LocationsOutput, error) {
+	output, err := findLocations(ctx, input.Instance, types.LocationReferences, input.PositionInput, input.IncludeDeclaration)
+	if err != nil {
+		return nil, LocationsOutput{}, err
+	}
+
+	return nil, output, nil
+}
+
+// RegisterFindReferencesTool registers the find references tool
+func RegisterFindReferencesTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "find_references",
+		Description: "Ask the language servers where the symbol at a position, or a named workspace symbol, is used. Returns the file, range and source line of each reference and which servers answered.",
+	}, FindReferencesHandler)
+}
@@ -0,0 +1,33 @@
+package lsp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestFindReferencesHandler(t *testing.T) {
+	results := types.LocationResults{
+		Locations: []types.Location{{Path: "/src/main.go", Range: types.TextRange{StartLine: 3, StartColumn: 2, EndLine: 3, EndColumn: 6}, Line: "\tinit()", Client: "gopls"}},
+		Clients:   []types.LSPClientStatus{{Name: "gopls", Answered: true}},
+	}
+	client := nvimtest.NewMockClient()
+	client.SetupFindLocations(types.LocationReferences, "main.go",
+		types.LocationOptions{Line: 10, Column: 6, IncludeDeclaration: true, Timeout: 250 * time.Millisecond}, results, nil)
+	mcpserver.NewServer(client)
+
+	_, output, err := FindReferencesHandler(t.Context(), nil, FindReferencesInput{
+		PositionInput:      PositionInput{BufferTitle: "main.go", Line: 10, Column: 6, TimeoutMs: 250},
+		IncludeDeclaration: true,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, results, output.LocationResults)
+	client.AssertExpectations(t)
+}
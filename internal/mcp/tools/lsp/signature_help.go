@@ -0,0 +1,44 @@
+package lsp
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// SignatureHelpInput dto for signature help request
+type SignatureHelpInput struct {
+	mcpserver.InstanceInput
+	PositionInput
+}
+
+// SignatureHelpOutput dto for signature help response
+type SignatureHelpOutput struct {
+	types.SignatureHelpResult
+}
+
+// SignatureHelpHandler handles signature help
+func SignatureHelpHandler(ctx context.Context, req *mcp.CallToolRequest, input SignatureHelpInput) (*mcp.CallToolResult, SignatureHelpOutput, error) {
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, SignatureHelpOutput{}, err
+	}
+
+	result, err := nvimClient.SignatureHelp(ctx, input.BufferTitle, input.options())
+	if err != nil {
+		return nil, SignatureHelpOutput{}, err
+	}
+
+	return nil, SignatureHelpOutput{SignatureHelpResult: result}, nil
+}
+
+// RegisterSignatureHelpTool registers the signature help tool
+func RegisterSignatureHelpTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "signature_help",
+		Description: "Ask the language servers for the signatures of the call at a position, typically inside its argument list. Returns each signature with its parameters and documentation as markdown, the active signature and parameter, and which servers answered.",
+	}, SignatureHelpHandler)
+}
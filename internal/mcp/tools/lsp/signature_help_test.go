@@ -0,0 +1,51 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestSignatureHelpHandler(t *testing.T) {
+	t.Run("returns the signatures of the call", func(t *testing.T) {
+		result := types.SignatureHelpResult{
+			Help: []types.SignatureHelp{{
+				Client: "gopls",
+				Signatures: []types.Signature{{
+					Label:      "Printf(format string, a ...any) (n int, err error)",
+					Parameters: []types.SignatureParameter{{Label: "format string"}, {Label: "a ...any"}},
+				}},
+				ActiveParameter: 1,
+				Signature:       "Printf(format string, a ...any) (n int, err error)",
+				Parameter:       "a ...any",
+			}},
+			Clients: []types.LSPClientStatus{{Name: "gopls", Answered: true}},
+		}
+		client := nvimtest.NewMockClient()
+		client.SetupSignatureHelp("main.go", types.LocationOptions{Line: 6, Column: 23, Encoding: "codepoint"}, result, nil)
+		mcpserver.NewServer(client)
+
+		_, output, err := SignatureHelpHandler(t.Context(), nil, SignatureHelpInput{
+			PositionInput: PositionInput{BufferTitle: "main.go", Line: 6, Column: 23, Encoding: "codepoint"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, result, output.SignatureHelpResult)
+		client.AssertExpectations(t)
+	})
+
+	t.Run("fails without a language server", func(t *testing.T) {
+		client := nvimtest.NewMockClient()
+		client.SetupSignatureHelp("", types.LocationOptions{}, types.SignatureHelpResult{}, nvim.ErrNoLanguageServer)
+		mcpserver.NewServer(client)
+
+		_, _, err := SignatureHelpHandler(t.Context(), nil, SignatureHelpInput{})
+		assert.ErrorIs(t, err, nvim.ErrNoLanguageServer)
+	})
+}
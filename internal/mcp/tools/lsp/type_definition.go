@@ -0,0 +1,34 @@
+package lsp
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// GotoTypeDefinitionInput dto for goto type definition request
+type GotoTypeDefinitionInput struct {
+	mcpserver.InstanceInput
+	PositionInput
+}
+
+// GotoTypeDefinitionHandler handles goto type definition
+func GotoTypeDefinitionHandler(ctx context.Context, req *mcp.CallToolRequest, input GotoTypeDefinitionInput) (*mcp.CallToolResult, LocationsOutput, error) {
+	output, err := findLocations(ctx, input.Instance, types.LocationTypeDefinition, input.PositionInput, false)
+	if err != nil {
+		return nil, LocationsOutput{}, err
+	}
+
+	return nil, output, nil
+}
+
+// RegisterGotoTypeDefinitionTool registers the goto type definition tool
+func RegisterGotoTypeDefinitionTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "goto_type_definition",
+		Description: "Ask the language servers where the type of the symbol at a position, or of a named workspace symbol, is defined. Returns the file, range and source line of each location and which servers answered.",
+	}, GotoTypeDefinitionHandler)
+}
@@ -0,0 +1,40 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestGotoTypeDefinitionHandler(t *testing.T) {
+	t.Run("asks about the cursor by default", func(t *testing.T) {
+		results := types.LocationResults{
+			Locations: []types.Location{{Path: "/src/config.ts", Range: types.TextRange{StartLine: 1, StartColumn: 18, EndLine: 1, EndColumn: 24}, Line: "export interface Config {", Client: "ts_ls"}},
+			Clients:   []types.LSPClientStatus{{Name: "ts_ls", Answered: true}},
+		}
+		client := nvimtest.NewMockClient()
+		client.SetupFindLocations(types.LocationTypeDefinition, "", types.LocationOptions{}, results, nil)
+		mcpserver.NewServer(client)
+
+		_, output, err := GotoTypeDefinitionHandler(t.Context(), nil, GotoTypeDefinitionInput{})
+		require.NoError(t, err)
+
+		assert.Equal(t, results, output.LocationResults)
+		client.AssertExpectations(t)
+	})
+
+	t.Run("fails without a language server", func(t *testing.T) {
+		client := nvimtest.NewMockClient()
+		client.SetupFindLocations(types.LocationTypeDefinition, "", types.LocationOptions{}, types.LocationResults{}, nvim.ErrNoLanguageServer)
+		mcpserver.NewServer(client)
+
+		_, _, err := GotoTypeDefinitionHandler(t.Context(), nil, GotoTypeDefinitionInput{})
+		assert.ErrorIs(t, err, nvim.ErrNoLanguageServer)
+	})
+}
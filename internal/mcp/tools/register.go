@@ -4,15 +4,22 @@ package tools
 import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/cousine/neovim-mcp/internal/mcp/tools/anchor"
 	"github.com/cousine/neovim-mcp/internal/mcp/tools/buffer"
 	"github.com/cousine/neovim-mcp/internal/mcp/tools/command"
 	"github.com/cousine/neovim-mcp/internal/mcp/tools/cursor"
+	"github.com/cousine/neovim-mcp/internal/mcp/tools/instance"
+	"github.com/cousine/neovim-mcp/internal/mcp/tools/lsp"
 	"github.com/cousine/neovim-mcp/internal/mcp/tools/text"
 	"github.com/cousine/neovim-mcp/internal/mcp/tools/window"
 )
 
 // RegisterAllTools registers all MCP tools with the server
 func RegisterAllTools(server *mcp.Server) {
+	// Instance tools (2)
+	instance.RegisterListInstancesTool(server)
+	instance.RegisterSelectInstanceTool(server)
+
 	// Buffer tools (5)
 	buffer.RegisterGetBuffersTool(server)
 	buffer.RegisterGetCurrentBufferTool(server)
@@ -20,17 +27,37 @@ func RegisterAllTools(server *mcp.Server) {
 	buffer.RegisterCloseBufferTool(server)
 	buffer.RegisterSwitchBufferTool(server)
 
-	// Text tools (4)
+	// Text tools (9)
 	text.RegisterGetBufferLinesTool(server)
 	text.RegisterSetBufferLinesTool(server)
 	text.RegisterInsertTextTool(server)
 	text.RegisterDeleteLinesTool(server)
+	text.RegisterReplaceTextTool(server)
+	text.RegisterGetTextTool(server)
+	text.RegisterSetTextTool(server)
+	text.RegisterApplyPatchTool(server)
+	text.RegisterApplyEditsTool(server)
+
+	// Anchor tools (2)
+	anchor.RegisterCreateAnchorTool(server)
+	anchor.RegisterResolveAnchorTool(server)
 
-	// Cursor tools (4)
+	// Cursor tools (6)
 	cursor.RegisterGetCursorPositionTool(server)
 	cursor.RegisterSetCursorPositionTool(server)
 	cursor.RegisterGotoLineTool(server)
 	cursor.RegisterSearchTool(server)
+	cursor.RegisterGrepProjectTool(server)
+	cursor.RegisterFindReplaceTool(server)
+
+	// LSP tools (7)
+	lsp.RegisterGotoDefinitionTool(server)
+	lsp.RegisterFindReferencesTool(server)
+	lsp.RegisterFindImplementationsTool(server)
+	lsp.RegisterGotoTypeDefinitionTool(server)
+	lsp.RegisterGotoDeclarationTool(server)
+	lsp.RegisterHoverTool(server)
+	lsp.RegisterSignatureHelpTool(server)
 
 	// Window tools (4)
 	window.RegisterGetWindowsTool(server)
@@ -38,8 +65,10 @@ func RegisterAllTools(server *mcp.Server) {
 	window.RegisterCloseWindowTool(server)
 	window.RegisterResizeWindowTool(server)
 
-	// Command tools (3)
+	// Command tools (5)
 	command.RegisterExecCommandTool(server)
 	command.RegisterExecLuaTool(server)
 	command.RegisterCallFunctionTool(server)
+	command.RegisterSendInputTool(server)
+	command.RegisterFeedKeysTool(server)
 }
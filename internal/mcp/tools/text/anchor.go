@@ -0,0 +1,37 @@
+package text
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// AnchorInput lets a tool target an anchor instead of a buffer and positions
+type AnchorInput struct {
+	Anchor string `json:"anchor,omitempty" jsonschema:"name of an anchor from create_anchor, targets its buffer and current range instead of buffer_title and positions"`
+}
+
+// RangeInput is a text range that may be left out when an anchor is given
+type RangeInput struct {
+	StartLine   int `json:"start_line,omitempty" jsonschema:"starting line number (1-based, inclusive)"`
+	StartColumn int `json:"start_column,omitempty" jsonschema:"starting column (1-based, inclusive)"`
+	EndLine     int `json:"end_line,omitempty" jsonschema:"ending line number (1-based, inclusive)"`
+	EndColumn   int `json:"end_column,omitempty" jsonschema:"ending column (1-based, exclusive)"`
+}
+
+// textRange returns the range as a types.TextRange
+func (r RangeInput) textRange() types.TextRange {
+	return types.TextRange(r)
+}
+
+// resolveAnchor returns the buffer handle of an anchor as a buffer title, along with
+// its current range and lines
+func resolveAnchor(ctx context.Context, client types.NeovimClient, name string) (string, types.Anchor, error) {
+	anchor, err := client.ResolveAnchor(ctx, name)
+	if err != nil {
+		return "", types.Anchor{}, err
+	}
+
+	return strconv.Itoa(int(anchor.Buffer)), anchor, nil
+}
@@ -0,0 +1,51 @@
+package text
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestAnchorTargets(t *testing.T) {
+	anchor := types.Anchor{
+		Name:    "handler",
+		Buffer:  7,
+		Range:   types.TextRange{StartLine: 10, StartColumn: 1, EndLine: 12, EndColumn: 2},
+		Lines:   []string{"func handler() {", "\treturn", "}"},
+		Version: 21,
+	}
+
+	t.Run("reads the anchored lines", func(t *testing.T) {
+		client := nvimtest.NewMockClient()
+		client.SetupResolveAnchor("handler", anchor, nil)
+		mcpserver.NewServer(client)
+
+		_, output, err := GetBufferLinesHandler(t.Context(), nil, GetBufferLinesInput{AnchorInput: AnchorInput{Anchor: "handler"}})
+		require.NoError(t, err)
+
+		assert.Equal(t, anchor.Lines, output.Lines)
+		assert.Equal(t, 21, output.Version)
+		client.AssertExpectations(t)
+	})
+
+	t.Run("writes the anchored range", func(t *testing.T) {
+		client := nvimtest.NewMockClient()
+		client.SetupResolveAnchor("handler", anchor, nil)
+		client.SetupSetText("7", anchor.Range, "func handler() {}", "", 0, types.TextRange{StartLine: 10, StartColumn: 1, EndLine: 10, EndColumn: 18}, 22, nil)
+		mcpserver.NewServer(client)
+
+		_, _, err := SetTextHandler(t.Context(), nil, SetTextInput{
+			AnchorInput: AnchorInput{Anchor: "handler"},
+			Text:        "func handler() {}",
+			Encoding:    "utf-16",
+		})
+		require.NoError(t, err)
+
+		client.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,46 @@
+package text
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// ApplyEditsInput dto for apply edits request
+type ApplyEditsInput struct {
+	mcpserver.InstanceInput
+
+	Edits []types.BufferEdit `json:"edits" jsonschema:"edits applied in order; positions refer to the buffer after the previous edits"`
+}
+
+// ApplyEditsOutput dto for apply edits response
+type ApplyEditsOutput struct {
+	types.ApplyEditsResult
+}
+
+// ApplyEditsHandler handles apply edits
+func ApplyEditsHandler(ctx context.Context, req *mcp.CallToolRequest, input ApplyEditsInput) (*mcp.CallToolResult, ApplyEditsOutput, error) {
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, ApplyEditsOutput{}, err
+	}
+
+	result, err := nvimClient.ApplyEdits(ctx, input.Edits)
+	if err != nil {
+		return nil, ApplyEditsOutput{}, err
+	}
+
+	return nil, ApplyEditsOutput{ApplyEditsResult: result}, nil
+}
+
+// RegisterApplyEditsTool registers the apply edits tool
+func RegisterApplyEditsTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "apply_edits",
+		Description: "Apply an ordered list of line range, character range or exact text edits across several buffers as one transaction: " +
+			"every edit is validated first and either all of them apply or none do. Each buffer's edits become one undo step",
+	}, ApplyEditsHandler)
+}
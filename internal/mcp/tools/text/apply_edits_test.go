@@ -0,0 +1,32 @@
+package text
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestApplyEditsHandler(t *testing.T) {
+	edits := []types.BufferEdit{
+		{BufferTitle: "a.go", Kind: types.EditKindReplace, OldText: "Foo", NewText: "Bar"},
+		{BufferTitle: "b.go", Kind: types.EditKindLines, StartLine: 3, EndLine: 4, Lines: []string{"x"}},
+	}
+	result := types.ApplyEditsResult{
+		Applied: 2,
+		Buffers: []types.EditedBuffer{{Buffer: 1, Name: "/src/a.go", Edits: 1}, {Buffer: 2, Name: "/src/b.go", Edits: 1}},
+	}
+	client := nvimtest.NewMockClient()
+	client.SetupApplyEdits(edits, result, nil)
+	mcpserver.NewServer(client)
+
+	_, output, err := ApplyEditsHandler(t.Context(), nil, ApplyEditsInput{Edits: edits})
+	require.NoError(t, err)
+
+	assert.Equal(t, result, output.ApplyEditsResult)
+	client.AssertExpectations(t)
+}
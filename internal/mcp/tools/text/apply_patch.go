@@ -0,0 +1,48 @@
+package text
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// ApplyPatchInput dto for apply patch request
+type ApplyPatchInput struct {
+	mcpserver.InstanceInput
+
+	Patch            string         `json:"patch" jsonschema:"unified diff with --- / +++ file headers and @@ hunks, may span several files"`
+	ExpectedVersions map[string]int `json:"expected_versions,omitempty" jsonschema:"buffer version returned by a read tool for file paths of the patch, the patch fails with a version conflict before any file is patched if one of these buffers changed since"`
+}
+
+// ApplyPatchOutput dto for apply patch response
+type ApplyPatchOutput struct {
+	types.PatchResult
+}
+
+// ApplyPatchHandler handles apply patch
+func ApplyPatchHandler(ctx context.Context, req *mcp.CallToolRequest, input ApplyPatchInput) (*mcp.CallToolResult, ApplyPatchOutput, error) {
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, ApplyPatchOutput{}, err
+	}
+
+	result, err := nvimClient.ApplyPatch(ctx, input.Patch, input.ExpectedVersions)
+	if err != nil {
+		return nil, ApplyPatchOutput{}, err
+	}
+
+	return nil, ApplyPatchOutput{PatchResult: result}, nil
+}
+
+// RegisterApplyPatchTool registers the apply patch tool
+func RegisterApplyPatchTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "apply_patch",
+		Description: "Apply a unified diff to the buffers of its files, loading files that are not open. " +
+			"Hunks tolerate shifted lines, whitespace changes and stale context; rejected hunks are reported with a reason. " +
+			"Each file becomes one undo step and is not written to disk",
+	}, ApplyPatchHandler)
+}
@@ -0,0 +1,37 @@
+package text
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestApplyPatchHandler(t *testing.T) {
+	patch := "--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-old\n+new\n"
+	result := types.PatchResult{
+		Applied:  1,
+		Rejected: 1,
+		Files: []types.FilePatchResult{{
+			Path:   "main.go",
+			Buffer: 3,
+			Hunks: []types.HunkResult{
+				{Hunk: 1, Header: "@@ -1 +1 @@", Applied: true, Changed: &types.TextRange{StartLine: 1, StartColumn: 1, EndLine: 1, EndColumn: 5}},
+				{Hunk: 2, Header: "@@ -9 +9 @@", Reason: "context not found"},
+			},
+		}},
+	}
+	client := nvimtest.NewMockClient()
+	client.SetupApplyPatch(patch, map[string]int{"main.go": 12}, result, nil)
+	mcpserver.NewServer(client)
+
+	_, output, err := ApplyPatchHandler(t.Context(), nil, ApplyPatchInput{Patch: patch, ExpectedVersions: map[string]int{"main.go": 12}})
+	require.NoError(t, err)
+
+	assert.Equal(t, result, output.PatchResult)
+	client.AssertExpectations(t)
+}
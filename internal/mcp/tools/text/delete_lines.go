@@ -7,31 +7,52 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
 )
 
 // DeleteLinesInput dto for delete lines request
 type DeleteLinesInput struct {
-	BufferTitle string `json:"buffer_title" jsonschema:"buffer title or filename"`
-	StartLine   int    `json:"start_line" jsonschema:"starting line number (1-based)"`
-	EndLine     int    `json:"end_line" jsonschema:"ending line number (1-based)"`
+	mcpserver.InstanceInput
+	AnchorInput
+
+	BufferTitle string `json:"buffer_title,omitempty" jsonschema:"buffer handle, absolute or cwd-relative path, file:// URI, or unique filename; not needed with an anchor"`
+	StartLine   int    `json:"start_line,omitempty" jsonschema:"starting line number (1-based)"`
+	EndLine     int    `json:"end_line,omitempty" jsonschema:"ending line number (1-based)"`
+
+	ExpectedVersion int `json:"expected_version,omitempty" jsonschema:"buffer version returned by a read tool, the write fails with a version conflict if the buffer changed since"`
 }
 
 // DeleteLinesOutput dto for delete lines response
 type DeleteLinesOutput struct {
 	Success bool `json:"success" jsonschema:"whether lines were deleted successfully"`
+	Version int  `json:"version" jsonschema:"buffer version after the write"`
 }
 
 // DeleteLinesHandler handles delete lines
 func DeleteLinesHandler(ctx context.Context, req *mcp.CallToolRequest, input DeleteLinesInput) (*mcp.CallToolResult, DeleteLinesOutput, error) {
-	nvimClient := mcpserver.GetNvimClient()
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, DeleteLinesOutput{}, err
+	}
+
+	title, startLine, endLine := input.BufferTitle, input.StartLine, input.EndLine
+	if input.Anchor != "" {
+		var anchor types.Anchor
+		if title, anchor, err = resolveAnchor(ctx, nvimClient, input.Anchor); err != nil {
+			return nil, DeleteLinesOutput{}, err
+		}
+
+		startLine, endLine = anchor.Range.StartLine, anchor.Range.EndLine
+	}
 
-	err := nvimClient.DeleteLines(ctx, input.BufferTitle, input.StartLine, input.EndLine)
+	version, err := nvimClient.DeleteLines(ctx, title, startLine, endLine, input.ExpectedVersion)
 	if err != nil {
 		return nil, DeleteLinesOutput{}, err
 	}
 
 	return nil, DeleteLinesOutput{
 		Success: true,
+		Version: version,
 	}, nil
 }
 
@@ -39,6 +60,6 @@ func DeleteLinesHandler(ctx context.Context, req *mcp.CallToolRequest, input Del
 func RegisterDeleteLinesTool(server *mcp.Server) {
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "delete_lines",
-		Description: "Delete a range of lines from a buffer",
+		Description: "Delete a range of lines from a buffer, or the lines spanned by an anchor",
 	}, DeleteLinesHandler)
 }
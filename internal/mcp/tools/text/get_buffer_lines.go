@@ -10,27 +10,47 @@ import (
 
 // GetBufferLinesInput dto for get buffer lines request
 type GetBufferLinesInput struct {
-	BufferTitle string `json:"buffer_title" jsonschema:"buffer title or filename"`
-	StartLine   int    `json:"start_line" jsonschema:"starting line number (1-based, inclusive)"`
-	EndLine     int    `json:"end_line" jsonschema:"ending line number (1-based, inclusive, -1 for end of file)"`
+	mcpserver.InstanceInput
+	AnchorInput
+
+	BufferTitle string `json:"buffer_title,omitempty" jsonschema:"buffer handle, absolute or cwd-relative path, file:// URI, or unique filename; not needed with an anchor"`
+	StartLine   int    `json:"start_line,omitempty" jsonschema:"starting line number (1-based, inclusive)"`
+	EndLine     int    `json:"end_line,omitempty" jsonschema:"ending line number (1-based, inclusive, -1 for end of file)"`
 }
 
 // GetBufferLinesOutput dto for get buffer lines response
 type GetBufferLinesOutput struct {
-	Lines []string `json:"lines" jsonschema:"array of line contents"`
+	Lines   []string `json:"lines" jsonschema:"array of line contents"`
+	Version int      `json:"version" jsonschema:"buffer version the lines were read at, pass it as expected_version to writes"`
 }
 
 // GetBufferLinesHandler handles get buffer lines
 func GetBufferLinesHandler(ctx context.Context, req *mcp.CallToolRequest, input GetBufferLinesInput) (*mcp.CallToolResult, GetBufferLinesOutput, error) {
-	nvimClient := mcpserver.GetNvimClient()
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, GetBufferLinesOutput{}, err
+	}
+
+	if input.Anchor != "" {
+		_, anchor, aerr := resolveAnchor(ctx, nvimClient, input.Anchor)
+		if aerr != nil {
+			return nil, GetBufferLinesOutput{}, aerr
+		}
+
+		return nil, GetBufferLinesOutput{
+			Lines:   anchor.Lines,
+			Version: anchor.Version,
+		}, nil
+	}
 
-	lines, err := nvimClient.GetBufferLines(ctx, input.BufferTitle, input.StartLine, input.EndLine)
+	lines, version, err := nvimClient.GetBufferLines(ctx, input.BufferTitle, input.StartLine, input.EndLine)
 	if err != nil {
 		return nil, GetBufferLinesOutput{}, err
 	}
 
 	return nil, GetBufferLinesOutput{
-		Lines: lines,
+		Lines:   lines,
+		Version: version,
 	}, nil
 }
 
@@ -38,6 +58,6 @@ func GetBufferLinesHandler(ctx context.Context, req *mcp.CallToolRequest, input
 func RegisterGetBufferLinesTool(server *mcp.Server) {
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "get_buffer_lines",
-		Description: "Read lines from a buffer with 1-based line indexing",
+		Description: "Read lines from a buffer with 1-based line indexing, or the lines spanned by an anchor",
 	}, GetBufferLinesHandler)
 }
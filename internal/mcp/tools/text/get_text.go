@@ -0,0 +1,62 @@
+package text
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// GetTextInput dto for get text request
+type GetTextInput struct {
+	mcpserver.InstanceInput
+	AnchorInput
+	RangeInput
+
+	BufferTitle string `json:"buffer_title,omitempty" jsonschema:"buffer handle, absolute or cwd-relative path, file:// URI, or unique filename; not needed with an anchor"`
+	Encoding    string `json:"encoding,omitempty" jsonschema:"how columns are counted: byte (default), codepoint or utf-16 (LSP positions); anchors count bytes"`
+}
+
+// GetTextOutput dto for get text response
+type GetTextOutput struct {
+	Text    string `json:"text" jsonschema:"text between the start and end positions, lines joined with newlines"`
+	Version int    `json:"version" jsonschema:"buffer version the text was read at, pass it as expected_version to writes"`
+}
+
+// GetTextHandler handles get text
+func GetTextHandler(ctx context.Context, req *mcp.CallToolRequest, input GetTextInput) (*mcp.CallToolResult, GetTextOutput, error) {
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, GetTextOutput{}, err
+	}
+
+	title, rng, encoding := input.BufferTitle, input.textRange(), input.Encoding
+	if input.Anchor != "" {
+		var anchor types.Anchor
+		if title, anchor, err = resolveAnchor(ctx, nvimClient, input.Anchor); err != nil {
+			return nil, GetTextOutput{}, err
+		}
+
+		rng, encoding = anchor.Range, ""
+	}
+
+	text, version, err := nvimClient.GetText(ctx, title, rng, encoding)
+	if err != nil {
+		return nil, GetTextOutput{}, err
+	}
+
+	return nil, GetTextOutput{
+		Text:    text,
+		Version: version,
+	}, nil
+}
+
+// RegisterGetTextTool registers the get text tool
+func RegisterGetTextTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_text",
+		Description: "Read the text of a buffer between two line and column positions (1-based, end column exclusive), or the text of an anchor",
+	}, GetTextHandler)
+}
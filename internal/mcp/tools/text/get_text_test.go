@@ -0,0 +1,30 @@
+package text
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestGetTextHandler(t *testing.T) {
+	rng := types.TextRange{StartLine: 1, StartColumn: 5, EndLine: 1, EndColumn: 10}
+	client := nvimtest.NewMockClient()
+	client.SetupGetText("main.go", rng, "utf-16", "naïve", 12, nil)
+	mcpserver.NewServer(client)
+
+	_, output, err := GetTextHandler(t.Context(), nil, GetTextInput{
+		RangeInput:  RangeInput(rng),
+		BufferTitle: "main.go",
+		Encoding:    "utf-16",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "naïve", output.Text)
+	assert.Equal(t, 12, output.Version)
+	client.AssertExpectations(t)
+}
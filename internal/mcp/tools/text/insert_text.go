@@ -6,36 +6,60 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
 )
 
 // InsertTextInput dto for insert text request
 type InsertTextInput struct {
-	Text string `json:"text" jsonschema:"text to insert at cursor position"`
+	mcpserver.InstanceInput
+
+	BufferTitle string `json:"buffer_title,omitempty" jsonschema:"buffer handle, absolute or cwd-relative path, file:// URI, or unique filename; defaults to the current buffer"`
+	Text        string `json:"text" jsonschema:"literal text to insert, never interpreted as keys or commands"`
+	Line        int    `json:"line,omitempty" jsonschema:"line to insert at (1-based), defaults to the cursor line"`
+	Column      int    `json:"column,omitempty" jsonschema:"column to insert at (1-based), defaults to the cursor column without a line and to the start of the line with one"`
+	Linewise    bool   `json:"linewise,omitempty" jsonschema:"insert the text as whole lines above or below the line instead of inside it"`
+	After       bool   `json:"after,omitempty" jsonschema:"insert after the character at the position, or below the line when linewise, instead of before it"`
+	Reindent    bool   `json:"reindent,omitempty" jsonschema:"re-indent the inserted lines by the buffer's indent rules, keeping their indentation relative to each other"`
+	Encoding    string `json:"encoding,omitempty" jsonschema:"how columns are counted: byte (default), codepoint or utf-16 (LSP positions)"`
+
+	ExpectedVersion int `json:"expected_version,omitempty" jsonschema:"buffer version returned by a read tool, the write fails with a version conflict if the buffer changed since"`
 }
 
 // InsertTextOutput dto for insert text response
 type InsertTextOutput struct {
-	Success bool `json:"success" jsonschema:"whether text was inserted successfully"`
+	types.InsertTextResult
 }
 
 // InsertTextHandler handles inserting text in neovim
 func InsertTextHandler(ctx context.Context, req *mcp.CallToolRequest, input InsertTextInput) (*mcp.CallToolResult, InsertTextOutput, error) {
-	nvimClient := mcpserver.GetNvimClient()
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, InsertTextOutput{}, err
+	}
 
-	err := nvimClient.InsertText(ctx, input.Text)
+	result, err := nvimClient.InsertText(ctx, input.BufferTitle, input.Text, types.InsertTextOptions{
+		Line:            input.Line,
+		Column:          input.Column,
+		Linewise:        input.Linewise,
+		After:           input.After,
+		Reindent:        input.Reindent,
+		Encoding:        input.Encoding,
+		ExpectedVersion: input.ExpectedVersion,
+	})
 	if err != nil {
 		return nil, InsertTextOutput{}, err
 	}
 
 	return nil, InsertTextOutput{
-		Success: true,
+		InsertTextResult: result,
 	}, nil
 }
 
 // RegisterInsertTextTool registers the insert text tool
 func RegisterInsertTextTool(server *mcp.Server) {
 	mcp.AddTool(server, &mcp.Tool{
-		Name:        "insert_text",
-		Description: "Insert text at the current cursor position",
+		Name: "insert_text",
+		Description: "Insert literal text into a buffer at a position or the cursor, whatever mode neovim is in. " +
+			"Use send_input to type keys instead.",
 	}, InsertTextHandler)
 }
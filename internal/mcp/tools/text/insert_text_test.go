@@ -1,7 +1,33 @@
 package text
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
 
 func TestInsertTextHandler(t *testing.T) {
-	t.Skip("Not implemented")
+	changed := types.TextRange{StartLine: 4, StartColumn: 1, EndLine: 5, EndColumn: 2}
+	client := nvimtest.NewMockClient()
+	client.SetupInsertText("main.go", "if err != nil {\n}", types.InsertTextOptions{Line: 3, Linewise: true, After: true},
+		types.InsertTextResult{Changed: changed, Version: 8}, nil)
+	mcpserver.NewServer(client)
+
+	_, output, err := InsertTextHandler(t.Context(), nil, InsertTextInput{
+		BufferTitle: "main.go",
+		Text:        "if err != nil {\n}",
+		Line:        3,
+		Linewise:    true,
+		After:       true,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, changed, output.Changed)
+	assert.Equal(t, 8, output.Version)
+	client.AssertExpectations(t)
 }
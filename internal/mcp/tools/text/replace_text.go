@@ -0,0 +1,55 @@
+package text
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// ReplaceTextInput dto for replace text request
+type ReplaceTextInput struct {
+	mcpserver.InstanceInput
+
+	BufferTitle string `json:"buffer_title" jsonschema:"buffer handle, absolute or cwd-relative path, file:// URI, or unique filename"`
+	OldText     string `json:"old_text" jsonschema:"exact text to replace, including whitespace and newlines; must occur exactly once unless occurrence or replace_all is given"`
+	NewText     string `json:"new_text" jsonschema:"text to insert in place of old_text, empty to delete it"`
+	Occurrence  int    `json:"occurrence,omitempty" jsonschema:"replace only this occurrence of old_text (1-based)"`
+	ReplaceAll  bool   `json:"replace_all,omitempty" jsonschema:"replace every occurrence of old_text"`
+
+	ExpectedVersion int `json:"expected_version,omitempty" jsonschema:"buffer version returned by a read tool, the write fails with a version conflict if the buffer changed since"`
+}
+
+// ReplaceTextOutput dto for replace text response
+type ReplaceTextOutput struct {
+	types.ReplaceTextResult
+}
+
+// ReplaceTextHandler handles replace text
+func ReplaceTextHandler(ctx context.Context, req *mcp.CallToolRequest, input ReplaceTextInput) (*mcp.CallToolResult, ReplaceTextOutput, error) {
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, ReplaceTextOutput{}, err
+	}
+
+	result, err := nvimClient.ReplaceText(ctx, input.BufferTitle, input.OldText, input.NewText, types.ReplaceTextOptions{
+		Occurrence:      input.Occurrence,
+		ReplaceAll:      input.ReplaceAll,
+		ExpectedVersion: input.ExpectedVersion,
+	})
+	if err != nil {
+		return nil, ReplaceTextOutput{}, err
+	}
+
+	return nil, ReplaceTextOutput{ReplaceTextResult: result}, nil
+}
+
+// RegisterReplaceTextTool registers the replace text tool
+func RegisterReplaceTextTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "replace_text",
+		Description: "Replace an exact, unique piece of text in a buffer and return the changed range; safer than line numbers, which drift as the buffer changes",
+	}, ReplaceTextHandler)
+}
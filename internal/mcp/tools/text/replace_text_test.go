@@ -0,0 +1,35 @@
+package text
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestReplaceTextHandler(t *testing.T) {
+	result := types.ReplaceTextResult{
+		Replacements: 1,
+		Changed:      types.TextRange{StartLine: 3, StartColumn: 1, EndLine: 3, EndColumn: 9},
+	}
+	client := nvimtest.NewMockClient()
+	client.SetupReplaceText("main.go", "return 1", "return 2", types.ReplaceTextOptions{Occurrence: 2, ExpectedVersion: 7}, result, nil)
+	mcpserver.NewServer(client)
+
+	_, output, err := ReplaceTextHandler(t.Context(), nil, ReplaceTextInput{
+		BufferTitle: "main.go",
+		OldText:     "return 1",
+		NewText:     "return 2",
+		Occurrence:  2,
+
+		ExpectedVersion: 7,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, result, output.ReplaceTextResult)
+	client.AssertExpectations(t)
+}
@@ -6,39 +6,74 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
 )
 
 // SetBufferLinesInput dto for set buffer lines request
 type SetBufferLinesInput struct {
-	BufferTitle string   `json:"buffer_title" jsonschema:"buffer title or filename"`
-	StartLine   int      `json:"start_line" jsonschema:"starting line number (1-based, inclusive)"`
-	EndLine     int      `json:"end_line" jsonschema:"ending line number (1-based, inclusive)"`
+	mcpserver.InstanceInput
+	AnchorInput
+
+	BufferTitle string   `json:"buffer_title,omitempty" jsonschema:"buffer handle, absolute or cwd-relative path, file:// URI, or unique filename; not needed with an anchor"`
+	StartLine   int      `json:"start_line,omitempty" jsonschema:"starting line number (1-based, inclusive)"`
+	EndLine     int      `json:"end_line,omitempty" jsonschema:"ending line number (1-based, inclusive)"`
 	Lines       []string `json:"lines" jsonschema:"array of new line contents"`
+	Reindent    bool     `json:"reindent,omitempty" jsonschema:"re-indent the new lines by the buffer's indent rules, keeping their indentation relative to each other"`
+
+	ExpectedVersion int `json:"expected_version,omitempty" jsonschema:"buffer version returned by a read tool, the write fails with a version conflict if the buffer changed since"`
 }
 
 // SetBufferLinesOutput dto for set buffer lines response
 type SetBufferLinesOutput struct {
 	Success bool `json:"success" jsonschema:"whether lines were set successfully"`
+	Version int  `json:"version" jsonschema:"buffer version after the write"`
+
+	Changed *types.TextRange `json:"changed,omitempty" jsonschema:"range of the re-indented lines, byte columns"`
 }
 
 // SetBufferLinesHandler handles set buffer lines
 func SetBufferLinesHandler(ctx context.Context, req *mcp.CallToolRequest, input SetBufferLinesInput) (*mcp.CallToolResult, SetBufferLinesOutput, error) {
-	nvimClient := mcpserver.GetNvimClient()
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, SetBufferLinesOutput{}, err
+	}
+
+	title, startLine, endLine := input.BufferTitle, input.StartLine, input.EndLine
+	if input.Anchor != "" {
+		var anchor types.Anchor
+		if title, anchor, err = resolveAnchor(ctx, nvimClient, input.Anchor); err != nil {
+			return nil, SetBufferLinesOutput{}, err
+		}
 
-	err := nvimClient.SetBufferLines(ctx, input.BufferTitle, input.StartLine, input.EndLine, input.Lines)
+		startLine, endLine = anchor.Range.StartLine, anchor.Range.EndLine
+	}
+
+	if input.Reindent && len(input.Lines) > 0 {
+		changed, version, rerr := nvimClient.SetReindentedLines(ctx, title, startLine, endLine, input.Lines, input.ExpectedVersion)
+		if rerr != nil {
+			return nil, SetBufferLinesOutput{}, rerr
+		}
+
+		return nil, SetBufferLinesOutput{Success: true, Version: version, Changed: &changed}, nil
+	}
+
+	version, err := nvimClient.SetBufferLines(ctx, title, startLine, endLine, input.Lines, input.ExpectedVersion)
 	if err != nil {
 		return nil, SetBufferLinesOutput{}, err
 	}
 
-	return nil, SetBufferLinesOutput{
+	output := SetBufferLinesOutput{
 		Success: true,
-	}, nil
+		Version: version,
+	}
+
+	return nil, output, nil
 }
 
 // RegisterSetBufferLinesTool registers the set buffer lines tool
 func RegisterSetBufferLinesTool(server *mcp.Server) {
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "set_buffer_lines",
-		Description: "Write or replace lines in a buffer with 1-based line indexing",
+		Description: "Write or replace lines in a buffer with 1-based line indexing, or replace the lines spanned by an anchor",
 	}, SetBufferLinesHandler)
 }
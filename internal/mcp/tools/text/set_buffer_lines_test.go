@@ -1,7 +1,55 @@
 package text
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
 
 func TestSetBufferLinesHandler(t *testing.T) {
-	t.Skip("Not implemented")
+	t.Run("writes lines", func(t *testing.T) {
+		client := nvimtest.NewMockClient()
+		client.SetupSetBufferLines("main.go", 2, 3, []string{"x"}, 0, 4, nil)
+		mcpserver.NewServer(client)
+
+		_, output, err := SetBufferLinesHandler(t.Context(), nil, SetBufferLinesInput{
+			BufferTitle: "main.go",
+			StartLine:   2,
+			EndLine:     3,
+			Lines:       []string{"x"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 4, output.Version)
+		assert.Nil(t, output.Changed)
+		client.AssertExpectations(t)
+	})
+
+	t.Run("reindents the new lines", func(t *testing.T) {
+		lines := []string{"if x {", "y()", "}"}
+		changed := types.TextRange{StartLine: 2, StartColumn: 1, EndLine: 4, EndColumn: 3}
+		client := nvimtest.NewMockClient()
+		client.SetupSetReindentedLines("main.go", 2, 2, lines, 4, changed, 5, nil)
+		mcpserver.NewServer(client)
+
+		_, output, err := SetBufferLinesHandler(t.Context(), nil, SetBufferLinesInput{
+			BufferTitle: "main.go",
+			StartLine:   2,
+			EndLine:     2,
+			Lines:       lines,
+			Reindent:    true,
+
+			ExpectedVersion: 4,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, &changed, output.Changed)
+		assert.Equal(t, 5, output.Version)
+		client.AssertExpectations(t)
+	})
 }
@@ -0,0 +1,65 @@
+package text
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// SetTextInput dto for set text request
+type SetTextInput struct {
+	mcpserver.InstanceInput
+	AnchorInput
+	RangeInput
+
+	BufferTitle string `json:"buffer_title,omitempty" jsonschema:"buffer handle, absolute or cwd-relative path, file:// URI, or unique filename; not needed with an anchor"`
+	Text        string `json:"text" jsonschema:"text replacing the range, may contain newlines; use an empty range to insert and empty text to delete"`
+	Encoding    string `json:"encoding,omitempty" jsonschema:"how columns are counted: byte (default), codepoint or utf-16 (LSP positions); anchors count bytes"`
+
+	ExpectedVersion int `json:"expected_version,omitempty" jsonschema:"buffer version returned by a read tool, the write fails with a version conflict if the buffer changed since"`
+}
+
+// SetTextOutput dto for set text response
+type SetTextOutput struct {
+	Changed types.TextRange `json:"changed" jsonschema:"range of the new text in the updated buffer, in the same encoding"`
+	Version int             `json:"version" jsonschema:"buffer version after the write"`
+}
+
+// SetTextHandler handles set text
+func SetTextHandler(ctx context.Context, req *mcp.CallToolRequest, input SetTextInput) (*mcp.CallToolResult, SetTextOutput, error) {
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, SetTextOutput{}, err
+	}
+
+	title, rng, encoding := input.BufferTitle, input.textRange(), input.Encoding
+	if input.Anchor != "" {
+		var anchor types.Anchor
+		if title, anchor, err = resolveAnchor(ctx, nvimClient, input.Anchor); err != nil {
+			return nil, SetTextOutput{}, err
+		}
+
+		rng, encoding = anchor.Range, ""
+	}
+
+	changed, version, err := nvimClient.SetText(ctx, title, rng, input.Text, encoding, input.ExpectedVersion)
+	if err != nil {
+		return nil, SetTextOutput{}, err
+	}
+
+	return nil, SetTextOutput{
+		Changed: changed,
+		Version: version,
+	}, nil
+}
+
+// RegisterSetTextTool registers the set text tool
+func RegisterSetTextTool(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "set_text",
+		Description: "Replace the text of a buffer between two line and column positions (1-based, end column exclusive) without touching the rest of the lines, or the text of an anchor",
+	}, SetTextHandler)
+}
@@ -0,0 +1,33 @@
+package text
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/cousine/neovim-mcp/internal/mcp"
+	"github.com/cousine/neovim-mcp/internal/nvim/nvimtest"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestSetTextHandler(t *testing.T) {
+	rng := types.TextRange{StartLine: 2, StartColumn: 3, EndLine: 2, EndColumn: 4}
+	changed := types.TextRange{StartLine: 2, StartColumn: 3, EndLine: 2, EndColumn: 6}
+	client := nvimtest.NewMockClient()
+	client.SetupSetText("main.go", rng, "foo", "", 12, changed, 13, nil)
+	mcpserver.NewServer(client)
+
+	_, output, err := SetTextHandler(t.Context(), nil, SetTextInput{
+		RangeInput:  RangeInput(rng),
+		BufferTitle: "main.go",
+		Text:        "foo",
+
+		ExpectedVersion: 12,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, changed, output.Changed)
+	assert.Equal(t, 13, output.Version)
+	client.AssertExpectations(t)
+}
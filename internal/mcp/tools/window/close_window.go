@@ -11,6 +11,8 @@ import (
 
 // CloseWindowInput dto for close window request
 type CloseWindowInput struct {
+	mcpserver.InstanceInput
+
 	WindowID int `json:"window_id" jsonschema:"window handle/ID to close"`
 }
 
@@ -21,9 +23,12 @@ type CloseWindowOutput struct {
 
 // CloseWindowHandler handles close window
 func CloseWindowHandler(ctx context.Context, req *mcp.CallToolRequest, input CloseWindowInput) (*mcp.CallToolResult, CloseWindowOutput, error) {
-	nvimClient := mcpserver.GetNvimClient()
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, CloseWindowOutput{}, err
+	}
 
-	err := nvimClient.CloseWindow(ctx, input.WindowID)
+	err = nvimClient.CloseWindow(ctx, input.WindowID)
 	if err != nil {
 		return nil, CloseWindowOutput{}, err
 	}
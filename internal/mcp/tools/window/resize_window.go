@@ -10,6 +10,8 @@ import (
 
 // ResizeWindowInput dto for resize window request
 type ResizeWindowInput struct {
+	mcpserver.InstanceInput
+
 	WindowID int `json:"window_id" jsonschema:"window handle/ID to resize"`
 	Width    int `json:"width,omitempty" jsonschema:"new width in columns (0 to keep current)"`
 	Height   int `json:"height,omitempty" jsonschema:"new height in rows (0 to keep current)"`
@@ -22,9 +24,12 @@ type ResizeWindowOutput struct {
 
 // ResizeWindowHandler handles resize window
 func ResizeWindowHandler(ctx context.Context, req *mcp.CallToolRequest, input ResizeWindowInput) (*mcp.CallToolResult, ResizeWindowOutput, error) {
-	nvimClient := mcpserver.GetNvimClient()
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, ResizeWindowOutput{}, err
+	}
 
-	err := nvimClient.ResizeWindow(ctx, input.WindowID, input.Width, input.Height)
+	err = nvimClient.ResizeWindow(ctx, input.WindowID, input.Width, input.Height)
 	if err != nil {
 		return nil, ResizeWindowOutput{}, err
 	}
@@ -11,6 +11,8 @@ import (
 
 // SplitWindowInput dto for split window request
 type SplitWindowInput struct {
+	mcpserver.InstanceInput
+
 	Direction   string `json:"direction" jsonschema:"split direction: 'horizontal' or 'vertical'"`
 	BufferTitle string `json:"buffer_title,omitempty" jsonschema:"optional buffer to open in new window"`
 }
@@ -22,7 +24,10 @@ type SplitWindowOutput struct {
 
 // SplitWindowHandler handles split window
 func SplitWindowHandler(ctx context.Context, req *mcp.CallToolRequest, input SplitWindowInput) (*mcp.CallToolResult, SplitWindowOutput, error) {
-	nvimClient := mcpserver.GetNvimClient()
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, SplitWindowOutput{}, err
+	}
 
 	wInfo, err := nvimClient.SplitWindow(ctx, input.Direction, input.BufferTitle)
 	if err != nil {
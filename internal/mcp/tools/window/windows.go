@@ -9,8 +9,10 @@ import (
 	"github.com/cousine/neovim-mcp/internal/types"
 )
 
-// GetWindowsInput dto for get windows request (reserved for future)
-type GetWindowsInput struct{}
+// GetWindowsInput dto for get windows request
+type GetWindowsInput struct {
+	mcpserver.InstanceInput
+}
 
 // GetWindowsOutput dto for get windows response
 type GetWindowsOutput struct {
@@ -19,7 +21,10 @@ type GetWindowsOutput struct {
 
 // GetWindowsHandler handles get windows
 func GetWindowsHandler(ctx context.Context, req *mcp.CallToolRequest, input GetWindowsInput) (*mcp.CallToolResult, GetWindowsOutput, error) {
-	nvimClient := mcpserver.GetNvimClient()
+	nvimClient, err := mcpserver.GetInstanceClient(input.Instance)
+	if err != nil {
+		return nil, GetWindowsOutput{}, err
+	}
 
 	windows, err := nvimClient.GetWindows(ctx)
 	if err != nil {
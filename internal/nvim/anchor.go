@@ -0,0 +1,159 @@
+package nvim
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/neovim/go-client/nvim"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// anchorNamespace is the extmark namespace of anchors, anchor names map to their
+// buffer and extmark in the g:neovim_mcp_anchors dictionary
+const anchorNamespace = "neovim-mcp"
+
+// luaCreateAnchor places the extmark of an anchor, replacing the anchor of the same name.
+// The start stays left and the end moves right of text inserted at them, so text written
+// over the whole range stays anchored. The end of a whole-line anchor is placed at the
+// start of the line after its last line, which the anchor records.
+const luaCreateAnchor = `
+	local namespace, name, buf, row, col, end_row, end_col, whole = ...
+	local ns = vim.api.nvim_create_namespace(namespace)
+	local anchors = vim.g.neovim_mcp_anchors or vim.empty_dict()
+	local old = anchors[name]
+	if old and vim.api.nvim_buf_is_valid(old[1]) then
+		pcall(vim.api.nvim_buf_del_extmark, old[1], ns, old[2])
+	end
+	if whole then
+		end_row, end_col = end_row + 1, 0
+	end
+	local id = vim.api.nvim_buf_set_extmark(buf, ns, row, col, {
+		end_row = end_row,
+		end_col = end_col,
+		right_gravity = false,
+		end_right_gravity = true,
+		strict = false,
+	})
+	anchors[name] = { buf, id, whole }
+	vim.g.neovim_mcp_anchors = anchors
+`
+
+// luaResolveAnchor returns the current position of an anchor (0-based, end column
+// exclusive). The end of a whole-line anchor, at the start of the line after it, is
+// moved to the end of its last line.
+const luaResolveAnchor = `
+	local namespace, name = ...
+	local ns = vim.api.nvim_create_namespace(namespace)
+	local a = (vim.g.neovim_mcp_anchors or {})[name]
+	local r = { found = a ~= nil, loaded = false, buffer = 0, row = 0, col = 0, end_row = 0, end_col = 0, tick = 0, lines = {} }
+	if not a or not vim.api.nvim_buf_is_loaded(a[1]) then
+		return r
+	end
+	local m = vim.api.nvim_buf_get_extmark_by_id(a[1], ns, a[2], { details = true })
+	if #m == 0 then
+		return r
+	end
+	r.loaded, r.buffer, r.row, r.col = true, a[1], m[1], m[2]
+	r.end_row, r.end_col = m[3].end_row or m[1], m[3].end_col or m[2]
+	if a[3] and r.end_col == 0 and r.end_row > r.row then
+		r.end_row = r.end_row - 1
+		r.end_col = #vim.api.nvim_buf_get_lines(a[1], r.end_row, r.end_row + 1, true)[1]
+	end
+	r.tick = vim.api.nvim_buf_get_changedtick(a[1])
+	r.lines = vim.api.nvim_buf_get_lines(a[1], r.row, r.end_row + 1, false)
+	return r
+`
+
+// anchorMark is the result of luaResolveAnchor
+type anchorMark struct {
+	Found  bool        `msgpack:"found"`
+	Loaded bool        `msgpack:"loaded"`
+	Buffer nvim.Buffer `msgpack:"buffer"`
+	Row    int         `msgpack:"row"`
+	Col    int         `msgpack:"col"`
+	EndRow int         `msgpack:"end_row"`
+	EndCol int         `msgpack:"end_col"`
+	Tick   int         `msgpack:"tick"`
+	Lines  []string    `msgpack:"lines"`
+}
+
+// CreateAnchor names a range of a buffer (1-based, end column exclusive, byte columns)
+// so it can be referred to after the lines around it change. Zero columns anchor whole
+// lines and an anchor of the same name is replaced.
+func (c *Client) CreateAnchor(ctx context.Context, name, title string, rng types.TextRange) (types.Anchor, error) {
+	if err := ctx.Err(); err != nil {
+		return types.Anchor{}, fmt.Errorf("failed to create anchor: %w", err)
+	}
+
+	if name == "" {
+		return types.Anchor{}, fmt.Errorf("failed to create anchor: %w: the name is empty", ErrInvalidRange)
+	}
+
+	buf, err := c.GetBufferByTitle(ctx, title)
+	if err != nil {
+		return types.Anchor{}, fmt.Errorf("failed to create anchor `%s` in buffer `%s`: %w", name, title, err)
+	}
+
+	wholeLines := rng.StartColumn == 0 && rng.EndColumn == 0
+	if wholeLines {
+		rng.StartColumn, rng.EndColumn = 1, 1
+	}
+
+	_, byteRng, _, err := c.readRange(ctx, buf.Handle, rng, PositionEncodingByte)
+	if err != nil {
+		return types.Anchor{}, fmt.Errorf("failed to create anchor `%s` in buffer `%s`: %w", name, title, err)
+	}
+
+	err = c.rpc(ctx, func(v *nvim.Nvim) error {
+		return v.ExecLua(luaCreateAnchor, nil, anchorNamespace, name, buf.Handle,
+			byteRng.startRow, byteRng.startCol, byteRng.endRow, byteRng.endCol, wholeLines)
+	})
+	if err != nil {
+		return types.Anchor{}, fmt.Errorf("failed to create anchor `%s` in buffer `%s`: %w", name, title, err)
+	}
+
+	return c.ResolveAnchor(ctx, name)
+}
+
+// ResolveAnchor returns the current range and lines of an anchor
+func (c *Client) ResolveAnchor(ctx context.Context, name string) (types.Anchor, error) {
+	if err := ctx.Err(); err != nil {
+		return types.Anchor{}, fmt.Errorf("failed to resolve anchor: %w", err)
+	}
+
+	var mark anchorMark
+
+	err := c.rpc(ctx, func(v *nvim.Nvim) error {
+		return v.ExecLua(luaResolveAnchor, &mark, anchorNamespace, name)
+	})
+	if err != nil {
+		return types.Anchor{}, fmt.Errorf("failed to resolve anchor `%s`: %w", name, err)
+	}
+
+	switch {
+	case !mark.Found:
+		return types.Anchor{}, fmt.Errorf("failed to resolve anchor `%s`: %w, create it with create_anchor", name, ErrAnchorNotFound)
+	case !mark.Loaded:
+		return types.Anchor{}, fmt.Errorf("failed to resolve anchor `%s`: %w, its buffer was closed", name, ErrAnchorNotFound)
+	}
+
+	info, err := c.getBufferInfo(ctx, mark.Buffer)
+	if err != nil {
+		return types.Anchor{}, fmt.Errorf("failed to resolve anchor `%s`: %w", name, err)
+	}
+
+	return types.Anchor{
+		Name:   name,
+		Buffer: mark.Buffer,
+		Path:   info.Path,
+		Range: types.TextRange{
+			StartLine:   mark.Row + 1,
+			StartColumn: mark.Col + 1,
+			EndLine:     mark.EndRow + 1,
+			EndColumn:   mark.EndCol + 1,
+		},
+		Lines:   mark.Lines,
+		Version: mark.Tick,
+	}, nil
+}
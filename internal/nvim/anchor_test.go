@@ -0,0 +1,122 @@
+package nvim
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestClient_Anchors(t *testing.T) {
+	client, cleanup := setupTestNeovim(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	open := func(t *testing.T, content string) string {
+		t.Helper()
+
+		tmpFile := createTempFile(t, content)
+		_, err := client.OpenBuffer(ctx, tmpFile)
+		require.NoError(t, err)
+
+		return filepath.Base(tmpFile)
+	}
+
+	t.Run("follows lines inserted above", func(t *testing.T) {
+		title := open(t, "package main\n\nfunc main() {\n}")
+
+		anchor, err := client.CreateAnchor(ctx, "main", title, types.TextRange{StartLine: 3, EndLine: 4})
+		require.NoError(t, err)
+		assert.Equal(t, types.TextRange{StartLine: 3, StartColumn: 1, EndLine: 4, EndColumn: 2}, anchor.Range)
+		assert.Equal(t, []string{"func main() {", "}"}, anchor.Lines)
+
+		_, err = client.SetBufferLines(ctx, title, 2, 1, []string{"", "import \"fmt\""}, 0)
+		require.NoError(t, err)
+
+		anchor, err = client.ResolveAnchor(ctx, "main")
+		require.NoError(t, err)
+		assert.Equal(t, 5, anchor.Range.StartLine)
+		assert.Equal(t, []string{"func main() {", "}"}, anchor.Lines)
+	})
+
+	t.Run("spans lines written over it", func(t *testing.T) {
+		title := open(t, "a\nold\nz")
+
+		anchor, err := client.CreateAnchor(ctx, "body", title, types.TextRange{StartLine: 2, EndLine: 2})
+		require.NoError(t, err)
+
+		_, err = client.SetBufferLines(ctx, strconv.Itoa(int(anchor.Buffer)), 2, 2, []string{"new1", "new2"}, anchor.Version)
+		require.NoError(t, err)
+
+		anchor, err = client.ResolveAnchor(ctx, "body")
+		require.NoError(t, err)
+		assert.Equal(t, types.TextRange{StartLine: 2, StartColumn: 1, EndLine: 3, EndColumn: 5}, anchor.Range)
+		assert.Equal(t, []string{"new1", "new2"}, anchor.Lines)
+	})
+
+	t.Run("keeps a last line that is empty", func(t *testing.T) {
+		title := open(t, "func f() {\n\treturn\n\n}")
+
+		anchor, err := client.CreateAnchor(ctx, "blank", title, types.TextRange{StartLine: 1, EndLine: 3})
+		require.NoError(t, err)
+		assert.Equal(t, types.TextRange{StartLine: 1, StartColumn: 1, EndLine: 3, EndColumn: 1}, anchor.Range)
+		assert.Equal(t, []string{"func f() {", "\treturn", ""}, anchor.Lines)
+
+		anchor, err = client.ResolveAnchor(ctx, "blank")
+		require.NoError(t, err)
+		assert.Equal(t, 3, anchor.Range.EndLine)
+		assert.Equal(t, []string{"func f() {", "\treturn", ""}, anchor.Lines)
+	})
+
+	t.Run("anchors through the last line", func(t *testing.T) {
+		title := open(t, "a\nb")
+
+		anchor, err := client.CreateAnchor(ctx, "tail", title, types.TextRange{StartLine: 2, EndLine: 2})
+		require.NoError(t, err)
+		assert.Equal(t, types.TextRange{StartLine: 2, StartColumn: 1, EndLine: 2, EndColumn: 2}, anchor.Range)
+		assert.Equal(t, []string{"b"}, anchor.Lines)
+	})
+
+	t.Run("replaces anchors of the same name", func(t *testing.T) {
+		title := open(t, "one\ntwo")
+
+		_, err := client.CreateAnchor(ctx, "word", title, types.TextRange{StartLine: 1, StartColumn: 1, EndLine: 1, EndColumn: 4})
+		require.NoError(t, err)
+
+		anchor, err := client.CreateAnchor(ctx, "word", title, types.TextRange{StartLine: 2, StartColumn: 2, EndLine: 2, EndColumn: 4})
+		require.NoError(t, err)
+		assert.Equal(t, types.TextRange{StartLine: 2, StartColumn: 2, EndLine: 2, EndColumn: 4}, anchor.Range)
+	})
+
+	t.Run("rejects unknown anchors", func(t *testing.T) {
+		_, err := client.ResolveAnchor(ctx, "missing")
+		assert.ErrorIs(t, err, ErrAnchorNotFound)
+	})
+
+	t.Run("rejects ranges past the end", func(t *testing.T) {
+		title := open(t, "one")
+
+		_, err := client.CreateAnchor(ctx, "past", title, types.TextRange{StartLine: 1, EndLine: 3})
+		assert.ErrorIs(t, err, ErrInvalidRange)
+	})
+
+	t.Run("targets edits", func(t *testing.T) {
+		title := open(t, "x\ny\nz")
+
+		_, err := client.CreateAnchor(ctx, "y", title, types.TextRange{StartLine: 2, EndLine: 2})
+		require.NoError(t, err)
+
+		_, err = client.ApplyEdits(ctx, []types.BufferEdit{{Anchor: "y", Kind: types.EditKindLines, Lines: []string{"Y"}}})
+		require.NoError(t, err)
+
+		lines, _, err := client.GetBufferLines(ctx, title, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"x", "Y", "z"}, lines)
+	})
+}
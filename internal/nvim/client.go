@@ -1,18 +1,29 @@
 // Package nvim implements neovim rpc client
 //
+// Concurrency:
+// Client is safe for concurrent use. Buffer lookups read the buffer list and all buffer
+// names in a single call, so a lookup never observes a partially read list. Operations
+// that act on and then read editor state (such as opening a buffer or splitting a
+// window) are sent as a single atomic batch.
+//
 // Context Handling:
 // All Client methods accept context.Context for cancellation and timeout support.
-// Note: The underlying neovim/go-client library does not natively support context-aware
-// RPC calls. Context checking is performed at method entry to provide early cancellation
-// detection, but cannot interrupt in-flight RPC calls to Neovim.
+// The underlying neovim/go-client library does not support context-aware RPC calls, so
+// every call runs in the background and the method returns ctx.Err() as soon as the
+// context is done. Neovim is then sent InterruptKeys to abort the operation that is
+// blocking it, such as a runaway lua loop or a hit-enter prompt.
 package nvim
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/neovim/go-client/nvim"
 
@@ -29,58 +40,65 @@ const (
 
 // Client wraps the Neovim RPC client
 type Client struct {
-	nvim        *nvim.Nvim
-	bufferCache map[nvim.Buffer]string
+	address string
+	cfg     clientConfig
+
+	// connMu guards nvim and status, nvim is nil while disconnected
+	connMu sync.RWMutex
+	nvim   *nvim.Nvim
+	status types.ConnectionStatus
+
+	// lost wakes the supervisor when the connection is lost, done stops it
+	lost      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+
+	// watchMu guards diagnosticsWatchers and the neovim side watch
+	watchMu             sync.Mutex
+	diagnosticsWatchers map[int]func()
+	nextWatcher         int
 }
 
-// NewClient creates a new Neovim client connected to the given socket
-func NewClient(socketAddr string) (*Client, error) {
-	v, err := nvim.Dial(socketAddr)
-	if err != nil {
-		return nil, err
-	}
-
-	client := &Client{
-		nvim:        v,
-		bufferCache: make(map[nvim.Buffer]string),
+// NewClient creates a new Neovim client connected to the given socket. Without
+// WithReconnect it fails when neovim is not reachable, with it the client is returned
+// disconnected and keeps trying to connect in the background.
+func NewClient(socketAddr string, opts ...Option) (*Client, error) {
+	cfg := clientConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
 	}
 
-	err = client.RefreshBufferCache(context.Background())
-	if err != nil {
-		vErr := v.Close()
-		if vErr != nil {
-			logger.Error("nvim: failed to close neovim client connection", "error", vErr)
+	if cfg.dial == nil {
+		cfg.dial = func(ctx context.Context) (*nvim.Nvim, error) {
+			return nvim.Dial(socketAddr, nvim.DialContext(ctx), nvim.DialServe(false))
 		}
-
-		return nil, fmt.Errorf("failed to refresh buffer cache: %w", err)
 	}
 
-	return client, nil
-}
-
-// RefreshBufferCache updates the buffer handle to title mapping
-func (c *Client) RefreshBufferCache(ctx context.Context) error {
-	if err := ctx.Err(); err != nil {
-		return fmt.Errorf("failed to refresh buffer cache: %w", err)
-	}
+	client := &Client{
+		address: socketAddr,
+		cfg:     cfg,
+		status:  types.ConnectionStatus{State: ConnectionStateConnecting, Address: socketAddr},
+		lost:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
 
-	buffers, err := c.nvim.Buffers()
-	if err != nil {
-		return fmt.Errorf("failed to list buffers: %w", err)
+		diagnosticsWatchers: make(map[int]func()),
 	}
 
-	c.bufferCache = make(map[nvim.Buffer]string)
-	for _, buf := range buffers {
-		name, berr := c.nvim.BufferName(buf)
-		if berr != nil {
-			logger.Debug("nvim: failed to read buffer", "error", berr)
-			continue
+	if err := client.connect(context.Background()); err != nil {
+		if !cfg.reconnect {
+			return nil, err
 		}
 
-		c.bufferCache[buf] = name
+		logger.Warn("nvim: neovim is not reachable, retrying in the background",
+			"address", socketAddr, "error", err)
+		client.connectFailed(err)
 	}
 
-	return nil
+	if cfg.reconnect || cfg.healthInterval > 0 {
+		go client.supervise()
+	}
+
+	return client, nil
 }
 
 // GetBuffers returns information about all open buffers
@@ -89,14 +107,21 @@ func (c *Client) GetBuffers(ctx context.Context) ([]types.BufferInfo, error) {
 		return nil, fmt.Errorf("failed to get buffers: %w", err)
 	}
 
-	if err := c.RefreshBufferCache(ctx); err != nil {
+	names, err := c.listBuffers(ctx)
+	if err != nil {
 		return nil, fmt.Errorf("failed to get buffers: %w", err)
 	}
 
-	results := make([]types.BufferInfo, 0, len(c.bufferCache))
-	for buf := range c.bufferCache {
-		info, err := c.getBufferInfo(buf)
+	buffers := slices.Sorted(maps.Keys(names))
+
+	results := make([]types.BufferInfo, 0, len(buffers))
+	for _, buf := range buffers {
+		info, err := c.getBufferInfo(ctx, buf)
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("failed to get buffers: %w", err)
+			}
+
 			logger.Debug("nvim: failed to get buffers", "error", err)
 			continue
 		}
@@ -107,23 +132,29 @@ func (c *Client) GetBuffers(ctx context.Context) ([]types.BufferInfo, error) {
 	return results, nil
 }
 
-// GetBufferByTitle finds a buffer by its title
+// GetBufferByTitle finds a buffer by reference: a buffer handle, an absolute or
+// cwd-relative path, a file:// URI or a file name matching exactly one buffer
 func (c *Client) GetBufferByTitle(ctx context.Context, title string) (types.BufferInfo, error) {
 	if err := ctx.Err(); err != nil {
 		return types.BufferInfo{}, fmt.Errorf("failed to get buffer by title: %w", err)
 	}
 
-	if err := c.RefreshBufferCache(ctx); err != nil {
+	names, err := c.listBuffers(ctx)
+	if err != nil {
 		return types.BufferInfo{}, fmt.Errorf("failed to get buffer: %w", err)
 	}
 
-	for buf, name := range c.bufferCache {
-		if strings.HasSuffix(name, title) || strings.Contains(name, title) {
-			return c.getBufferInfo(buf)
-		}
+	var cwd string
+	if cerr := c.rpc(ctx, func(v *nvim.Nvim) error { return v.Call("getcwd", &cwd) }); cerr != nil {
+		return types.BufferInfo{}, fmt.Errorf("failed to get buffer: %w", cerr)
 	}
 
-	return types.BufferInfo{}, ErrBufferNotFound
+	buf, err := resolveBufferRef(title, cwd, names)
+	if err != nil {
+		return types.BufferInfo{}, err
+	}
+
+	return c.getBufferInfo(ctx, buf)
 }
 
 // GetCurrentBuffer returns information about the current buffer
@@ -132,12 +163,16 @@ func (c *Client) GetCurrentBuffer(ctx context.Context) (types.BufferInfo, error)
 		return types.BufferInfo{}, fmt.Errorf("failed to get current buffer: %w", err)
 	}
 
-	buf, err := c.nvim.CurrentBuffer()
+	var buf nvim.Buffer
+
+	err := c.batch(ctx, func(b *nvim.Batch) {
+		b.CurrentBuffer(&buf)
+	})
 	if err != nil {
 		return types.BufferInfo{}, fmt.Errorf("failed to get current buffer: %w", err)
 	}
 
-	return c.getBufferInfo(buf)
+	return c.getBufferInfo(ctx, buf)
 }
 
 // OpenBuffer opens a file in a new buffer
@@ -146,11 +181,17 @@ func (c *Client) OpenBuffer(ctx context.Context, path string) (types.BufferInfo,
 		return types.BufferInfo{}, fmt.Errorf("failed to open buffer: %w", err)
 	}
 
-	if err := c.nvim.Command(fmt.Sprintf(CmdEditPath, path)); err != nil {
+	var buf nvim.Buffer
+
+	err := c.batch(ctx, func(b *nvim.Batch) {
+		b.Command(fmt.Sprintf(CmdEditPath, path))
+		b.CurrentBuffer(&buf)
+	})
+	if err != nil {
 		return types.BufferInfo{}, fmt.Errorf("failed to open buffer: %w", err)
 	}
 
-	return c.GetCurrentBuffer(ctx)
+	return c.getBufferInfo(ctx, buf)
 }
 
 // CloseBuffer closes a buffer by title
@@ -164,7 +205,8 @@ func (c *Client) CloseBuffer(ctx context.Context, title string) error {
 		return fmt.Errorf("failed to close buffer `%s`: %w", title, err)
 	}
 
-	if cerr := c.nvim.Command(fmt.Sprintf(CmdDeleteBuffer, buf.Handle)); cerr != nil {
+	cmd := fmt.Sprintf(CmdDeleteBuffer, buf.Handle)
+	if cerr := c.rpc(ctx, func(v *nvim.Nvim) error { return v.Command(cmd) }); cerr != nil {
 		return fmt.Errorf("failed to close buffer `%s`: %w", title, cerr)
 	}
 
@@ -182,27 +224,36 @@ func (c *Client) SwitchBuffer(ctx context.Context, title string) error {
 		return fmt.Errorf("failed to switch to buffer `%s`: %w", title, err)
 	}
 
-	if berr := c.nvim.SetCurrentBuffer(buf.Handle); berr != nil {
+	if berr := c.rpc(ctx, func(v *nvim.Nvim) error { return v.SetCurrentBuffer(buf.Handle) }); berr != nil {
 		return fmt.Errorf("failed to switch to buffer `%s`: %w", title, berr)
 	}
 
 	return nil
 }
 
-// GetBufferLines retrieves lines from a buffer (1-based indexing)
-func (c *Client) GetBufferLines(ctx context.Context, title string, start, end int) ([]string, error) {
+// GetBufferLines retrieves lines from a buffer (1-based indexing) and the buffer
+// version they were read at
+func (c *Client) GetBufferLines(ctx context.Context, title string, start, end int) ([]string, int, error) {
 	if err := ctx.Err(); err != nil {
-		return nil, fmt.Errorf("failed to get buffer lines: %w", err)
+		return nil, 0, fmt.Errorf("failed to get buffer lines: %w", err)
 	}
 
 	buf, err := c.GetBufferByTitle(ctx, title)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get lines from buffer `%s`: %w", title, err)
+		return nil, 0, fmt.Errorf("failed to get lines from buffer `%s`: %w", title, err)
 	}
 
-	lines, err := c.nvim.BufferLines(buf.Handle, start-1, end, true)
+	var (
+		lines   [][]byte
+		version int
+	)
+
+	err = c.batch(ctx, func(b *nvim.Batch) {
+		b.BufferLines(buf.Handle, start-1, end, true, &lines)
+		b.BufferChangedTick(buf.Handle, &version)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get lines from buffer `%s`: %w", title, err)
+		return nil, 0, fmt.Errorf("failed to get lines from buffer `%s`: %w", title, err)
 	}
 
 	result := make([]string, len(lines))
@@ -210,59 +261,70 @@ func (c *Client) GetBufferLines(ctx context.Context, title string, start, end in
 		result[i] = string(line)
 	}
 
-	return result, nil
+	return result, version, nil
 }
 
-// SetBufferLines sets lines in a buffer (1-based indexing)
-func (c *Client) SetBufferLines(ctx context.Context, title string, start, end int, lines []string) error {
+// SetBufferLines sets lines in a buffer (1-based indexing) and returns the new buffer
+// version. A non-zero expected version fails the write with a VersionConflictError when
+// the buffer changed since.
+func (c *Client) SetBufferLines(ctx context.Context, title string, start, end int, lines []string, expectedVersion int) (int, error) {
 	if err := ctx.Err(); err != nil {
-		return fmt.Errorf("failed to set buffer lines: %w", err)
+		return 0, fmt.Errorf("failed to set buffer lines: %w", err)
 	}
 
 	buf, err := c.GetBufferByTitle(ctx, title)
 	if err != nil {
-		return fmt.Errorf("failed to set lines in buffer `%s`: %w", title, err)
+		return 0, fmt.Errorf("failed to set lines in buffer `%s`: %w", title, err)
 	}
 
-	byteLines := make([][]byte, len(lines))
-	for i, line := range lines {
-		byteLines[i] = []byte(line)
+	if lines == nil {
+		lines = []string{}
 	}
 
-	if berr := c.nvim.SetBufferLines(buf.Handle, start-1, end, true, byteLines); berr != nil {
-		return fmt.Errorf("failed to set lines in buffer `%s`: %w", title, berr)
-	}
+	if expectedVersion != 0 {
+		version, serr := c.setLines(ctx, buf.Handle, expectedVersion, []lineEdit{{start: start - 1, end: end, lines: lines}})
+		if errors.Is(serr, errStaleBuffer) {
+			serr = c.conflictError(ctx, buf.Handle, title, expectedVersion, start, end)
+		}
 
-	return nil
-}
+		if serr != nil {
+			return 0, fmt.Errorf("failed to set lines in buffer `%s`: %w", title, serr)
+		}
 
-// InsertText inserts text at the current cursor position
-func (c *Client) InsertText(ctx context.Context, text string) error {
-	if err := ctx.Err(); err != nil {
-		return fmt.Errorf("failed to insert text: %w", err)
+		return version, nil
 	}
 
-	written, err := c.nvim.Input(text)
-	if err != nil {
-		return fmt.Errorf("failed to insert text: %w", err)
+	byteLines := make([][]byte, len(lines))
+	for i, line := range lines {
+		byteLines[i] = []byte(line)
 	}
 
-	logger.Debug("nvim: written bytes at cursor", "bytes", written)
+	var version int
 
-	return nil
+	berr := c.batch(ctx, func(b *nvim.Batch) {
+		b.SetBufferLines(buf.Handle, start-1, end, true, byteLines)
+		b.BufferChangedTick(buf.Handle, &version)
+	})
+	if berr != nil {
+		return 0, fmt.Errorf("failed to set lines in buffer `%s`: %w", title, berr)
+	}
+
+	return version, nil
 }
 
-// DeleteLines deletes lines from a buffer (1-based indexing)
-func (c *Client) DeleteLines(ctx context.Context, title string, start, end int) error {
+// DeleteLines deletes lines from a buffer (1-based indexing) and returns the new buffer
+// version, a non-zero expected version is checked as by SetBufferLines
+func (c *Client) DeleteLines(ctx context.Context, title string, start, end int, expectedVersion int) (int, error) {
 	if err := ctx.Err(); err != nil {
-		return fmt.Errorf("failed to delete lines: %w", err)
+		return 0, fmt.Errorf("failed to delete lines: %w", err)
 	}
 
-	if err := c.SetBufferLines(ctx, title, start, end, []string{}); err != nil {
-		return fmt.Errorf("failed to delete lines from buffer `%s`: %w", title, err)
+	version, err := c.SetBufferLines(ctx, title, start, end, []string{}, expectedVersion)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete lines from buffer `%s`: %w", title, err)
 	}
 
-	return nil
+	return version, nil
 }
 
 // GetCursorPosition returns the current cursor position (1-based)
@@ -271,12 +333,12 @@ func (c *Client) GetCursorPosition(ctx context.Context) (types.CursorPosition, e
 		return types.CursorPosition{}, fmt.Errorf("failed to get cursor position: %w", err)
 	}
 
-	w, err := c.nvim.CurrentWindow()
-	if err != nil {
-		return types.CursorPosition{}, fmt.Errorf("failed to get cursor position: %w", err)
-	}
+	// window 0 is the current window, resolved by neovim within the same request
+	var pos [2]int
 
-	pos, err := c.nvim.WindowCursor(w)
+	err := c.batch(ctx, func(b *nvim.Batch) {
+		b.WindowCursor(0, &pos)
+	})
 	if err != nil {
 		return types.CursorPosition{}, fmt.Errorf("failed to get cursor position: %w", err)
 	}
@@ -293,15 +355,10 @@ func (c *Client) SetCursorPosition(ctx context.Context, line, col int) error {
 		return fmt.Errorf("failed to set cursor position: %w", err)
 	}
 
-	w, err := c.nvim.CurrentWindow()
-	if err != nil {
+	if err := c.rpc(ctx, func(v *nvim.Nvim) error { return v.SetWindowCursor(0, [2]int{line, col - 1}) }); err != nil {
 		return fmt.Errorf("failed to set cursor position: %w", err)
 	}
 
-	if werr := c.nvim.SetWindowCursor(w, [2]int{line, col - 1}); werr != nil {
-		return fmt.Errorf("failed to set cursor position: %w", werr)
-	}
-
 	return nil
 }
 
@@ -311,91 +368,36 @@ func (c *Client) GotoLine(ctx context.Context, line int) error {
 		return fmt.Errorf("failed to goto line: %w", err)
 	}
 
-	if err := c.nvim.Command(strconv.Itoa(line)); err != nil {
+	if err := c.rpc(ctx, func(v *nvim.Nvim) error { return v.Command(strconv.Itoa(line)) }); err != nil {
 		return fmt.Errorf("failed to goto line %d : %w", line, err)
 	}
 
 	return nil
 }
 
-// Search searches for a pattern in the current buffer
-func (c *Client) Search(ctx context.Context, pattern string, flags string) ([]types.SearchResult, error) {
-	if err := ctx.Err(); err != nil {
-		return nil, fmt.Errorf("failed to search: %w", err)
-	}
-
-	luaCode := fmt.Sprintf(`
-		local results = {}
-		local pos = vim.fn.searchpos(%q, 'w' .. %q)
-		while pos[1] ~= 0 do 
-			local line = vim.fn.getline(pos[1])
-			table.insert(results, {line = pos[1], col = pos[2], text = line})
-			pos = vim.fn.searchpos(%q, 'W' .. %q)
-		end
-		return results
-	`, pattern, flags, pattern, flags)
-
-	out, err := c.ExecLua(ctx, luaCode, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search: %w", err)
-	}
-
-	results, ok := out.([]any)
-	if !ok {
-		return nil, fmt.Errorf("failed to search: ExecLua output is not an array: %t", out)
-	}
-
-	searchResults := make([]types.SearchResult, 0, len(results))
-	for _, r := range results {
-		m, resOk := r.(map[string]any)
-		if !resOk {
-			logger.Debug("result is an unknown type: %t", r)
-			continue
-		}
-
-		line, lOk := m["line"].(int64)
-		if !lOk {
-			logger.Debug("line is an unknown type: %t", line)
-			continue
-		}
-
-		col, colOk := m["col"].(int64)
-		if !colOk {
-			logger.Debug("col is an unknown type: %t", colOk)
-			continue
-		}
-
-		matchText, matchOk := m["text"].(string)
-		if !matchOk {
-			logger.Debug("match text is an unknown type: %t", matchText)
-			continue
-		}
-
-		searchResults = append(searchResults, types.SearchResult{
-			Line:      int(line),
-			Column:    int(col),
-			MatchText: matchText,
-		})
-	}
-
-	return searchResults, nil
-}
-
 // GetWindows returns information about all windows
 func (c *Client) GetWindows(ctx context.Context) ([]types.WindowInfo, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, fmt.Errorf("failed to get windows: %w", err)
 	}
 
-	windows, err := c.nvim.Windows()
+	var windows []nvim.Window
+
+	err := c.batch(ctx, func(b *nvim.Batch) {
+		b.Windows(&windows)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list windows: %w", err)
 	}
 
 	result := make([]types.WindowInfo, 0, len(windows))
 	for _, win := range windows {
-		winInfo, werr := c.getWindowInfo(win)
+		winInfo, werr := c.getWindowInfo(ctx, win)
 		if werr != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("failed to get windows: %w", werr)
+			}
+
 			logger.Debug("nvim: failed to retrieve window info", "error", werr)
 			continue
 		}
@@ -421,16 +423,17 @@ func (c *Client) SplitWindow(ctx context.Context, direction string, bufferTitle
 		cmd = fmt.Sprintf("%s %s", cmd, bufferTitle)
 	}
 
-	if err := c.nvim.Command(cmd); err != nil {
-		return types.WindowInfo{}, fmt.Errorf("failed to split window: %w", err)
-	}
+	var win nvim.Window
 
-	win, err := c.nvim.CurrentWindow()
+	err := c.batch(ctx, func(b *nvim.Batch) {
+		b.Command(cmd)
+		b.CurrentWindow(&win)
+	})
 	if err != nil {
-		return types.WindowInfo{}, fmt.Errorf("failed to get newly split window: %w", err)
+		return types.WindowInfo{}, fmt.Errorf("failed to split window: %w", err)
 	}
 
-	winInfo, err := c.getWindowInfo(win)
+	winInfo, err := c.getWindowInfo(ctx, win)
 	if err != nil {
 		return types.WindowInfo{}, fmt.Errorf("failed to get newly split window info: %w", err)
 	}
@@ -444,7 +447,7 @@ func (c *Client) CloseWindow(ctx context.Context, windowID int) error {
 		return fmt.Errorf("failed to close window: %w", err)
 	}
 
-	if err := c.nvim.CloseWindow(nvim.Window(windowID), true); err != nil {
+	if err := c.rpc(ctx, func(v *nvim.Nvim) error { return v.CloseWindow(nvim.Window(windowID), true) }); err != nil {
 		return fmt.Errorf("failed to close window: %w", err)
 	}
 
@@ -459,19 +462,40 @@ func (c *Client) ResizeWindow(ctx context.Context, windowID, width, height int)
 
 	win := nvim.Window(windowID)
 
-	batch := c.nvim.NewBatch()
-	if width > 0 {
-		batch.SetWindowWidth(win, width)
+	err := c.batch(ctx, func(b *nvim.Batch) {
+		if width > 0 {
+			b.SetWindowWidth(win, width)
+		}
+
+		if height > 0 {
+			b.SetWindowHeight(win, height)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to resize window: %w", err)
 	}
 
-	if height > 0 {
-		batch.SetWindowHeight(win, height)
+	return nil
+}
+
+// SendInput queues keys as if typed by the user, their effect depends on the current
+// mode and mappings
+func (c *Client) SendInput(ctx context.Context, keys string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to send input: %w", err)
 	}
 
-	if err := batch.Execute(); err != nil {
-		return fmt.Errorf("failed to resize window: %w", err)
+	var written int
+
+	err := c.batch(ctx, func(b *nvim.Batch) {
+		b.Input(keys, &written)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send input: %w", err)
 	}
 
+	logger.Debug("nvim: sent input", "bytes", written)
+
 	return nil
 }
 
@@ -481,7 +505,11 @@ func (c *Client) ExecCommand(ctx context.Context, command string) (string, error
 		return "", fmt.Errorf("failed to exec command: %w", err)
 	}
 
-	output, err := c.nvim.Exec(command, true)
+	var output string
+
+	err := c.batch(ctx, func(b *nvim.Batch) {
+		b.Exec(command, true, &output)
+	})
 	if err != nil {
 		return "", fmt.Errorf("failed to execute command: %w", err)
 	}
@@ -496,7 +524,7 @@ func (c *Client) ExecLua(ctx context.Context, code string, args []any) (any, err
 	}
 
 	var result any
-	if err := c.nvim.ExecLua(code, &result, args...); err != nil {
+	if err := c.rpc(ctx, func(v *nvim.Nvim) error { return v.ExecLua(code, &result, args...) }); err != nil {
 		return nil, fmt.Errorf("failed to execute lua: %w", err)
 	}
 
@@ -510,34 +538,87 @@ func (c *Client) CallFunction(ctx context.Context, fname string, args []any) (an
 	}
 
 	var result any
-	if err := c.nvim.Call(fname, &result, args...); err != nil {
+	if err := c.rpc(ctx, func(v *nvim.Nvim) error { return v.Call(fname, &result, args...) }); err != nil {
 		return nil, fmt.Errorf("failed to call function: %w", err)
 	}
 
 	return result, nil
 }
 
-// Close closes the Neovim connection
+// Close closes the Neovim connection and stops reconnecting
 func (c *Client) Close() error {
-	return c.nvim.Close()
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+
+	c.connMu.Lock()
+	v := c.nvim
+	c.nvim = nil
+	c.status.State = ConnectionStateClosed
+	c.status.Responsive = false
+	c.connMu.Unlock()
+
+	if v == nil {
+		return nil
+	}
+
+	return v.Close()
 }
 
 // ----------------------------------------------------------------------------
 
+// luaListBuffers returns the handle and name of every buffer
+const luaListBuffers = `
+	local r = {}
+	for _, buf in ipairs(vim.api.nvim_list_bufs()) do
+		table.insert(r, { handle = buf, name = vim.api.nvim_buf_get_name(buf) })
+	end
+	return r
+`
+
+// bufferName is an entry of the luaListBuffers result
+type bufferName struct {
+	Handle int    `msgpack:"handle"`
+	Name   string `msgpack:"name"`
+}
+
+// listBuffers returns the name of every neovim buffer by handle
+func (c *Client) listBuffers(ctx context.Context) (map[nvim.Buffer]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list buffers: %w", err)
+	}
+
+	var buffers []bufferName
+
+	err := c.rpc(ctx, func(v *nvim.Nvim) error {
+		return v.ExecLua(luaListBuffers, &buffers)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list buffers: %w", err)
+	}
+
+	names := make(map[nvim.Buffer]string, len(buffers))
+	for _, buf := range buffers {
+		names[nvim.Buffer(buf.Handle)] = buf.Name
+	}
+
+	return names, nil
+}
+
 // getBufferInfo returns the neovim buffer info
-func (c *Client) getBufferInfo(buf nvim.Buffer) (types.BufferInfo, error) {
+func (c *Client) getBufferInfo(ctx context.Context, buf nvim.Buffer) (types.BufferInfo, error) {
 	var name string
 	var loaded bool
 	var changed bool
 	var lineCount int
 
-	batch := c.nvim.NewBatch()
-	batch.BufferName(buf, &name)
-	batch.BufferOption(buf, "buflisted", &loaded)
-	batch.BufferOption(buf, "modified", &changed)
-	batch.BufferLineCount(buf, &lineCount)
-
-	if err := batch.Execute(); err != nil {
+	err := c.batch(ctx, func(b *nvim.Batch) {
+		b.BufferName(buf, &name)
+		b.BufferOption(buf, "buflisted", &loaded)
+		b.BufferOption(buf, "modified", &changed)
+		b.BufferLineCount(buf, &lineCount)
+	})
+	if err != nil {
 		return types.BufferInfo{}, fmt.Errorf("failed to get buffer info: %w", err)
 	}
 
@@ -557,21 +638,35 @@ func (c *Client) getBufferInfo(buf nvim.Buffer) (types.BufferInfo, error) {
 	}, nil
 }
 
+// bufferOrCurrent returns the buffer titled title, or the current buffer for an empty title
+func (c *Client) bufferOrCurrent(ctx context.Context, title string) (types.BufferInfo, error) {
+	if title == "" {
+		return c.GetCurrentBuffer(ctx)
+	}
+
+	buf, err := c.GetBufferByTitle(ctx, title)
+	if err != nil {
+		return types.BufferInfo{}, fmt.Errorf("buffer `%s`: %w", title, err)
+	}
+
+	return buf, nil
+}
+
 // getWindowInfo returns the neovim window info
-func (c *Client) getWindowInfo(win nvim.Window) (types.WindowInfo, error) {
+func (c *Client) getWindowInfo(ctx context.Context, win nvim.Window) (types.WindowInfo, error) {
 	var buf nvim.Buffer
 	var width, height int
 
-	batch := c.nvim.NewBatch()
-	batch.WindowBuffer(win, &buf)
-	batch.WindowWidth(win, &width)
-	batch.WindowHeight(win, &height)
-
-	if err := batch.Execute(); err != nil {
+	err := c.batch(ctx, func(b *nvim.Batch) {
+		b.WindowBuffer(win, &buf)
+		b.WindowWidth(win, &width)
+		b.WindowHeight(win, &height)
+	})
+	if err != nil {
 		return types.WindowInfo{}, fmt.Errorf("failed to get window info: %w", err)
 	}
 
-	bufInfo, err := c.getBufferInfo(buf)
+	bufInfo, err := c.getBufferInfo(ctx, buf)
 	if err != nil {
 		return types.WindowInfo{}, fmt.Errorf("failed to get buffer info: %w", err)
 	}
@@ -7,12 +7,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"testing"
 	"time"
 
-	"github.com/neovim/go-client/nvim"
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/cousine/neovim-mcp/internal/types"
@@ -110,7 +109,6 @@ func TestNewClient(t *testing.T) {
 
 		assert.NotNil(t, client)
 		assert.NotNil(t, client.nvim)
-		assert.NotNil(t, client.bufferCache)
 	})
 
 	t.Run("fails with invalid socket", func(t *testing.T) {
@@ -215,6 +213,63 @@ func TestClient_GetBufferByTitle(t *testing.T) {
 		assert.NotZero(t, buffer.Handle)
 	})
 
+	t.Run("finds buffer by handle", func(t *testing.T) {
+		tmpFile := createTempFile(t, "test")
+
+		opened, err := client.OpenBuffer(ctx, tmpFile)
+		require.NoError(t, err)
+
+		buffer, err := client.GetBufferByTitle(ctx, strconv.Itoa(int(opened.Handle)))
+
+		require.NoError(t, err)
+		assert.Equal(t, opened.Handle, buffer.Handle)
+	})
+
+	t.Run("finds buffer by file uri", func(t *testing.T) {
+		tmpFile := createTempFile(t, "test")
+
+		opened, err := client.OpenBuffer(ctx, tmpFile)
+		require.NoError(t, err)
+
+		buffer, err := client.GetBufferByTitle(ctx, "file://"+opened.Path)
+
+		require.NoError(t, err)
+		assert.Equal(t, opened.Handle, buffer.Handle)
+	})
+
+	t.Run("finds buffer by cwd relative path", func(t *testing.T) {
+		tmpDir := resolvePath(t, t.TempDir())
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "sub"), 0o755))
+
+		_, err := client.ExecCommand(ctx, "cd "+tmpDir)
+		require.NoError(t, err)
+
+		opened, err := client.OpenBuffer(ctx, filepath.Join(tmpDir, "sub", "relative.txt"))
+		require.NoError(t, err)
+
+		buffer, err := client.GetBufferByTitle(ctx, "sub/relative.txt")
+
+		require.NoError(t, err)
+		assert.Equal(t, opened.Handle, buffer.Handle)
+	})
+
+	t.Run("returns error listing candidates for ambiguous title", func(t *testing.T) {
+		first := filepath.Join(resolvePath(t, t.TempDir()), "ambiguous.go")
+		second := filepath.Join(resolvePath(t, t.TempDir()), "ambiguous.go")
+
+		_, err := client.OpenBuffer(ctx, first)
+		require.NoError(t, err)
+
+		_, err = client.OpenBuffer(ctx, second)
+		require.NoError(t, err)
+
+		_, err = client.GetBufferByTitle(ctx, "ambiguous.go")
+
+		var ambiguous *AmbiguousBufferError
+		require.ErrorAs(t, err, &ambiguous)
+		assert.ElementsMatch(t, []string{first, second}, ambiguous.Candidates)
+	})
+
 	t.Run("returns error for non-existent buffer", func(t *testing.T) {
 		_, err := client.GetBufferByTitle(ctx, "nonexistent-file-12345.txt")
 
@@ -336,7 +391,7 @@ func TestClient_GetBufferLines(t *testing.T) {
 		_, err := client.OpenBuffer(ctx, tmpFile)
 		require.NoError(t, err)
 
-		lines, err := client.GetBufferLines(ctx, filepath.Base(tmpFile), 1, 3)
+		lines, _, err := client.GetBufferLines(ctx, filepath.Base(tmpFile), 1, 3)
 
 		require.NoError(t, err)
 		assert.Equal(t, []string{"line1", "line2", "line3"}, lines)
@@ -348,14 +403,14 @@ func TestClient_GetBufferLines(t *testing.T) {
 		_, err := client.OpenBuffer(ctx, tmpFile)
 		require.NoError(t, err)
 
-		lines, err := client.GetBufferLines(ctx, filepath.Base(tmpFile), 2, 4)
+		lines, _, err := client.GetBufferLines(ctx, filepath.Base(tmpFile), 2, 4)
 
 		require.NoError(t, err)
 		assert.Equal(t, []string{"line2", "line3", "line4"}, lines)
 	})
 
 	t.Run("returns error for non-existent buffer", func(t *testing.T) {
-		_, err := client.GetBufferLines(ctx, "nonexistent.txt", 1, 5)
+		_, _, err := client.GetBufferLines(ctx, "nonexistent.txt", 1, 5)
 
 		assert.Error(t, err)
 	})
@@ -373,10 +428,10 @@ func TestClient_SetBufferLines(t *testing.T) {
 		_, err := client.OpenBuffer(ctx, tmpFile)
 		require.NoError(t, err)
 
-		err = client.SetBufferLines(ctx, filepath.Base(tmpFile), 2, 3, []string{"newline2", "newline3"})
+		_, err = client.SetBufferLines(ctx, filepath.Base(tmpFile), 2, 3, []string{"newline2", "newline3"}, 0)
 		require.NoError(t, err)
 
-		lines, err := client.GetBufferLines(ctx, filepath.Base(tmpFile), 1, 3)
+		lines, _, err := client.GetBufferLines(ctx, filepath.Base(tmpFile), 1, 3)
 
 		require.NoError(t, err)
 		assert.Equal(t, []string{"line1", "newline2", "newline3"}, lines)
@@ -389,15 +444,37 @@ func TestClient_SetBufferLines(t *testing.T) {
 		require.NoError(t, err)
 
 		// Replace line 2 (end is exclusive in 0-based, so 2,3 in 1-based means replace line 2)
-		err = client.SetBufferLines(ctx, filepath.Base(tmpFile), 2, 2, []string{"replaced"})
+		_, err = client.SetBufferLines(ctx, filepath.Base(tmpFile), 2, 2, []string{"replaced"}, 0)
 		require.NoError(t, err)
 
 		// Get the line we replaced
-		lines, err := client.GetBufferLines(ctx, filepath.Base(tmpFile), 2, 2)
+		lines, _, err := client.GetBufferLines(ctx, filepath.Base(tmpFile), 2, 2)
 
 		require.NoError(t, err)
 		assert.Equal(t, []string{"replaced"}, lines)
 	})
+
+	t.Run("checks the expected version", func(t *testing.T) {
+		tmpFile := createTempFile(t, "line1\nline2\nline3")
+
+		_, err := client.OpenBuffer(ctx, tmpFile)
+		require.NoError(t, err)
+
+		_, version, err := client.GetBufferLines(ctx, filepath.Base(tmpFile), 1, -1)
+		require.NoError(t, err)
+
+		newVersion, err := client.SetBufferLines(ctx, filepath.Base(tmpFile), 1, 1, []string{"first"}, version)
+		require.NoError(t, err)
+		assert.Greater(t, newVersion, version)
+
+		// the user typed in between, the stale version no longer applies
+		_, err = client.SetBufferLines(ctx, filepath.Base(tmpFile), 2, 3, []string{"x"}, version)
+
+		var conflict *VersionConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, newVersion, conflict.Current)
+		assert.Equal(t, []string{"line2", "line3"}, conflict.Lines)
+	})
 }
 
 func TestClient_DeleteLines(t *testing.T) {
@@ -412,37 +489,37 @@ func TestClient_DeleteLines(t *testing.T) {
 		_, err := client.OpenBuffer(ctx, tmpFile)
 		require.NoError(t, err)
 
-		err = client.DeleteLines(ctx, filepath.Base(tmpFile), 2, 3)
+		_, err = client.DeleteLines(ctx, filepath.Base(tmpFile), 2, 3, 0)
 		require.NoError(t, err)
 
-		lines, err := client.GetBufferLines(ctx, filepath.Base(tmpFile), 1, 2)
+		lines, _, err := client.GetBufferLines(ctx, filepath.Base(tmpFile), 1, 2)
 
 		require.NoError(t, err)
 		assert.Equal(t, []string{"line1", "line4"}, lines)
 	})
 }
 
-func TestClient_InsertText(t *testing.T) {
+func TestClient_SendInput(t *testing.T) {
 	client, cleanup := setupTestNeovim(t)
 	defer cleanup()
 
 	ctx := context.Background()
 
-	t.Run("inserts text", func(t *testing.T) {
+	t.Run("types keys", func(t *testing.T) {
 		tmpFile := createTempFile(t, "")
 
 		_, err := client.OpenBuffer(ctx, tmpFile)
 		require.NoError(t, err)
 
 		// Enter insert mode, type text, exit insert mode
-		err = client.InsertText(ctx, "ihello")
+		err = client.SendInput(ctx, "ihello")
 		require.NoError(t, err)
 
 		// Exit insert mode
-		err = client.InsertText(ctx, "\x1b") // ESC
+		err = client.SendInput(ctx, "\x1b") // ESC
 		require.NoError(t, err)
 
-		lines, err := client.GetBufferLines(ctx, filepath.Base(tmpFile), 1, 1)
+		lines, _, err := client.GetBufferLines(ctx, filepath.Base(tmpFile), 1, 1)
 
 		require.NoError(t, err)
 		assert.Contains(t, lines[0], "hello")
@@ -656,16 +733,19 @@ func TestClient_Search(t *testing.T) {
 		_, err := client.OpenBuffer(ctx, tmpFile)
 		require.NoError(t, err)
 
-		results, err := client.Search(ctx, "hello", "")
+		results, err := client.Search(ctx, "", "hello", types.SearchOptions{})
 
 		require.NoError(t, err)
-		require.NotEmpty(t, results)
-		// Verify we found matches with correct structure
-		for _, result := range results {
-			assert.Contains(t, result.MatchText, "hello")
-			assert.Greater(t, result.Line, 0)
-			assert.Greater(t, result.Column, 0)
+		require.Len(t, results.Matches, 2)
+		assert.False(t, results.Truncated)
+		assert.Positive(t, results.Version)
+		for _, result := range results.Matches {
+			assert.Equal(t, "hello", result.MatchText)
+			assert.Equal(t, 1, result.Column)
+			assert.Equal(t, 6, result.EndColumn)
 		}
+		assert.Equal(t, 1, results.Matches[0].Line)
+		assert.Equal(t, 3, results.Matches[1].Line)
 	})
 
 	t.Run("returns empty results when no match", func(t *testing.T) {
@@ -674,134 +754,167 @@ func TestClient_Search(t *testing.T) {
 		_, err := client.OpenBuffer(ctx, tmpFile)
 		require.NoError(t, err)
 
-		results, err := client.Search(ctx, "nonexistent", "")
+		results, err := client.Search(ctx, "", "nonexistent", types.SearchOptions{})
 
 		require.NoError(t, err)
-		assert.Empty(t, results)
+		assert.NotNil(t, results.Matches)
+		assert.Empty(t, results.Matches)
 	})
 
-	t.Run("finds pattern with case insensitive flag", func(t *testing.T) {
+	t.Run("ignores case when asked", func(t *testing.T) {
 		tmpFile := createTempFile(t, "Hello World\nhello world\nHELLO WORLD")
 
 		_, err := client.OpenBuffer(ctx, tmpFile)
 		require.NoError(t, err)
 
-		// Case-insensitive search using \c flag in pattern
-		results, err := client.Search(ctx, "\\chello", "")
+		results, err := client.Search(ctx, "", "hello", types.SearchOptions{IgnoreCase: true})
+
+		require.NoError(t, err)
+		assert.Len(t, results.Matches, 3)
+
+		results, err = client.Search(ctx, "", "hello", types.SearchOptions{})
 
 		require.NoError(t, err)
-		// Should find all 3 case variants
-		assert.GreaterOrEqual(t, len(results), 1)
+		assert.Len(t, results.Matches, 1)
 	})
 
-	t.Run("returns correct column positions", func(t *testing.T) {
+	t.Run("returns the span of the match", func(t *testing.T) {
 		tmpFile := createTempFile(t, "prefix_target_suffix")
 
 		_, err := client.OpenBuffer(ctx, tmpFile)
 		require.NoError(t, err)
 
-		results, err := client.Search(ctx, "target", "")
+		results, err := client.Search(ctx, "", "target", types.SearchOptions{})
 
 		require.NoError(t, err)
-		require.Len(t, results, 1)
-		assert.Equal(t, 1, results[0].Line)
-		assert.Equal(t, 8, results[0].Column) // "target" starts at column 8 (1-based)
-		assert.Contains(t, results[0].MatchText, "target")
+		require.Len(t, results.Matches, 1)
+		assert.Equal(t, types.SearchResult{Line: 1, Column: 8, EndLine: 1, EndColumn: 14, MatchText: "target"}, results.Matches[0])
 	})
 
-	t.Run("finds regex patterns", func(t *testing.T) {
+	t.Run("returns capture groups", func(t *testing.T) {
 		tmpFile := createTempFile(t, "test123\ntest456\nno match here")
 
 		_, err := client.OpenBuffer(ctx, tmpFile)
 		require.NoError(t, err)
 
-		results, err := client.Search(ctx, "test\\d\\+", "")
+		results, err := client.Search(ctx, "", `test\(\d\+\)`, types.SearchOptions{})
 
 		require.NoError(t, err)
-		// Should find matches for test followed by digits
-		require.NotEmpty(t, results)
-		for _, result := range results {
-			assert.Contains(t, result.MatchText, "test")
-		}
+		require.Len(t, results.Matches, 2)
+		assert.Equal(t, "test123", results.Matches[0].MatchText)
+		assert.Equal(t, []string{"123"}, results.Matches[0].Groups)
+		assert.Equal(t, []string{"456"}, results.Matches[1].Groups)
 	})
 
-	t.Run("handles special characters in pattern", func(t *testing.T) {
-		tmpFile := createTempFile(t, "foo.bar\nbaz")
+	t.Run("matches literal strings", func(t *testing.T) {
+		tmpFile := createTempFile(t, "foo.bar\nfooxbar\nsay \"hello\" please")
 
 		_, err := client.OpenBuffer(ctx, tmpFile)
 		require.NoError(t, err)
 
-		// Pattern with escaped dot (literal match)
-		results, err := client.Search(ctx, "foo\\.bar", "")
+		results, err := client.Search(ctx, "", "foo.bar", types.SearchOptions{Flavor: types.SearchFlavorLiteral})
+
+		require.NoError(t, err)
+		require.Len(t, results.Matches, 1)
+		assert.Equal(t, "foo.bar", results.Matches[0].MatchText)
+
+		results, err = client.Search(ctx, "", `"hello"`, types.SearchOptions{Flavor: types.SearchFlavorLiteral})
 
 		require.NoError(t, err)
-		require.Len(t, results, 1)
-		assert.Equal(t, "foo.bar", results[0].MatchText)
+		require.Len(t, results.Matches, 1)
+		assert.Equal(t, 3, results.Matches[0].Line)
+		assert.Equal(t, 5, results.Matches[0].Column)
 	})
 
-	t.Run("handles quotes in pattern", func(t *testing.T) {
-		tmpFile := createTempFile(t, `say "hello" please`)
+	t.Run("matches RE2 regexes across lines", func(t *testing.T) {
+		tmpFile := createTempFile(t, "func a() {\n}\nfunc b() {\n}")
 
 		_, err := client.OpenBuffer(ctx, tmpFile)
 		require.NoError(t, err)
 
-		// Pattern with quotes (exercises Lua string escaping in fmt.Sprintf %q)
-		results, err := client.Search(ctx, `"hello"`, "")
+		results, err := client.Search(ctx, "", `^func (\w+)\(\) \{\n\}`, types.SearchOptions{Flavor: types.SearchFlavorRE2})
 
 		require.NoError(t, err)
-		require.Len(t, results, 1)
-		assert.Contains(t, results[0].MatchText, `"hello"`)
+		require.Len(t, results.Matches, 2)
+		assert.Equal(t, types.SearchResult{Line: 3, Column: 1, EndLine: 4, EndColumn: 2, MatchText: "func b() {\n}", Groups: []string{"b"}}, results.Matches[1])
 	})
 
-	t.Run("finds multiple matches on different lines", func(t *testing.T) {
+	t.Run("limits and flags truncated results", func(t *testing.T) {
 		tmpFile := createTempFile(t, "match one\nmatch two\nmatch three\nother four")
 
 		_, err := client.OpenBuffer(ctx, tmpFile)
 		require.NoError(t, err)
 
-		results, err := client.Search(ctx, "match", "")
+		results, err := client.Search(ctx, "", "match", types.SearchOptions{MaxResults: 2})
 
 		require.NoError(t, err)
-		// Verify we get multiple matches
-		assert.GreaterOrEqual(t, len(results), 1)
-		// Each result should contain the pattern
-		for _, result := range results {
-			assert.Contains(t, result.MatchText, "match")
-		}
+		assert.Len(t, results.Matches, 2)
+		assert.True(t, results.Truncated)
+
+		results, err = client.Search(ctx, "", "match", types.SearchOptions{MaxResults: 3})
+
+		require.NoError(t, err)
+		assert.Len(t, results.Matches, 3)
+		assert.False(t, results.Truncated)
 	})
 
-	t.Run("populates all SearchResult fields", func(t *testing.T) {
-		tmpFile := createTempFile(t, "find me here")
+	t.Run("returns context lines", func(t *testing.T) {
+		tmpFile := createTempFile(t, "one\ntwo\nfind me here\nfour\nfive")
 
 		_, err := client.OpenBuffer(ctx, tmpFile)
 		require.NoError(t, err)
 
-		results, err := client.Search(ctx, "me", "")
+		results, err := client.Search(ctx, "", "me", types.SearchOptions{ContextLines: 1})
 
 		require.NoError(t, err)
-		require.Len(t, results, 1)
-		// Verify all fields are populated correctly
-		assert.Equal(t, 1, results[0].Line)
-		assert.Equal(t, 6, results[0].Column) // "me" starts at column 6
-		assert.Equal(t, "find me here", results[0].MatchText)
+		require.Len(t, results.Matches, 1)
+		assert.Equal(t, 6, results.Matches[0].Column)
+		assert.Equal(t, "me", results.Matches[0].MatchText)
+		assert.Equal(t, []string{"two"}, results.Matches[0].Before)
+		assert.Equal(t, []string{"four"}, results.Matches[0].After)
 	})
 
-	t.Run("iterates through all results", func(t *testing.T) {
-		// This test specifically exercises the for loop that iterates through results
-		tmpFile := createTempFile(t, "aaa\nbbb\naaa")
+	t.Run("searches another buffer without moving the cursor", func(t *testing.T) {
+		other, err := client.OpenBuffer(ctx, createTempFile(t, "alpha\nbeta\nalpha"))
+		require.NoError(t, err)
 
-		_, err := client.OpenBuffer(ctx, tmpFile)
+		current, err := client.OpenBuffer(ctx, createTempFile(t, "one\ntwo\nthree"))
 		require.NoError(t, err)
+		require.NoError(t, client.SetCursorPosition(ctx, 2, 1))
 
-		results, err := client.Search(ctx, "aaa", "")
+		_, err = client.ExecCommand(ctx, "let @/ = 'two'")
+		require.NoError(t, err)
 
+		results, err := client.Search(ctx, other.Path, "alpha", types.SearchOptions{})
 		require.NoError(t, err)
-		// Verify we iterate through results and each has correct types
-		for _, result := range results {
-			assert.IsType(t, 0, result.Line)
-			assert.IsType(t, 0, result.Column)
-			assert.IsType(t, "", result.MatchText)
-		}
+		assert.Len(t, results.Matches, 2)
+
+		results, err = client.Search(ctx, "", "three", types.SearchOptions{})
+		require.NoError(t, err)
+		assert.Len(t, results.Matches, 1)
+
+		buf, err := client.GetCurrentBuffer(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, current.Handle, buf.Handle)
+
+		pos, err := client.GetCursorPosition(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, pos.Line)
+
+		register, err := client.ExecLua(ctx, "return vim.fn.getreg('/')", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "two", register)
+	})
+
+	t.Run("rejects invalid patterns", func(t *testing.T) {
+		_, err := client.Search(ctx, "", "", types.SearchOptions{})
+		assert.ErrorIs(t, err, ErrInvalidPattern)
+
+		_, err = client.Search(ctx, "", "(", types.SearchOptions{Flavor: types.SearchFlavorRE2})
+		assert.ErrorIs(t, err, ErrInvalidPattern)
+
+		_, err = client.Search(ctx, "", "a", types.SearchOptions{Flavor: "pcre"})
+		assert.ErrorIs(t, err, ErrInvalidPattern)
 	})
 }
 
@@ -913,7 +1026,7 @@ func TestClient_ExecLua(t *testing.T) {
 		require.NoError(t, err)
 
 		// Verify the modification
-		lines, err := client.GetBufferLines(ctx, filepath.Base(tmpFile), 1, 1)
+		lines, _, err := client.GetBufferLines(ctx, filepath.Base(tmpFile), 1, 1)
 		require.NoError(t, err)
 		assert.Equal(t, []string{"modified via lua"}, lines)
 	})
@@ -992,328 +1105,3 @@ func TestClient_Close(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
-
-// ----------------------------------------------------------------------------
-//
-// --- Compile-time verification that MockClient implements types.NeovimClient ---
-
-var _ types.NeovimClient = (*MockClient)(nil)
-
-// MockClient is a mock implementation of types.NeovimClient for testing
-type MockClient struct {
-	mock.Mock
-}
-
-// NewMockClient creates a new MockClient instance
-func NewMockClient() *MockClient {
-	return &MockClient{}
-}
-
-// GetBuffers returns a list of all open buffers
-func (m *MockClient) GetBuffers(ctx context.Context) ([]types.BufferInfo, error) {
-	args := m.Called(ctx)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]types.BufferInfo), args.Error(1)
-}
-
-// GetBufferByTitle finds a buffer by its title
-func (m *MockClient) GetBufferByTitle(ctx context.Context, title string) (types.BufferInfo, error) {
-	args := m.Called(ctx, title)
-	return args.Get(0).(types.BufferInfo), args.Error(1)
-}
-
-// GetCurrentBuffer returns the currently active buffer
-func (m *MockClient) GetCurrentBuffer(ctx context.Context) (types.BufferInfo, error) {
-	args := m.Called(ctx)
-	if args.Get(0) == nil {
-		return types.BufferInfo{}, args.Error(1)
-	}
-	return args.Get(0).(types.BufferInfo), args.Error(1)
-}
-
-// OpenBuffer opens a file in a new buffer
-func (m *MockClient) OpenBuffer(ctx context.Context, path string) (types.BufferInfo, error) {
-	args := m.Called(ctx, path)
-	if args.Get(0) == nil {
-		return types.BufferInfo{}, args.Error(1)
-	}
-	return args.Get(0).(types.BufferInfo), args.Error(1)
-}
-
-// CloseBuffer closes a buffer by title
-func (m *MockClient) CloseBuffer(ctx context.Context, title string) error {
-	args := m.Called(ctx, title)
-	return args.Error(0)
-}
-
-// SwitchBuffer switches to a buffer by title
-func (m *MockClient) SwitchBuffer(ctx context.Context, title string) error {
-	args := m.Called(ctx, title)
-	return args.Error(0)
-}
-
-// GetBufferLines retrieves lines from a buffer
-func (m *MockClient) GetBufferLines(ctx context.Context, title string, start, end int) ([]string, error) {
-	args := m.Called(ctx, title, start, end)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]string), args.Error(1)
-}
-
-// SetBufferLines sets lines in a buffer
-func (m *MockClient) SetBufferLines(ctx context.Context, title string, start, end int, lines []string) error {
-	args := m.Called(ctx, title, start, end, lines)
-	return args.Error(0)
-}
-
-// InsertText inserts text at the current cursor position
-func (m *MockClient) InsertText(ctx context.Context, text string) error {
-	args := m.Called(ctx, text)
-	return args.Error(0)
-}
-
-// DeleteLines deletes lines from a buffer
-func (m *MockClient) DeleteLines(ctx context.Context, title string, start, end int) error {
-	args := m.Called(ctx, title, start, end)
-	return args.Error(0)
-}
-
-// GetCursorPosition returns the current cursor position
-func (m *MockClient) GetCursorPosition(ctx context.Context) (types.CursorPosition, error) {
-	args := m.Called(ctx)
-	if args.Get(0) == nil {
-		return types.CursorPosition{}, args.Error(1)
-	}
-	return args.Get(0).(types.CursorPosition), args.Error(1)
-}
-
-// SetCursorPosition sets the cursor position
-func (m *MockClient) SetCursorPosition(ctx context.Context, line, col int) error {
-	args := m.Called(ctx, line, col)
-	return args.Error(0)
-}
-
-// GotoLine moves the cursor to a specific line
-func (m *MockClient) GotoLine(ctx context.Context, line int) error {
-	args := m.Called(ctx, line)
-	return args.Error(0)
-}
-
-// Search searches for a pattern in the current buffer
-func (m *MockClient) Search(ctx context.Context, pattern string, flags string) ([]types.SearchResult, error) {
-	args := m.Called(ctx, pattern, flags)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]types.SearchResult), args.Error(1)
-}
-
-// GetWindows returns information about all windows
-func (m *MockClient) GetWindows(ctx context.Context) ([]types.WindowInfo, error) {
-	args := m.Called(ctx)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]types.WindowInfo), args.Error(1)
-}
-
-// SplitWindow creates a new window split
-func (m *MockClient) SplitWindow(ctx context.Context, direction string, bufferTitle string) (types.WindowInfo, error) {
-	args := m.Called(ctx, direction, bufferTitle)
-	if args.Get(0) == nil {
-		return types.WindowInfo{}, args.Error(1)
-	}
-	return args.Get(0).(types.WindowInfo), args.Error(1)
-}
-
-// CloseWindow closes a window by ID
-func (m *MockClient) CloseWindow(ctx context.Context, windowID int) error {
-	args := m.Called(ctx, windowID)
-	return args.Error(0)
-}
-
-// ResizeWindow resizes a window
-func (m *MockClient) ResizeWindow(ctx context.Context, windowID, width, height int) error {
-	args := m.Called(ctx, windowID, width, height)
-	return args.Error(0)
-}
-
-// ExecCommand executes a Vim Ex command
-func (m *MockClient) ExecCommand(ctx context.Context, command string) (string, error) {
-	args := m.Called(ctx, command)
-	return args.String(0), args.Error(1)
-}
-
-// ExecLua executes Lua code
-func (m *MockClient) ExecLua(ctx context.Context, code string, luaArgs []any) (any, error) {
-	args := m.Called(ctx, code, luaArgs)
-	return args.Get(0), args.Error(1)
-}
-
-// CallFunction calls a Vim/Neovim function
-func (m *MockClient) CallFunction(ctx context.Context, fname string, fnArgs []any) (any, error) {
-	args := m.Called(ctx, fname, fnArgs)
-	return args.Get(0), args.Error(1)
-}
-
-// Close closes the Neovim connection
-func (m *MockClient) Close() error {
-	args := m.Called()
-	return args.Error(0)
-}
-
-// --- Test Helper Functions ---
-
-// MockBufferInfo creates a BufferInfo for testing
-func MockBufferInfo(handle int, title, name string) types.BufferInfo {
-	return types.BufferInfo{
-		Handle:    nvim.Buffer(handle),
-		Title:     title,
-		Path:      name,
-		Loaded:    true,
-		Changed:   false,
-		LineCount: 100,
-	}
-}
-
-// MockWindowInfo creates a WindowInfo for testing
-func MockWindowInfo(handle int, buffer types.BufferInfo, width, height int) types.WindowInfo {
-	return types.WindowInfo{
-		Handle: nvim.Window(handle),
-		Buffer: buffer,
-		Width:  width,
-		Height: height,
-	}
-}
-
-// MockCursorPosition creates a CursorPosition for testing
-func MockCursorPosition(line, col int) types.CursorPosition {
-	return types.CursorPosition{
-		Line:   line,
-		Column: col,
-	}
-}
-
-// MockSearchResult creates a SearchResult for testing
-func MockSearchResult(line, col int, matchText string) types.SearchResult {
-	return types.SearchResult{
-		Line:      line,
-		Column:    col,
-		MatchText: matchText,
-	}
-}
-
-// --- Setup Helper Methods ---
-
-// SetupGetBuffers configures the mock to return the given buffers
-func (m *MockClient) SetupGetBuffers(buffers []types.BufferInfo, err error) *mock.Call {
-	return m.On("GetBuffers", mock.Anything).Return(buffers, err)
-}
-
-// SetupGetCurrentBuffer configures the mock to return the given buffer
-func (m *MockClient) SetupGetCurrentBuffer(buffer types.BufferInfo, err error) *mock.Call {
-	return m.On("GetCurrentBuffer", mock.Anything).Return(buffer, err)
-}
-
-// SetupOpenBuffer configures the mock to return the given buffer when opening a path
-func (m *MockClient) SetupOpenBuffer(path string, buffer types.BufferInfo, err error) *mock.Call {
-	return m.On("OpenBuffer", mock.Anything, path).Return(buffer, err)
-}
-
-// SetupCloseBuffer configures the mock for closing a buffer
-func (m *MockClient) SetupCloseBuffer(title string, err error) *mock.Call {
-	return m.On("CloseBuffer", mock.Anything, title).Return(err)
-}
-
-// SetupSwitchBuffer configures the mock for switching buffers
-func (m *MockClient) SetupSwitchBuffer(title string, err error) *mock.Call {
-	return m.On("SwitchBuffer", mock.Anything, title).Return(err)
-}
-
-// SetupGetBufferByTitle configures the mock to return a buffer handle
-func (m *MockClient) SetupGetBufferByTitle(title string, handle nvim.Buffer, err error) *mock.Call {
-	return m.On("GetBufferByTitle", mock.Anything, title).Return(handle, err)
-}
-
-// SetupGetBufferLines configures the mock to return lines from a buffer
-func (m *MockClient) SetupGetBufferLines(title string, start, end int, lines []string, err error) *mock.Call {
-	return m.On("GetBufferLines", mock.Anything, title, start, end).Return(lines, err)
-}
-
-// SetupSetBufferLines configures the mock for setting buffer lines
-func (m *MockClient) SetupSetBufferLines(title string, start, end int, lines []string, err error) *mock.Call {
-	return m.On("SetBufferLines", mock.Anything, title, start, end, lines).Return(err)
-}
-
-// SetupInsertText configures the mock for inserting text
-func (m *MockClient) SetupInsertText(text string, err error) *mock.Call {
-	return m.On("InsertText", mock.Anything, text).Return(err)
-}
-
-// SetupDeleteLines configures the mock for deleting lines
-func (m *MockClient) SetupDeleteLines(title string, start, end int, err error) *mock.Call {
-	return m.On("DeleteLines", mock.Anything, title, start, end).Return(err)
-}
-
-// SetupGetCursorPosition configures the mock to return a cursor position
-func (m *MockClient) SetupGetCursorPosition(pos types.CursorPosition, err error) *mock.Call {
-	return m.On("GetCursorPosition", mock.Anything).Return(pos, err)
-}
-
-// SetupSetCursorPosition configures the mock for setting cursor position
-func (m *MockClient) SetupSetCursorPosition(line, col int, err error) *mock.Call {
-	return m.On("SetCursorPosition", mock.Anything, line, col).Return(err)
-}
-
-// SetupGotoLine configures the mock for going to a line
-func (m *MockClient) SetupGotoLine(line int, err error) *mock.Call {
-	return m.On("GotoLine", mock.Anything, line).Return(err)
-}
-
-// SetupSearch configures the mock to return search results
-func (m *MockClient) SetupSearch(pattern, flags string, results []types.SearchResult, err error) *mock.Call {
-	return m.On("Search", mock.Anything, pattern, flags).Return(results, err)
-}
-
-// SetupGetWindows configures the mock to return windows
-func (m *MockClient) SetupGetWindows(windows []types.WindowInfo, err error) *mock.Call {
-	return m.On("GetWindows", mock.Anything).Return(windows, err)
-}
-
-// SetupSplitWindow configures the mock for splitting windows
-func (m *MockClient) SetupSplitWindow(direction, bufferTitle string, window types.WindowInfo, err error) *mock.Call {
-	return m.On("SplitWindow", mock.Anything, direction, bufferTitle).Return(window, err)
-}
-
-// SetupCloseWindow configures the mock for closing a window
-func (m *MockClient) SetupCloseWindow(windowID int, err error) *mock.Call {
-	return m.On("CloseWindow", mock.Anything, windowID).Return(err)
-}
-
-// SetupResizeWindow configures the mock for resizing a window
-func (m *MockClient) SetupResizeWindow(windowID, width, height int, err error) *mock.Call {
-	return m.On("ResizeWindow", mock.Anything, windowID, width, height).Return(err)
-}
-
-// SetupExecCommand configures the mock for executing commands
-func (m *MockClient) SetupExecCommand(command, output string, err error) *mock.Call {
-	return m.On("ExecCommand", mock.Anything, command).Return(output, err)
-}
-
-// SetupExecLua configures the mock for executing Lua code
-func (m *MockClient) SetupExecLua(code string, luaArgs []any, result any, err error) *mock.Call {
-	return m.On("ExecLua", mock.Anything, code, luaArgs).Return(result, err)
-}
-
-// SetupCallFunction configures the mock for calling functions
-func (m *MockClient) SetupCallFunction(fname string, fnArgs []any, result any, err error) *mock.Call {
-	return m.On("CallFunction", mock.Anything, fname, fnArgs).Return(result, err)
-}
-
-// SetupClose configures the mock for closing the client
-func (m *MockClient) SetupClose(err error) *mock.Call {
-	return m.On("Close").Return(err)
-}
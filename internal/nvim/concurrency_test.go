@@ -0,0 +1,414 @@
+package nvim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+const (
+	// stressWorkers is the number of goroutines calling each client method
+	stressWorkers = 4
+	// stressIterations is the number of calls each goroutine makes
+	stressIterations = 10
+)
+
+// stressOp is a client call exercised by the stress tests, strict ops must never fail
+type stressOp struct {
+	name   string
+	strict bool
+	call   func(ctx context.Context, worker, iteration int) error
+}
+
+// runStress calls every op from stressWorkers goroutines in parallel and collects
+// the errors returned by strict ops
+func runStress(t *testing.T, ops []stressOp) {
+	t.Helper()
+
+	ctx := context.Background()
+	errs := make(chan error, len(ops)*stressWorkers*stressIterations)
+
+	var wg sync.WaitGroup
+	for _, op := range ops {
+		for worker := range stressWorkers {
+			wg.Go(func() {
+				for iteration := range stressIterations {
+					err := op.call(ctx, worker, iteration)
+					if err != nil && op.strict {
+						errs <- fmt.Errorf("%s (worker %d, iteration %d): %w", op.name, worker, iteration, err)
+					}
+				}
+			})
+		}
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+}
+
+// --- Concurrency Tests ---
+
+func TestClient_ConcurrentUse(t *testing.T) {
+	client, cleanup := setupTestNeovim(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// stable buffer that is read and written by every worker but never closed
+	stableFile := createTempFile(t, "line1\nline2\nline3\nline4\nline5")
+	stable, err := client.OpenBuffer(ctx, stableFile)
+	require.NoError(t, err)
+
+	stableRef := strconv.Itoa(int(stable.Handle))
+	scratchDir := resolvePath(t, t.TempDir())
+
+	scratchFile := func(worker, iteration int) string {
+		return filepath.Join(scratchDir, fmt.Sprintf("scratch-%d-%d.txt", worker, iteration))
+	}
+
+	// each worker edits its own file per operation, so version checks never conflict
+	editFiles := make(map[string]string)
+	for _, op := range []string{"InsertText", "ReplaceText", "SetText", "ApplyEdits", "ApplyPatch", "SetReindentedLines"} {
+		for worker := range stressWorkers {
+			path := resolvePath(t, createTempFile(t, "one\ntwo"))
+			_, err := client.OpenBuffer(ctx, path)
+			require.NoError(t, err)
+			editFiles[fmt.Sprintf("%s-%d", op, worker)] = path
+		}
+	}
+
+	editFile := func(op string, worker int) string {
+		return editFiles[fmt.Sprintf("%s-%d", op, worker)]
+	}
+
+	// toggle returns the text an iteration replaces and its replacement, the odd
+	// iterations undo the even ones
+	toggle := func(iteration int, from, to string) (string, string) {
+		if iteration%2 == 1 {
+			return to, from
+		}
+		return from, to
+	}
+
+	// anchors resolved by every worker, separate from those CreateAnchor replaces
+	for worker := range stressWorkers {
+		_, err := client.CreateAnchor(ctx, fmt.Sprintf("r%d", worker), stableRef, types.TextRange{StartLine: 1, EndLine: 1})
+		require.NoError(t, err)
+	}
+
+	// without a language server the lsp methods must fail with ErrNoLanguageServer only
+	withoutServer := func(err error) error {
+		if errors.Is(err, ErrNoLanguageServer) {
+			return nil
+		}
+		return err
+	}
+
+	grepDir := resolvePath(t, t.TempDir())
+	for worker := range stressWorkers {
+		dir := filepath.Join(grepDir, strconv.Itoa(worker))
+		require.NoError(t, os.Mkdir(dir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "find.txt"), []byte("foo\n"), 0o600))
+	}
+
+	ops := []stressOp{
+		{name: "GetBuffers", strict: true, call: func(ctx context.Context, _, _ int) error {
+			_, err := client.GetBuffers(ctx)
+			return err
+		}},
+		{name: "GetBufferByTitle", strict: true, call: func(ctx context.Context, _, _ int) error {
+			_, err := client.GetBufferByTitle(ctx, stable.Path)
+			return err
+		}},
+		{name: "GetCurrentBuffer", strict: true, call: func(ctx context.Context, _, _ int) error {
+			_, err := client.GetCurrentBuffer(ctx)
+			return err
+		}},
+		{name: "OpenBuffer", strict: true, call: func(ctx context.Context, worker, iteration int) error {
+			buf, err := client.OpenBuffer(ctx, scratchFile(worker, iteration))
+			if err != nil {
+				return err
+			}
+			if buf.Path != scratchFile(worker, iteration) {
+				return fmt.Errorf("opened %s, got %s", scratchFile(worker, iteration), buf.Path)
+			}
+			return nil
+		}},
+		{name: "CloseBuffer", call: func(ctx context.Context, worker, iteration int) error {
+			return client.CloseBuffer(ctx, scratchFile(worker, iteration))
+		}},
+		{name: "SwitchBuffer", strict: true, call: func(ctx context.Context, _, _ int) error {
+			return client.SwitchBuffer(ctx, stableRef)
+		}},
+		{name: "GetBufferLines", strict: true, call: func(ctx context.Context, _, _ int) error {
+			_, _, err := client.GetBufferLines(ctx, stableRef, 1, 1)
+			return err
+		}},
+		{name: "SetBufferLines", strict: true, call: func(ctx context.Context, worker, iteration int) error {
+			_, err := client.SetBufferLines(ctx, stableRef, 1, 1, []string{fmt.Sprintf("w%d-i%d", worker, iteration)}, 0)
+			return err
+		}},
+		{name: "SendInput", call: func(ctx context.Context, _, _ int) error {
+			return client.SendInput(ctx, "\x1b")
+		}},
+		{name: "DeleteLines", strict: true, call: func(ctx context.Context, _, _ int) error {
+			// delete a line that is re-added right after to keep the stable buffer long enough
+			if _, err := client.SetBufferLines(ctx, stableRef, 2, 1, []string{"extra"}, 0); err != nil {
+				return err
+			}
+			_, err := client.DeleteLines(ctx, stableRef, 2, 2, 0)
+			return err
+		}},
+		{name: "GetCursorPosition", strict: true, call: func(ctx context.Context, _, _ int) error {
+			_, err := client.GetCursorPosition(ctx)
+			return err
+		}},
+		{name: "SetCursorPosition", call: func(ctx context.Context, _, _ int) error {
+			return client.SetCursorPosition(ctx, 1, 1)
+		}},
+		{name: "GotoLine", call: func(ctx context.Context, _, _ int) error {
+			return client.GotoLine(ctx, 1)
+		}},
+		{name: "Search", strict: true, call: func(ctx context.Context, _, _ int) error {
+			_, err := client.Search(ctx, stableRef, "line", types.SearchOptions{})
+			return err
+		}},
+		{name: "GetWindows", strict: true, call: func(ctx context.Context, _, _ int) error {
+			_, err := client.GetWindows(ctx)
+			return err
+		}},
+		{name: "SplitWindow+CloseWindow", call: func(ctx context.Context, worker, _ int) error {
+			direction := SplitDirectionHorizontal
+			if worker%2 == 0 {
+				direction = SplitDirectionVertical
+			}
+			win, err := client.SplitWindow(ctx, direction, "")
+			if err != nil {
+				return err
+			}
+			return client.CloseWindow(ctx, int(win.Handle))
+		}},
+		{name: "ResizeWindow", call: func(ctx context.Context, _, _ int) error {
+			windows, err := client.GetWindows(ctx)
+			if err != nil || len(windows) == 0 {
+				return err
+			}
+			return client.ResizeWindow(ctx, int(windows[0].Handle), 20, 5)
+		}},
+		{name: "ExecCommand", strict: true, call: func(ctx context.Context, _, _ int) error {
+			_, err := client.ExecCommand(ctx, "echo 'stress'")
+			return err
+		}},
+		{name: "ExecLua", strict: true, call: func(ctx context.Context, worker, _ int) error {
+			out, err := client.ExecLua(ctx, "return ...", []any{worker})
+			if err != nil {
+				return err
+			}
+			if out != int64(worker) {
+				return fmt.Errorf("expected %d, got %v", worker, out)
+			}
+			return nil
+		}},
+		{name: "CallFunction", strict: true, call: func(ctx context.Context, _, _ int) error {
+			_, err := client.CallFunction(ctx, "abs", []any{-1})
+			return err
+		}},
+		{name: "GetDiagnostics", strict: true, call: func(ctx context.Context, _, _ int) error {
+			_, err := client.GetDiagnostics(ctx, "")
+			return err
+		}},
+		{name: "InsertText", strict: true, call: func(ctx context.Context, worker, _ int) error {
+			_, err := client.InsertText(ctx, editFile("InsertText", worker), "x", types.InsertTextOptions{Line: 2, Column: 1})
+			return err
+		}},
+		{name: "ReplaceText", strict: true, call: func(ctx context.Context, worker, iteration int) error {
+			oldText, newText := toggle(iteration, "one", "uno")
+			_, err := client.ReplaceText(ctx, editFile("ReplaceText", worker), oldText, newText, types.ReplaceTextOptions{})
+			return err
+		}},
+		{name: "SetText", strict: true, call: func(ctx context.Context, worker, iteration int) error {
+			rng := types.TextRange{StartLine: 1, StartColumn: 1, EndLine: 1, EndColumn: 2}
+			_, _, err := client.SetText(ctx, editFile("SetText", worker), rng, strconv.Itoa(iteration), "", 0)
+			return err
+		}},
+		{name: "GetText", strict: true, call: func(ctx context.Context, _, _ int) error {
+			_, _, err := client.GetText(ctx, stableRef, types.TextRange{StartLine: 1, StartColumn: 1, EndLine: 2, EndColumn: 1}, "")
+			return err
+		}},
+		{name: "SetReindentedLines", strict: true, call: func(ctx context.Context, worker, iteration int) error {
+			_, _, err := client.SetReindentedLines(ctx, editFile("SetReindentedLines", worker), 2, 2, []string{"  " + strconv.Itoa(iteration)}, 0)
+			return err
+		}},
+		{name: "ApplyEdits", strict: true, call: func(ctx context.Context, worker, iteration int) error {
+			path := editFile("ApplyEdits", worker)
+			_, err := client.ApplyEdits(ctx, []types.BufferEdit{
+				{BufferTitle: path, Kind: types.EditKindLines, StartLine: 1, EndLine: 1, Lines: []string{strconv.Itoa(iteration)}},
+				{BufferTitle: path, Kind: types.EditKindRange, Range: &types.TextRange{StartLine: 2, StartColumn: 1, EndLine: 2, EndColumn: 2}, Text: "t"},
+			})
+			return err
+		}},
+		{name: "ApplyPatch", strict: true, call: func(ctx context.Context, worker, iteration int) error {
+			path := editFile("ApplyPatch", worker)
+			oldLine, newLine := toggle(iteration, "one", "uno")
+			result, err := client.ApplyPatch(ctx, "--- "+path+"\n+++ "+path+"\n@@ -1,1 +1,1 @@\n-"+oldLine+"\n+"+newLine+"\n", nil)
+			if err != nil {
+				return err
+			}
+			if result.Rejected > 0 {
+				return fmt.Errorf("rejected %d hunks of %s", result.Rejected, path)
+			}
+			return nil
+		}},
+		{name: "FeedKeys", call: func(ctx context.Context, _, _ int) error {
+			_, err := client.FeedKeys(ctx, "0", types.FeedKeysOptions{})
+			return err
+		}},
+		{name: "CreateAnchor", strict: true, call: func(ctx context.Context, worker, _ int) error {
+			_, err := client.CreateAnchor(ctx, fmt.Sprintf("w%d", worker), stableRef, types.TextRange{StartLine: 1, EndLine: 2})
+			return err
+		}},
+		{name: "ResolveAnchor", strict: true, call: func(ctx context.Context, worker, _ int) error {
+			_, err := client.ResolveAnchor(ctx, fmt.Sprintf("r%d", worker))
+			return err
+		}},
+		{name: "GrepProject", strict: true, call: func(ctx context.Context, _, _ int) error {
+			_, err := client.GrepProject(ctx, "foo|bar", types.GrepOptions{Root: grepDir})
+			return err
+		}},
+		{name: "PreviewFindReplace+ApplyFindReplace", strict: true, call: func(ctx context.Context, worker, iteration int) error {
+			opts := types.GrepOptions{Root: filepath.Join(grepDir, strconv.Itoa(worker))}
+			pattern, replacement := toggle(iteration, "foo", "bar")
+
+			preview, err := client.PreviewFindReplace(ctx, pattern, replacement, opts)
+			if err != nil {
+				return err
+			}
+			if len(preview.Matches) != 1 {
+				return fmt.Errorf("expected 1 match of %s, got %d", pattern, len(preview.Matches))
+			}
+
+			result, err := client.ApplyFindReplace(ctx, pattern, replacement, []string{preview.Matches[0].ID}, opts)
+			if err != nil {
+				return err
+			}
+			if result.Replaced != 1 {
+				return fmt.Errorf("expected 1 replacement of %s, got %d", pattern, result.Replaced)
+			}
+			return nil
+		}},
+		{name: "WatchDiagnostics", strict: true, call: func(ctx context.Context, _, _ int) error {
+			stop, err := client.WatchDiagnostics(ctx, func() {})
+			if err != nil {
+				return err
+			}
+			return stop(ctx)
+		}},
+		{name: "FindLocations", strict: true, call: func(ctx context.Context, _, _ int) error {
+			_, err := client.FindLocations(ctx, types.LocationDefinition, stableRef, types.LocationOptions{Line: 1, Column: 1})
+			return withoutServer(err)
+		}},
+		{name: "Hover", strict: true, call: func(ctx context.Context, _, _ int) error {
+			_, err := client.Hover(ctx, stableRef, types.LocationOptions{Line: 1, Column: 1})
+			return withoutServer(err)
+		}},
+		{name: "SignatureHelp", strict: true, call: func(ctx context.Context, _, _ int) error {
+			_, err := client.SignatureHelp(ctx, stableRef, types.LocationOptions{Line: 1, Column: 1})
+			return withoutServer(err)
+		}},
+		{name: "GetConnectionStatus", strict: true, call: func(ctx context.Context, _, _ int) error {
+			status, err := client.GetConnectionStatus(ctx)
+			if err != nil {
+				return err
+			}
+			if status.State != ConnectionStateConnected {
+				return fmt.Errorf("expected state %s, got %s", ConnectionStateConnected, status.State)
+			}
+			return nil
+		}},
+		{name: "GetEditorConfig", strict: true, call: func(ctx context.Context, _, _ int) error {
+			_, err := client.GetEditorConfig(ctx)
+			return err
+		}},
+		{name: "GetPlugins", strict: true, call: func(ctx context.Context, _, _ int) error {
+			_, err := client.GetPlugins(ctx)
+			return err
+		}},
+	}
+
+	t.Run("covers every client method", func(t *testing.T) {
+		covered := map[string]bool{"Close": true}
+		for _, op := range ops {
+			for name := range strings.SplitSeq(op.name, "+") {
+				covered[name] = true
+			}
+		}
+
+		clientType := reflect.TypeFor[*Client]()
+		for i := range clientType.NumMethod() {
+			assert.True(t, covered[clientType.Method(i).Name], "%s is not exercised", clientType.Method(i).Name)
+		}
+	})
+
+	runStress(t, ops)
+
+	t.Run("client remains usable", func(t *testing.T) {
+		lines, _, err := client.GetBufferLines(ctx, stableRef, 1, 5)
+
+		require.NoError(t, err)
+		assert.Len(t, lines, 5)
+	})
+}
+
+func TestClient_ConcurrentBufferLookups(t *testing.T) {
+	client, cleanup := setupTestNeovim(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	tmpDir := resolvePath(t, t.TempDir())
+
+	// lookups of a stable buffer must succeed while other buffers come and go
+	stable, err := client.OpenBuffer(ctx, filepath.Join(tmpDir, "stable.txt"))
+	require.NoError(t, err)
+
+	ops := []stressOp{
+		{name: "churn", call: func(ctx context.Context, worker, iteration int) error {
+			path := filepath.Join(tmpDir, fmt.Sprintf("churn-%d-%d.txt", worker, iteration))
+			if _, err := client.OpenBuffer(ctx, path); err != nil {
+				return err
+			}
+			return client.CloseBuffer(ctx, path)
+		}},
+		{name: "lookup", strict: true, call: func(ctx context.Context, _, _ int) error {
+			buf, err := client.GetBufferByTitle(ctx, "stable.txt")
+			if err != nil {
+				return err
+			}
+			if buf.Handle != stable.Handle {
+				return fmt.Errorf("expected buffer %d, got %d", stable.Handle, buf.Handle)
+			}
+			return nil
+		}},
+		{name: "list", strict: true, call: func(ctx context.Context, _, _ int) error {
+			_, err := client.GetBuffers(ctx)
+			return err
+		}},
+	}
+
+	runStress(t, ops)
+}
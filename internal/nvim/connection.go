@@ -0,0 +1,330 @@
+package nvim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/neovim/go-client/msgpack/rpc"
+	"github.com/neovim/go-client/nvim"
+
+	"github.com/cousine/neovim-mcp/internal/logger"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+const (
+	// ConnectionStateConnected indicates the client is connected to neovim
+	ConnectionStateConnected = "connected"
+	// ConnectionStateConnecting indicates the client is trying to (re)connect to neovim
+	ConnectionStateConnecting = "connecting"
+	// ConnectionStateDisconnected indicates the connection to neovim is lost
+	ConnectionStateDisconnected = "disconnected"
+	// ConnectionStateClosed indicates the client was closed
+	ConnectionStateClosed = "closed"
+)
+
+const (
+	// connectTimeout bounds dialing neovim and rebuilding the client caches
+	connectTimeout = 10 * time.Second
+	// healthCheckTimeout is how long neovim has to answer a health check
+	healthCheckTimeout = 5 * time.Second
+	// reconnectMinBackoff is the delay before the second reconnection attempt
+	reconnectMinBackoff = 250 * time.Millisecond
+	// reconnectMaxBackoff is the default cap of the reconnection delay
+	reconnectMaxBackoff = 30 * time.Second
+)
+
+// errConnectionClosed is reported when neovim closes the connection without an error
+var errConnectionClosed = errors.New("connection closed by neovim")
+
+// Option configures a Client created by NewClient
+type Option func(*clientConfig)
+
+// clientConfig holds the settings applied by Option
+type clientConfig struct {
+	reconnect      bool
+	maxBackoff     time.Duration
+	healthInterval time.Duration
+
+	// dial opens a connection without serving it, dialing the socket address when nil
+	dial func(ctx context.Context) (*nvim.Nvim, error)
+}
+
+// WithReconnect reconnects to neovim whenever the connection is lost, waiting between
+// attempts with an exponential backoff capped at maxBackoff (30s when not positive)
+func WithReconnect(maxBackoff time.Duration) Option {
+	return func(cfg *clientConfig) {
+		cfg.reconnect = true
+		cfg.maxBackoff = maxBackoff
+		if cfg.maxBackoff <= 0 {
+			cfg.maxBackoff = reconnectMaxBackoff
+		}
+	}
+}
+
+// WithHealthCheck pings neovim every interval to detect dead or unresponsive connections
+func WithHealthCheck(interval time.Duration) Option {
+	return func(cfg *clientConfig) {
+		cfg.healthInterval = interval
+	}
+}
+
+// withDialer replaces dialing the socket address with dial
+func withDialer(dial func(ctx context.Context) (*nvim.Nvim, error)) Option {
+	return func(cfg *clientConfig) {
+		cfg.dial = dial
+	}
+}
+
+// GetConnectionStatus returns the state and health of the neovim connection
+func (c *Client) GetConnectionStatus(ctx context.Context) (types.ConnectionStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return types.ConnectionStatus{}, fmt.Errorf("failed to get connection status: %w", err)
+	}
+
+	c.connMu.RLock()
+	defer c.connMu.RUnlock()
+
+	return c.status, nil
+}
+
+// ----------------------------------------------------------------------------
+
+// conn returns the current neovim connection or ErrNotConnected
+func (c *Client) conn() (*nvim.Nvim, error) {
+	c.connMu.RLock()
+	defer c.connMu.RUnlock()
+
+	if c.nvim != nil {
+		return c.nvim, nil
+	}
+
+	if c.status.LastError != "" {
+		return nil, fmt.Errorf("%w at `%s` (%s): %s", ErrNotConnected, c.address, c.status.State, c.status.LastError)
+	}
+
+	return nil, fmt.Errorf("%w at `%s` (%s)", ErrNotConnected, c.address, c.status.State)
+}
+
+// connect dials neovim, publishes the new connection and rebuilds the client caches
+func (c *Client) connect(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+
+	v, err := c.cfg.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to neovim at `%s`: %w", c.address, err)
+	}
+
+	go c.serve(v)
+
+	c.connMu.Lock()
+	select {
+	case <-c.done:
+		c.connMu.Unlock()
+
+		if cerr := v.Close(); cerr != nil {
+			logger.Debug("nvim: failed to close neovim connection", "error", cerr)
+		}
+
+		return fmt.Errorf("failed to connect to neovim: %w", ErrNotConnected)
+	default:
+	}
+
+	if !c.status.ConnectedAt.IsZero() {
+		c.status.Reconnects++
+	}
+
+	c.nvim = v
+	c.status.State = ConnectionStateConnected
+	c.status.Responsive = true
+	c.status.ConnectedAt = time.Now()
+	c.status.Attempts = 0
+	c.status.LastError = ""
+	c.connMu.Unlock()
+
+	if err = c.restore(ctx); err != nil {
+		c.disconnect(v, err)
+		return err
+	}
+
+	return nil
+}
+
+// restore rebuilds the client state bound to the neovim connection
+func (c *Client) restore(ctx context.Context) error {
+	c.watchMu.Lock()
+	watching := len(c.diagnosticsWatchers) > 0
+	c.watchMu.Unlock()
+
+	if watching {
+		if err := c.rpc(ctx, c.installDiagnosticsWatch); err != nil {
+			return fmt.Errorf("failed to watch diagnostics: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// serve reads from the connection until it is closed, then marks it as lost
+func (c *Client) serve(v *nvim.Nvim) {
+	err := v.Serve()
+	if err == nil {
+		err = errConnectionClosed
+	}
+
+	c.disconnect(v, err)
+}
+
+// disconnect drops connection v if it is still the current one and wakes the supervisor
+func (c *Client) disconnect(v *nvim.Nvim, err error) {
+	c.connMu.Lock()
+	if v == nil || c.nvim != v {
+		c.connMu.Unlock()
+		return
+	}
+
+	c.nvim = nil
+	c.status.State = ConnectionStateDisconnected
+	c.status.Responsive = false
+	c.status.LastError = err.Error()
+	c.connMu.Unlock()
+
+	logger.Warn("nvim: lost connection to neovim", "address", c.address, "error", err)
+
+	if cerr := v.Close(); cerr != nil {
+		logger.Debug("nvim: failed to close neovim connection", "error", cerr)
+	}
+
+	c.wake()
+}
+
+// connectFailed records a failed connection attempt
+func (c *Client) connectFailed(err error) {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+
+	c.status.State = ConnectionStateDisconnected
+	c.status.Attempts++
+	c.status.LastError = err.Error()
+}
+
+// wake asks the supervisor to reconnect without blocking
+func (c *Client) wake() {
+	select {
+	case c.lost <- struct{}{}:
+	default:
+	}
+}
+
+// supervise reconnects lost connections and runs health checks until the client is closed
+func (c *Client) supervise() {
+	var health <-chan time.Time
+	if c.cfg.healthInterval > 0 {
+		ticker := time.NewTicker(c.cfg.healthInterval)
+		defer ticker.Stop()
+
+		health = ticker.C
+	}
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-c.lost:
+			if c.cfg.reconnect {
+				c.reconnect()
+			}
+		case <-health:
+			c.checkHealth()
+		}
+	}
+}
+
+// reconnect dials neovim with exponential backoff until it succeeds or the client is closed
+func (c *Client) reconnect() {
+	backoff := reconnectMinBackoff
+
+	for {
+		c.connMu.Lock()
+		if c.nvim != nil || c.status.State == ConnectionStateClosed {
+			c.connMu.Unlock()
+			return
+		}
+
+		c.status.State = ConnectionStateConnecting
+		c.connMu.Unlock()
+
+		err := c.connect(context.Background())
+		if err == nil {
+			logger.Info("nvim: connected to neovim", "address", c.address)
+			return
+		}
+
+		c.connectFailed(err)
+		logger.Debug("nvim: failed to reconnect to neovim", "address", c.address, "retry", backoff, "error", err)
+
+		select {
+		case <-c.done:
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, c.cfg.maxBackoff)
+	}
+}
+
+// checkHealth pings neovim, dropping dead connections and flagging unresponsive ones.
+// Unlike rpc it never interrupts neovim, a slow answer may be the user at a prompt.
+func (c *Client) checkHealth() {
+	v, err := c.conn()
+	if err != nil {
+		return
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		var pid int
+		done <- v.Call("getpid", &pid)
+	}()
+
+	select {
+	case err = <-done:
+	case <-time.After(healthCheckTimeout):
+		err = fmt.Errorf("neovim did not answer within %s", healthCheckTimeout)
+	}
+
+	if isConnectionError(err) {
+		c.disconnect(v, err)
+		return
+	}
+
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+
+	if c.nvim != v {
+		return
+	}
+
+	c.status.LastCheck = time.Now()
+	c.status.Responsive = err == nil
+	if err != nil {
+		c.status.LastError = err.Error()
+		logger.Warn("nvim: neovim is not responding", "address", c.address, "error", err)
+	}
+}
+
+// isConnectionError reports whether err means the neovim connection is gone
+func isConnectionError(err error) bool {
+	return errors.Is(err, rpc.ErrClosed) ||
+		errors.Is(err, errConnectionClosed) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrClosedPipe) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, syscall.ECONNRESET)
+}
@@ -0,0 +1,160 @@
+package nvim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/neovim/go-client/msgpack/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startTestNeovim starts a headless Neovim listening on socketPath and waits for the socket
+func startTestNeovim(t *testing.T, socketPath string) *exec.Cmd {
+	t.Helper()
+
+	_ = os.Remove(socketPath)
+
+	cmd := exec.Command("nvim", "--headless", "--clean", "--listen", socketPath)
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start Neovim: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		_ = os.Remove(socketPath)
+	})
+
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(socketPath)
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+
+	return cmd
+}
+
+// --- Connection Tests ---
+
+func TestNewClient_Unreachable(t *testing.T) {
+	socketPath := testSocketPath(t)
+
+	t.Run("fails without reconnect", func(t *testing.T) {
+		_, err := NewClient(socketPath)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("returns a disconnected client with reconnect", func(t *testing.T) {
+		client, err := NewClient(socketPath, WithReconnect(time.Second))
+		require.NoError(t, err)
+		defer client.Close()
+
+		_, err = client.GetBuffers(context.Background())
+		assert.ErrorIs(t, err, ErrNotConnected)
+
+		status, err := client.GetConnectionStatus(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, socketPath, status.Address)
+		assert.NotEqual(t, ConnectionStateConnected, status.State)
+		assert.False(t, status.Responsive)
+		assert.Positive(t, status.Attempts)
+		assert.NotEmpty(t, status.LastError)
+	})
+
+	t.Run("reports closed clients", func(t *testing.T) {
+		client, err := NewClient(socketPath, WithReconnect(time.Second))
+		require.NoError(t, err)
+		require.NoError(t, client.Close())
+
+		status, err := client.GetConnectionStatus(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, ConnectionStateClosed, status.State)
+		assert.NoError(t, client.Close())
+	})
+}
+
+func TestClient_Reconnect(t *testing.T) {
+	ctx := context.Background()
+	socketPath := testSocketPath(t)
+
+	first := startTestNeovim(t, socketPath)
+
+	client, err := NewClient(socketPath, WithReconnect(500*time.Millisecond), WithHealthCheck(100*time.Millisecond))
+	require.NoError(t, err)
+	defer client.Close()
+
+	status, err := client.GetConnectionStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ConnectionStateConnected, status.State)
+
+	t.Run("reports the lost connection", func(t *testing.T) {
+		require.NoError(t, first.Process.Kill())
+
+		require.Eventually(t, func() bool {
+			_, err := client.GetBuffers(ctx)
+			return errors.Is(err, ErrNotConnected)
+		}, 5*time.Second, 50*time.Millisecond)
+	})
+
+	t.Run("reconnects to the restarted instance", func(t *testing.T) {
+		startTestNeovim(t, socketPath)
+
+		require.Eventually(t, func() bool {
+			_, err := client.GetBuffers(ctx)
+			return err == nil
+		}, 10*time.Second, 100*time.Millisecond)
+
+		status, err := client.GetConnectionStatus(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, ConnectionStateConnected, status.State)
+		assert.Equal(t, 1, status.Reconnects)
+		assert.Zero(t, status.Attempts)
+	})
+
+	t.Run("looks up buffers after reconnecting", func(t *testing.T) {
+		tmpFile := createTempFile(t, "reconnected")
+
+		_, err := client.OpenBuffer(ctx, tmpFile)
+		require.NoError(t, err)
+
+		_, err = client.GetBufferByTitle(ctx, tmpFile)
+		assert.NoError(t, err)
+	})
+
+	t.Run("runs health checks", func(t *testing.T) {
+		require.Eventually(t, func() bool {
+			status, err := client.GetConnectionStatus(ctx)
+			return err == nil && status.Responsive && !status.LastCheck.IsZero()
+		}, 5*time.Second, 50*time.Millisecond)
+	})
+}
+
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "session closed", err: rpc.ErrClosed, want: true},
+		{name: "eof", err: fmt.Errorf("read: %w", io.EOF), want: true},
+		{name: "broken pipe", err: fmt.Errorf("write: %w", syscall.EPIPE), want: true},
+		{name: "connection reset", err: syscall.ECONNRESET, want: true},
+		{name: "neovim error", err: errors.New("Vim:E492: Not an editor command"), want: false},
+		{name: "deadline", err: context.DeadlineExceeded, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isConnectionError(tt.err))
+		})
+	}
+}
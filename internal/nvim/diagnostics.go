@@ -0,0 +1,195 @@
+package nvim
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/neovim/go-client/nvim"
+
+	"github.com/cousine/neovim-mcp/internal/logger"
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// DiagnosticsChangedEvent is the rpc notification sent by neovim when diagnostics change
+const DiagnosticsChangedEvent = "neovim_mcp_diagnostics_changed"
+
+// luaGetDiagnostics collects vim.diagnostic entries, the first argument is an
+// optional buffer handle (nil for all buffers)
+const luaGetDiagnostics = `
+	local bufnr = ...
+	local severities = { 'error', 'warning', 'info', 'hint' }
+	local results = {}
+	for _, d in ipairs(vim.diagnostic.get(bufnr)) do
+		table.insert(results, {
+			buffer = d.bufnr,
+			path = vim.api.nvim_buf_get_name(d.bufnr),
+			severity = severities[d.severity] or '',
+			source = d.source or '',
+			code = d.code ~= nil and tostring(d.code) or '',
+			message = d.message or '',
+			lnum = d.lnum,
+			col = d.col,
+			end_lnum = d.end_lnum or d.lnum,
+			end_col = d.end_col or d.col,
+		})
+	end
+	return results
+`
+
+// luaWatchDiagnostics installs a DiagnosticChanged autocmd notifying the given channel
+const luaWatchDiagnostics = `
+	local chan, event = ...
+	local group = vim.api.nvim_create_augroup('neovim_mcp_diagnostics_' .. chan, { clear = true })
+	vim.api.nvim_create_autocmd('DiagnosticChanged', {
+		group = group,
+		callback = function(args)
+			vim.rpcnotify(chan, event, args.buf, vim.api.nvim_buf_get_name(args.buf))
+		end,
+	})
+`
+
+// luaUnwatchDiagnostics removes the autocmd of luaWatchDiagnostics for the given channel
+const luaUnwatchDiagnostics = `
+	local chan = ...
+	pcall(vim.api.nvim_del_augroup_by_name, 'neovim_mcp_diagnostics_' .. chan)
+`
+
+// luaDiagnostic mirrors the table returned by luaGetDiagnostics (0-based positions)
+type luaDiagnostic struct {
+	Buffer   int    `msgpack:"buffer"`
+	Path     string `msgpack:"path"`
+	Severity string `msgpack:"severity"`
+	Source   string `msgpack:"source"`
+	Code     string `msgpack:"code"`
+	Message  string `msgpack:"message"`
+	Line     int    `msgpack:"lnum"`
+	Col      int    `msgpack:"col"`
+	EndLine  int    `msgpack:"end_lnum"`
+	EndCol   int    `msgpack:"end_col"`
+}
+
+// GetDiagnostics returns diagnostics grouped by buffer, an empty title returns all buffers
+func (c *Client) GetDiagnostics(ctx context.Context, bufferTitle string) ([]types.BufferDiagnostics, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get diagnostics: %w", err)
+	}
+
+	var bufnr any
+	if bufferTitle != "" {
+		buf, err := c.GetBufferByTitle(ctx, bufferTitle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get diagnostics for buffer `%s`: %w", bufferTitle, err)
+		}
+
+		bufnr = int(buf.Handle)
+	}
+
+	var diagnostics []luaDiagnostic
+	err := c.rpc(ctx, func(v *nvim.Nvim) error {
+		return v.ExecLua(luaGetDiagnostics, &diagnostics, bufnr)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diagnostics: %w", err)
+	}
+
+	results := make([]types.BufferDiagnostics, 0)
+	index := make(map[int]int)
+	for _, d := range diagnostics {
+		i, ok := index[d.Buffer]
+		if !ok {
+			i = len(results)
+			index[d.Buffer] = i
+			results = append(results, types.BufferDiagnostics{
+				Handle:      nvim.Buffer(d.Buffer),
+				Path:        d.Path,
+				Diagnostics: []types.Diagnostic{},
+			})
+		}
+
+		results[i].Diagnostics = append(results[i].Diagnostics, types.Diagnostic{
+			Severity:    d.Severity,
+			Source:      d.Source,
+			Code:        d.Code,
+			Message:     d.Message,
+			StartLine:   d.Line + 1,
+			StartColumn: d.Col + 1,
+			EndLine:     d.EndLine + 1,
+			EndColumn:   d.EndCol + 1,
+		})
+	}
+
+	return results, nil
+}
+
+// WatchDiagnostics calls onChange whenever neovim fires a DiagnosticChanged autocmd,
+// the watch is reinstalled when the client reconnects. The returned function stops
+// calling onChange, and removes the autocmd once no watcher is left.
+func (c *Client) WatchDiagnostics(ctx context.Context, onChange func()) (func(context.Context) error, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to watch diagnostics: %w", err)
+	}
+
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+
+	if err := c.rpc(ctx, c.installDiagnosticsWatch); err != nil {
+		return nil, fmt.Errorf("failed to watch diagnostics: %w", err)
+	}
+
+	id := c.nextWatcher
+	c.nextWatcher++
+	c.diagnosticsWatchers[id] = onChange
+
+	return func(ctx context.Context) error { return c.unwatchDiagnostics(ctx, id) }, nil
+}
+
+// ----------------------------------------------------------------------------
+
+// installDiagnosticsWatch registers the diagnostics notification handler and autocmd
+// on connection v, installing them again replaces the previous ones
+func (c *Client) installDiagnosticsWatch(v *nvim.Nvim) error {
+	if err := v.RegisterHandler(DiagnosticsChangedEvent, c.diagnosticsChanged); err != nil {
+		return err
+	}
+
+	return v.ExecLua(luaWatchDiagnostics, nil, v.ChannelID(), DiagnosticsChangedEvent)
+}
+
+// unwatchDiagnostics forgets the watcher id, removing the autocmd after the last one
+func (c *Client) unwatchDiagnostics(ctx context.Context, id int) error {
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+
+	if _, ok := c.diagnosticsWatchers[id]; !ok {
+		return nil
+	}
+
+	delete(c.diagnosticsWatchers, id)
+	if len(c.diagnosticsWatchers) > 0 {
+		return nil
+	}
+
+	err := c.rpc(ctx, func(v *nvim.Nvim) error {
+		return v.ExecLua(luaUnwatchDiagnostics, nil, v.ChannelID())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to stop watching diagnostics: %w", err)
+	}
+
+	return nil
+}
+
+// diagnosticsChanged handles DiagnosticsChangedEvent notifications
+func (c *Client) diagnosticsChanged(buf int, path string) {
+	logger.Debug("nvim: diagnostics changed", "buffer", buf, "path", path)
+
+	c.watchMu.Lock()
+	watchers := slices.Collect(maps.Values(c.diagnosticsWatchers))
+	c.watchMu.Unlock()
+
+	for _, onChange := range watchers {
+		onChange()
+	}
+}
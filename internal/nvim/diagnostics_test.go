@@ -0,0 +1,158 @@
+package nvim
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setTestDiagnostics publishes diagnostics for the current buffer under a test namespace
+func setTestDiagnostics(t *testing.T, client *Client) {
+	t.Helper()
+
+	_, err := client.ExecLua(context.Background(), `
+		local ns = vim.api.nvim_create_namespace('neovim_mcp_test')
+		vim.diagnostic.set(ns, 0, {
+			{ lnum = 0, col = 2, end_lnum = 0, end_col = 5, severity = vim.diagnostic.severity.ERROR,
+			  message = 'undefined: foo', source = 'gopls', code = 'UndeclaredName' },
+			{ lnum = 2, col = 0, severity = vim.diagnostic.severity.HINT, message = 'unused', code = 42 },
+		})
+	`, nil)
+	require.NoError(t, err)
+}
+
+// --- Diagnostic Operations Tests ---
+
+func TestClient_GetDiagnostics(t *testing.T) {
+	client, cleanup := setupTestNeovim(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	t.Run("returns empty list without diagnostics", func(t *testing.T) {
+		diagnostics, err := client.GetDiagnostics(ctx, "")
+
+		require.NoError(t, err)
+		assert.Empty(t, diagnostics)
+	})
+
+	t.Run("returns diagnostics grouped by buffer", func(t *testing.T) {
+		tmpFile := createTempFile(t, "a foo b\nline2\nline3")
+		resolvedTmpFile := resolvePath(t, tmpFile)
+
+		_, err := client.OpenBuffer(ctx, tmpFile)
+		require.NoError(t, err)
+
+		setTestDiagnostics(t, client)
+
+		diagnostics, err := client.GetDiagnostics(ctx, "")
+
+		require.NoError(t, err)
+		require.Len(t, diagnostics, 1)
+		assert.Equal(t, resolvedTmpFile, diagnostics[0].Path)
+		require.Len(t, diagnostics[0].Diagnostics, 2)
+
+		first := diagnostics[0].Diagnostics[0]
+		assert.Equal(t, "error", first.Severity)
+		assert.Equal(t, "gopls", first.Source)
+		assert.Equal(t, "UndeclaredName", first.Code)
+		assert.Equal(t, "undefined: foo", first.Message)
+		assert.Equal(t, 1, first.StartLine)
+		assert.Equal(t, 3, first.StartColumn)
+		assert.Equal(t, 1, first.EndLine)
+		assert.Equal(t, 6, first.EndColumn)
+
+		second := diagnostics[0].Diagnostics[1]
+		assert.Equal(t, "hint", second.Severity)
+		assert.Equal(t, "42", second.Code)
+		assert.Equal(t, 3, second.StartLine)
+	})
+
+	t.Run("filters diagnostics by buffer", func(t *testing.T) {
+		tmpFile := createTempFile(t, "clean")
+
+		_, err := client.OpenBuffer(ctx, tmpFile)
+		require.NoError(t, err)
+
+		diagnostics, err := client.GetDiagnostics(ctx, filepath.Base(tmpFile))
+
+		require.NoError(t, err)
+		assert.Empty(t, diagnostics)
+	})
+
+	t.Run("returns error for non-existent buffer", func(t *testing.T) {
+		_, err := client.GetDiagnostics(ctx, "nonexistent-file-12345.txt")
+
+		assert.ErrorIs(t, err, ErrBufferNotFound)
+	})
+}
+
+func TestClient_WatchDiagnostics(t *testing.T) {
+	client, cleanup := setupTestNeovim(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	t.Run("notifies on diagnostic changes", func(t *testing.T) {
+		tmpFile := createTempFile(t, "a foo b\nline2\nline3")
+
+		_, err := client.OpenBuffer(ctx, tmpFile)
+		require.NoError(t, err)
+
+		changed := make(chan struct{}, 1)
+		stop, err := client.WatchDiagnostics(ctx, func() {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		})
+		require.NoError(t, err)
+
+		setTestDiagnostics(t, client)
+
+		select {
+		case <-changed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected diagnostics change notification")
+		}
+
+		require.NoError(t, stop(ctx))
+	})
+
+	t.Run("removes the autocmd after the last watcher stops", func(t *testing.T) {
+		first, err := client.WatchDiagnostics(ctx, func() {})
+		require.NoError(t, err)
+		second, err := client.WatchDiagnostics(ctx, func() {})
+		require.NoError(t, err)
+
+		hasAutocmd := func() bool {
+			var count int
+			err := client.rpc(ctx, func(v *nvim.Nvim) error {
+				return v.ExecLua(`return #vim.api.nvim_get_autocmds({ event = 'DiagnosticChanged' })`, &count)
+			})
+			require.NoError(t, err)
+			return count > 0
+		}
+
+		require.NoError(t, first(ctx))
+		assert.True(t, hasAutocmd())
+
+		require.NoError(t, second(ctx))
+		assert.False(t, hasAutocmd())
+		assert.NoError(t, second(ctx))
+	})
+
+	t.Run("returns error for cancelled context", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := client.WatchDiagnostics(cancelled, func() {})
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
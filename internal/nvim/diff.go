@@ -0,0 +1,399 @@
+package nvim
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// maxFuzz is the number of context lines a hunk may ignore at each end to apply
+const maxFuzz = 2
+
+// devNull is the path of the missing side of a created or deleted file
+const devNull = "/dev/null"
+
+// hunkHeaderPattern matches `@@ -start,count +start,count @@ section`
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// filePatch holds the hunks of a single file of a unified diff
+type filePatch struct {
+	oldPath string
+	newPath string
+	hunks   []hunk
+}
+
+// hunk is a hunk of a unified diff, ops holds the ' ', '-' or '+' of each line
+type hunk struct {
+	header   string
+	oldStart int // 1-based line of the header, 0 when unknown
+	oldCount int // -1 when the header has no counts
+	newCount int
+	ops      []byte
+	lines    []string
+}
+
+// lineEdit replaces the lines start to end (0-based, end exclusive) with lines
+type lineEdit struct {
+	start, end int
+	lines      []string
+}
+
+// hunkMatch is where a hunk applies to a buffer, or why it does not
+type hunkMatch struct {
+	edit       lineEdit
+	offset     int
+	fuzz       int
+	whitespace bool
+	reason     string
+}
+
+// path returns the buffer path targeted by the file patch
+func (f filePatch) path() string {
+	if f.newPath == devNull {
+		return f.oldPath
+	}
+
+	return f.newPath
+}
+
+// ----------------------------------------------------------------------------
+
+// parsePatch parses a possibly multi-file unified diff. Hunk line counts are used to
+// tell removed `---` lines from file headers but are not required to be accurate.
+func parsePatch(patch string) ([]filePatch, error) {
+	lines := strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n")
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var files []filePatch
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case isFileHeader(lines, i):
+			oldPath, newPath := headerPath(lines[i], "--- "), headerPath(lines[i+1], "+++ ")
+			oldPath, newPath = stripGitPrefixes(oldPath, newPath)
+			files = append(files, filePatch{oldPath: oldPath, newPath: newPath})
+			i += 2
+		case strings.HasPrefix(line, "@@"):
+			if len(files) == 0 {
+				return nil, fmt.Errorf("%w: hunk `%s` comes before any --- / +++ file header", ErrInvalidPatch, line)
+			}
+
+			h, next := parseHunk(lines, i)
+			files[len(files)-1].hunks = append(files[len(files)-1].hunks, h)
+			i = next
+		default:
+			// git extended headers, `diff` lines and prose around the patch
+			i++
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: no --- / +++ file header found", ErrInvalidPatch)
+	}
+
+	for _, f := range files {
+		if len(f.hunks) == 0 {
+			return nil, fmt.Errorf("%w: `%s` has no hunks", ErrInvalidPatch, f.path())
+		}
+	}
+
+	return files, nil
+}
+
+// isFileHeader reports whether lines[i] and lines[i+1] are a --- / +++ file header
+func isFileHeader(lines []string, i int) bool {
+	return strings.HasPrefix(lines[i], "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ ")
+}
+
+// headerPath extracts the path of a file header line, dropping any timestamp
+func headerPath(line, prefix string) string {
+	path := strings.TrimPrefix(line, prefix)
+	if tab := strings.IndexByte(path, '\t'); tab >= 0 {
+		path = path[:tab]
+	}
+
+	return strings.TrimSpace(path)
+}
+
+// stripGitPrefixes removes the a/ and b/ prefixes git adds to diff paths
+func stripGitPrefixes(oldPath, newPath string) (string, string) {
+	oldGit := oldPath == devNull || strings.HasPrefix(oldPath, "a/")
+	newGit := newPath == devNull || strings.HasPrefix(newPath, "b/")
+
+	if !oldGit || !newGit || (oldPath == devNull && newPath == devNull) {
+		return oldPath, newPath
+	}
+
+	return strings.TrimPrefix(oldPath, "a/"), strings.TrimPrefix(newPath, "b/")
+}
+
+// parseHunk parses the hunk starting at lines[start] and returns the index of the
+// first line after it
+func parseHunk(lines []string, start int) (hunk, int) {
+	h := hunk{header: lines[start], oldCount: -1}
+
+	if m := hunkHeaderPattern.FindStringSubmatch(lines[start]); m != nil {
+		h.oldStart, _ = strconv.Atoi(m[1])
+		h.oldCount, h.newCount = 1, 1
+
+		if m[2] != "" {
+			h.oldCount, _ = strconv.Atoi(m[2])
+		}
+
+		if m[4] != "" {
+			h.newCount, _ = strconv.Atoi(m[4])
+		}
+	}
+
+	oldSeen, newSeen := 0, 0
+	complete := func() bool { return h.oldCount >= 0 && oldSeen >= h.oldCount && newSeen >= h.newCount }
+
+	i := start + 1
+	for ; i < len(lines); i++ {
+		line := lines[i]
+
+		if strings.HasPrefix(line, "@@") || strings.HasPrefix(line, "diff ") {
+			break
+		}
+
+		if isFileHeader(lines, i) && (h.oldCount < 0 || complete()) {
+			break
+		}
+
+		// editors and models often strip the single space of empty context lines
+		op, text := byte(' '), ""
+		if line != "" {
+			op, text = line[0], line[1:]
+		}
+
+		switch op {
+		case ' ':
+			oldSeen++
+			newSeen++
+		case '-':
+			oldSeen++
+		case '+':
+			newSeen++
+		case '\\':
+			// `\ No newline at end of file`
+			continue
+		default:
+			return h, i
+		}
+
+		h.ops = append(h.ops, op)
+		h.lines = append(h.lines, text)
+	}
+
+	return h, i
+}
+
+// matchHunks locates the hunks of a file in lines and returns their matches in order.
+// Each hunk is searched nearest to its header position, shifted by the offset of the
+// previous hunk, and may not overlap the previous applied hunk.
+func matchHunks(lines []string, hunks []hunk) []hunkMatch {
+	matches := make([]hunkMatch, len(hunks))
+
+	offset, minStart := 0, 0
+	for i, h := range hunks {
+		if h.oldCount < 0 {
+			// a hunk without line numbers is searched from the previous hunk on
+			matches[i] = matchHunk(lines, h, minStart, minStart)
+			matches[i].offset = 0
+		} else {
+			hint := h.oldStart - 1
+			if h.oldCount == 0 {
+				// a hunk without old lines inserts after line oldStart
+				hint = h.oldStart
+			}
+
+			matches[i] = matchHunk(lines, h, hint+offset, minStart)
+			matches[i].offset += offset
+		}
+
+		if matches[i].reason != "" {
+			continue
+		}
+
+		if h.oldCount >= 0 {
+			offset = matches[i].offset
+		}
+
+		minStart = matches[i].edit.end
+	}
+
+	return matches
+}
+
+// matchHunk finds where a hunk applies, relaxing whitespace and then context
+func matchHunk(lines []string, h hunk, hint, minStart int) hunkMatch {
+	if len(h.ops) == 0 {
+		return hunkMatch{reason: "hunk has no lines"}
+	}
+
+	leading, trailing := contextLines(h.ops)
+
+	for fuzz := 0; fuzz <= maxFuzz; fuzz++ {
+		// only context lines can be ignored, never removed or added ones
+		skipStart, skipEnd := min(fuzz, leading), min(fuzz, trailing)
+		if fuzz > 0 && skipStart < fuzz && skipEnd < fuzz {
+			break
+		}
+
+		ops := h.ops[skipStart : len(h.ops)-skipEnd]
+		body := h.lines[skipStart : len(h.lines)-skipEnd]
+		old := oldLines(ops, body)
+
+		for _, whitespace := range []bool{false, true} {
+			pos, ok := locate(lines, old, hint+skipStart, minStart, whitespace)
+			if !ok {
+				continue
+			}
+
+			return hunkMatch{
+				edit:       hunkEdit(lines, ops, body, pos),
+				offset:     pos - (hint + skipStart),
+				fuzz:       fuzz,
+				whitespace: whitespace,
+			}
+		}
+	}
+
+	if len(oldLines(h.ops, h.lines)) == 0 {
+		return hunkMatch{reason: fmt.Sprintf("cannot insert at line %d of a buffer with %d lines", hint+1, len(lines))}
+	}
+
+	return hunkMatch{reason: fmt.Sprintf("context and removed lines not found after line %d, even ignoring whitespace and up to %d context lines",
+		minStart, maxFuzz)}
+}
+
+// contextLines counts the context lines at the start and end of a hunk
+func contextLines(ops []byte) (int, int) {
+	leading := 0
+	for leading < len(ops) && ops[leading] == ' ' {
+		leading++
+	}
+
+	trailing := 0
+	for trailing < len(ops)-leading && ops[len(ops)-1-trailing] == ' ' {
+		trailing++
+	}
+
+	return leading, trailing
+}
+
+// oldLines returns the context and removed lines of a hunk
+func oldLines(ops []byte, lines []string) []string {
+	var old []string
+	for i, op := range ops {
+		if op != '+' {
+			old = append(old, lines[i])
+		}
+	}
+
+	return old
+}
+
+// locate returns the position of old in lines nearest to hint and not before minStart
+func locate(lines, old []string, hint, minStart int, ignoreWhitespace bool) (int, bool) {
+	last := len(lines) - len(old)
+	if last < minStart {
+		return 0, false
+	}
+
+	hint = max(minStart, min(hint, last))
+
+	for distance := 0; hint-distance >= minStart || hint+distance <= last; distance++ {
+		for _, pos := range []int{hint - distance, hint + distance} {
+			if pos >= minStart && pos <= last && linesMatch(lines[pos:pos+len(old)], old, ignoreWhitespace) {
+				return pos, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// linesMatch compares lines, optionally ignoring differences in whitespace
+func linesMatch(a, b []string, ignoreWhitespace bool) bool {
+	for i := range b {
+		if a[i] == b[i] {
+			continue
+		}
+
+		if !ignoreWhitespace || strings.Join(strings.Fields(a[i]), " ") != strings.Join(strings.Fields(b[i]), " ") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hunkEdit builds the edit applying a hunk matched at pos, keeping the buffer version
+// of context lines so whitespace-insensitive matches do not rewrite them
+func hunkEdit(lines []string, ops []byte, body []string, pos int) lineEdit {
+	leading, trailing := contextLines(ops)
+
+	edit := lineEdit{start: pos + leading, lines: []string{}}
+	current := edit.start
+
+	for i := leading; i < len(ops)-trailing; i++ {
+		switch ops[i] {
+		case ' ':
+			edit.lines = append(edit.lines, lines[current])
+			current++
+		case '-':
+			current++
+		case '+':
+			edit.lines = append(edit.lines, body[i])
+		}
+	}
+
+	edit.end = current
+
+	return edit
+}
+
+// hunkResults reports the matches of a file and the ranges of the applied hunks once
+// the edits are applied, edits holds the applied edits in order
+func hunkResults(hunks []hunk, matches []hunkMatch) ([]types.HunkResult, []lineEdit) {
+	results := make([]types.HunkResult, len(hunks))
+
+	var edits []lineEdit
+	shift := 0
+
+	for i, m := range matches {
+		results[i] = types.HunkResult{Hunk: i + 1, Header: hunks[i].header}
+
+		if m.reason != "" {
+			results[i].Reason = m.reason
+			continue
+		}
+
+		start := m.edit.start + shift
+		shift += len(m.edit.lines) - (m.edit.end - m.edit.start)
+
+		changed := types.TextRange{StartLine: start + 1, StartColumn: 1, EndLine: start + 1, EndColumn: 1}
+		if n := len(m.edit.lines); n > 0 {
+			changed.EndLine = start + n
+			changed.EndColumn = len(m.edit.lines[n-1]) + 1
+		}
+
+		results[i].Applied = true
+		results[i].Offset = m.offset
+		results[i].Fuzz = m.fuzz
+		results[i].IgnoredWhitespace = m.whitespace
+		results[i].Changed = &changed
+
+		edits = append(edits, m.edit)
+	}
+
+	return results, edits
+}
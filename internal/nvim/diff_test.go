@@ -0,0 +1,174 @@
+package nvim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestParsePatch(t *testing.T) {
+	t.Run("multi-file git diff", func(t *testing.T) {
+		patch := "diff --git a/main.go b/main.go\n" +
+			"index 123..456 100644\n" +
+			"--- a/main.go\n" +
+			"+++ b/main.go\n" +
+			"@@ -1,3 +1,3 @@ package main\n" +
+			" a\n" +
+			"-b\n" +
+			"+B\n" +
+			" c\n" +
+			"@@ -10,2 +10,3 @@\n" +
+			" x\n" +
+			"+y\n" +
+			" z\n" +
+			"\\ No newline at end of file\n" +
+			"diff --git a/new.txt b/new.txt\n" +
+			"new file mode 100644\n" +
+			"--- /dev/null\n" +
+			"+++ b/new.txt\n" +
+			"@@ -0,0 +1 @@\n" +
+			"+hello\n"
+
+		files, err := parsePatch(patch)
+		require.NoError(t, err)
+		require.Len(t, files, 2)
+
+		assert.Equal(t, "main.go", files[0].path())
+		require.Len(t, files[0].hunks, 2)
+		assert.Equal(t, hunk{
+			header:   "@@ -1,3 +1,3 @@ package main",
+			oldStart: 1, oldCount: 3, newCount: 3,
+			ops:   []byte(" -+ "),
+			lines: []string{"a", "b", "B", "c"},
+		}, files[0].hunks[0])
+		assert.Equal(t, []byte(" + "), files[0].hunks[1].ops)
+
+		assert.Equal(t, devNull, files[1].oldPath)
+		assert.Equal(t, "new.txt", files[1].path())
+		assert.Equal(t, 0, files[1].hunks[0].oldCount)
+	})
+
+	t.Run("removed lines looking like headers", func(t *testing.T) {
+		patch := "--- notes.md\n+++ notes.md\n@@ -1,3 +1,1 @@\n--- old\n+++ new\n keep\n"
+
+		files, err := parsePatch(patch)
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		assert.Equal(t, "notes.md", files[0].path())
+		assert.Equal(t, []string{"-- old", "++ new", "keep"}, files[0].hunks[0].lines)
+	})
+
+	t.Run("hunks without line numbers and stripped blank context", func(t *testing.T) {
+		files, err := parsePatch("--- a/f\n+++ b/f\n@@\n a\n\n-b\n+c\n")
+		require.NoError(t, err)
+
+		h := files[0].hunks[0]
+		assert.Equal(t, -1, h.oldCount)
+		assert.Equal(t, []byte("  -+"), h.ops)
+		assert.Equal(t, []string{"a", "", "b", "c"}, h.lines)
+	})
+
+	t.Run("rejects text without file headers", func(t *testing.T) {
+		_, err := parsePatch("@@ -1 +1 @@\n-a\n+b\n")
+		assert.ErrorIs(t, err, ErrInvalidPatch)
+
+		_, err = parsePatch("just some text")
+		assert.ErrorIs(t, err, ErrInvalidPatch)
+	})
+}
+
+func TestMatchHunks(t *testing.T) {
+	lines := []string{"func a() {", "\treturn 1", "}", "", "func b() {", "\treturn 2", "}"}
+
+	parse := func(t *testing.T, hunks string) []hunk {
+		t.Helper()
+
+		files, err := parsePatch("--- a/f.go\n+++ b/f.go\n" + hunks)
+		require.NoError(t, err)
+
+		return files[0].hunks
+	}
+
+	t.Run("exact", func(t *testing.T) {
+		matches := matchHunks(lines, parse(t, "@@ -5,3 +5,3 @@\n func b() {\n-\treturn 2\n+\treturn 3\n }\n"))
+
+		require.Empty(t, matches[0].reason)
+		assert.Equal(t, lineEdit{start: 5, end: 6, lines: []string{"\treturn 3"}}, matches[0].edit)
+		assert.Zero(t, matches[0].offset)
+		assert.Zero(t, matches[0].fuzz)
+	})
+
+	t.Run("shifted", func(t *testing.T) {
+		matches := matchHunks(lines, parse(t, "@@ -1,3 +1,3 @@\n func b() {\n-\treturn 2\n+\treturn 3\n }\n"))
+
+		require.Empty(t, matches[0].reason)
+		assert.Equal(t, 4, matches[0].offset)
+		assert.Equal(t, 5, matches[0].edit.start)
+	})
+
+	t.Run("whitespace", func(t *testing.T) {
+		matches := matchHunks(lines, parse(t, "@@ -5,3 +5,3 @@\n func b()  {\n-    return 2\n+\treturn 3\n }\n"))
+
+		require.Empty(t, matches[0].reason)
+		assert.True(t, matches[0].whitespace)
+		assert.Equal(t, lineEdit{start: 5, end: 6, lines: []string{"\treturn 3"}}, matches[0].edit)
+	})
+
+	t.Run("stale context", func(t *testing.T) {
+		matches := matchHunks(lines, parse(t, "@@ -4,4 +4,4 @@\n // b\n func b() {\n-\treturn 2\n+\treturn 3\n }\n"))
+
+		require.Empty(t, matches[0].reason)
+		assert.Equal(t, 1, matches[0].fuzz)
+		assert.Equal(t, lineEdit{start: 5, end: 6, lines: []string{"\treturn 3"}}, matches[0].edit)
+	})
+
+	t.Run("keeps buffer context lines", func(t *testing.T) {
+		matches := matchHunks(lines, parse(t, "@@ -1,3 +1,3 @@\n-func a() {\n  return 1\n+func a() int {\n }\n"))
+
+		require.Empty(t, matches[0].reason)
+		assert.Equal(t, lineEdit{start: 0, end: 2, lines: []string{"\treturn 1", "func a() int {"}}, matches[0].edit)
+	})
+
+	t.Run("rejects missing lines", func(t *testing.T) {
+		matches := matchHunks(lines, parse(t, "@@ -5,3 +5,3 @@\n func c() {\n-\treturn 4\n+\treturn 3\n }\n"))
+
+		assert.Contains(t, matches[0].reason, "not found")
+	})
+
+	t.Run("keeps hunks in order", func(t *testing.T) {
+		matches := matchHunks(lines, parse(t,
+			"@@ -5,2 +5,2 @@\n-func b() {\n+func c() {\n \treturn 2\n"+
+				"@@ -1,2 +1,2 @@\n-func a() {\n+func d() {\n \treturn 1\n"))
+
+		require.Empty(t, matches[0].reason)
+		assert.Contains(t, matches[1].reason, "not found after line 5")
+	})
+
+	t.Run("insertion", func(t *testing.T) {
+		matches := matchHunks(lines, parse(t, "@@ -7,0 +8,2 @@\n+\n+func c() {}\n"))
+
+		require.Empty(t, matches[0].reason)
+		assert.Equal(t, lineEdit{start: 7, end: 7, lines: []string{"", "func c() {}"}}, matches[0].edit)
+	})
+}
+
+func TestHunkResults(t *testing.T) {
+	hunks := []hunk{{header: "@@ -1 +1,2 @@"}, {header: "@@ -5 +6 @@"}, {header: "@@ -9 +9 @@"}}
+	matches := []hunkMatch{
+		{edit: lineEdit{start: 0, end: 1, lines: []string{"a", "bc"}}},
+		{reason: "context not found"},
+		{edit: lineEdit{start: 8, end: 9, lines: []string{}}, offset: 2, fuzz: 1},
+	}
+
+	results, edits := hunkResults(hunks, matches)
+
+	assert.Equal(t, []types.HunkResult{
+		{Hunk: 1, Header: "@@ -1 +1,2 @@", Applied: true, Changed: &types.TextRange{StartLine: 1, StartColumn: 1, EndLine: 2, EndColumn: 3}},
+		{Hunk: 2, Header: "@@ -5 +6 @@", Reason: "context not found"},
+		{Hunk: 3, Header: "@@ -9 +9 @@", Applied: true, Offset: 2, Fuzz: 1, Changed: &types.TextRange{StartLine: 10, StartColumn: 1, EndLine: 10, EndColumn: 1}},
+	}, results)
+	assert.Equal(t, []lineEdit{matches[0].edit, matches[2].edit}, edits)
+}
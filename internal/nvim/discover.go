@@ -0,0 +1,228 @@
+package nvim
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/neovim/go-client/nvim"
+
+	"github.com/cousine/neovim-mcp/internal/logger"
+)
+
+const (
+	// probeTimeout bounds dialing and querying a discovered socket
+	probeTimeout = 2 * time.Second
+	// exactMatchScore ranks a cwd equal to a client dir above any nested cwd
+	exactMatchScore = math.MaxInt
+)
+
+// Candidate is a running neovim instance found by discovery
+type Candidate struct {
+	Address string `json:"address"`
+	PID     int    `json:"pid"`
+	Cwd     string `json:"cwd"`
+}
+
+// String describes the candidate for error messages
+func (c Candidate) String() string {
+	return fmt.Sprintf("%s (pid %d, cwd %s)", c.Address, c.PID, c.Cwd)
+}
+
+// DiscoverOptions configures Discover
+type DiscoverOptions struct {
+	// Dirs are the working directory and roots of the MCP client
+	Dirs []string
+	// Inherited is the $NVIM address set when running inside a neovim terminal
+	Inherited string
+	// Patterns are the socket globs to scan, SocketPatterns when empty
+	Patterns []string
+}
+
+// SocketPatterns returns the globs matching the sockets neovim listens on by default:
+// $XDG_RUNTIME_DIR/nvim.* and the legacy /tmp/nvim.*/0
+func SocketPatterns() []string {
+	var patterns []string
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		patterns = append(patterns, filepath.Join(runtimeDir, "nvim.*"))
+	}
+
+	return append(patterns, filepath.Join(os.TempDir(), "nvim.*", "0"))
+}
+
+// FindSockets returns the unix sockets matching patterns, without duplicates
+func FindSockets(patterns []string) []string {
+	var sockets []string
+
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			logger.Debug("nvim: invalid socket pattern", "pattern", pattern, "error", err)
+			continue
+		}
+
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil || info.Mode()&os.ModeSocket == 0 || slices.Contains(sockets, match) {
+				continue
+			}
+
+			sockets = append(sockets, match)
+		}
+	}
+
+	return sockets
+}
+
+// ProbeSocket connects to address and asks neovim for its api info, pid and cwd
+func ProbeSocket(ctx context.Context, address string) (Candidate, error) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	v, err := nvim.Dial(address, nvim.DialContext(ctx))
+	if err != nil {
+		return Candidate{}, fmt.Errorf("failed to connect to neovim at `%s`: %w", address, err)
+	}
+
+	defer func() {
+		if cerr := v.Close(); cerr != nil {
+			logger.Debug("nvim: failed to close probe connection", "address", address, "error", cerr)
+		}
+	}()
+
+	candidate := Candidate{Address: address}
+
+	done := make(chan error, 1)
+	go func() {
+		var apiInfo []any
+
+		b := v.NewBatch()
+		b.APIInfo(&apiInfo)
+		b.Call("getpid", &candidate.PID)
+		b.Call("getcwd", &candidate.Cwd)
+		done <- b.Execute()
+	}()
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		return Candidate{}, fmt.Errorf("failed to probe neovim at `%s`: %w", address, err)
+	}
+
+	return candidate, nil
+}
+
+// Discover finds the running neovim instance to connect to. The inherited $NVIM
+// instance wins, otherwise the sockets matching the patterns are probed and the
+// instance working in one of the dirs is picked, see SelectCandidate.
+func Discover(ctx context.Context, opts DiscoverOptions) (Candidate, error) {
+	if opts.Inherited != "" {
+		candidate, err := ProbeSocket(ctx, opts.Inherited)
+		if err == nil {
+			return candidate, nil
+		}
+
+		logger.Warn("nvim: inherited neovim instance is not reachable", "address", opts.Inherited, "error", err)
+	}
+
+	patterns := opts.Patterns
+	if len(patterns) == 0 {
+		patterns = SocketPatterns()
+	}
+
+	var candidates []Candidate
+	for _, socket := range FindSockets(patterns) {
+		if err := ctx.Err(); err != nil {
+			return Candidate{}, fmt.Errorf("failed to discover neovim: %w", err)
+		}
+
+		candidate, err := ProbeSocket(ctx, socket)
+		if err != nil {
+			logger.Debug("nvim: skipping stale neovim socket", "address", socket, "error", err)
+			continue
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	return SelectCandidate(candidates, opts.Dirs)
+}
+
+// SelectCandidate picks the candidate whose cwd is one of dirs, or failing that the
+// candidate whose cwd is inside one of dirs, the deepest dir winning. It fails with a DiscoveryError
+// listing the candidates when none or several of them match equally well.
+func SelectCandidate(candidates []Candidate, dirs []string) (Candidate, error) {
+	var (
+		best      []Candidate
+		bestScore int
+	)
+
+	for _, candidate := range candidates {
+		score := matchScore(candidate.Cwd, dirs)
+		switch {
+		case score == 0 || score < bestScore:
+			continue
+		case score > bestScore:
+			best, bestScore = nil, score
+		}
+
+		best = append(best, candidate)
+	}
+
+	switch len(best) {
+	case 1:
+		return best[0], nil
+	case 0:
+		return Candidate{}, &DiscoveryError{Err: ErrInstanceNotFound, Dirs: dirs, Candidates: candidates}
+	default:
+		return Candidate{}, &DiscoveryError{Err: ErrAmbiguousInstance, Dirs: dirs, Candidates: best}
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// matchScore rates how well cwd matches dirs: 0 when it is outside all of them, the
+// length of the deepest dir containing it, or exactMatchScore when it is one of them
+func matchScore(cwd string, dirs []string) int {
+	if cwd == "" {
+		return 0
+	}
+
+	cwd = cleanPath(cwd)
+
+	score := 0
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+
+		dir = cleanPath(dir)
+
+		switch {
+		case cwd == dir:
+			return exactMatchScore
+		case strings.HasPrefix(cwd, dir+string(filepath.Separator)):
+			score = max(score, len(dir))
+		}
+	}
+
+	return score
+}
+
+// cleanPath resolves symlinks of path when possible, so /tmp and /private/tmp match
+func cleanPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+
+	return filepath.Clean(path)
+}
@@ -0,0 +1,171 @@
+package nvim
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tempSocketDir creates a short temporary directory, unix socket paths are length limited
+func tempSocketDir(t *testing.T) string {
+	t.Helper()
+
+	dir, err := os.MkdirTemp("", "nvim-discover-")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	return dir
+}
+
+func TestFindSockets(t *testing.T) {
+	dir := tempSocketDir(t)
+
+	listener, err := net.Listen("unix", filepath.Join(dir, "nvim.123.0"))
+	require.NoError(t, err)
+	defer listener.Close()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nvim.log"), nil, 0o600))
+
+	t.Run("returns sockets only", func(t *testing.T) {
+		sockets := FindSockets([]string{filepath.Join(dir, "nvim.*")})
+
+		assert.Equal(t, []string{filepath.Join(dir, "nvim.123.0")}, sockets)
+	})
+
+	t.Run("skips duplicates", func(t *testing.T) {
+		sockets := FindSockets([]string{filepath.Join(dir, "nvim.*"), filepath.Join(dir, "*.0")})
+
+		assert.Len(t, sockets, 1)
+	})
+
+	t.Run("ignores invalid patterns", func(t *testing.T) {
+		assert.Empty(t, FindSockets([]string{"[", filepath.Join(dir, "missing.*")}))
+	})
+}
+
+func TestSelectCandidate(t *testing.T) {
+	project := Candidate{Address: "/run/nvim.1.0", PID: 1, Cwd: "/src/project"}
+	nested := Candidate{Address: "/run/nvim.2.0", PID: 2, Cwd: "/src/project/docs"}
+	other := Candidate{Address: "/run/nvim.3.0", PID: 3, Cwd: "/src/other"}
+	twin := Candidate{Address: "/run/nvim.4.0", PID: 4, Cwd: "/src/project"}
+
+	tests := []struct {
+		name       string
+		candidates []Candidate
+		dirs       []string
+		want       Candidate
+		wantErr    error
+	}{
+		{
+			name:       "exact cwd",
+			candidates: []Candidate{other, nested, project},
+			dirs:       []string{"/src/project"},
+			want:       project,
+		},
+		{
+			name:       "any root",
+			candidates: []Candidate{project, other},
+			dirs:       []string{"/home/me", "/src/other/"},
+			want:       other,
+		},
+		{
+			name:       "nested cwd",
+			candidates: []Candidate{nested, other},
+			dirs:       []string{"/src/project"},
+			want:       nested,
+		},
+		{
+			name:       "no match",
+			candidates: []Candidate{project, other},
+			dirs:       []string{"/home/me"},
+			wantErr:    ErrInstanceNotFound,
+		},
+		{
+			name:       "no candidates",
+			candidates: nil,
+			dirs:       []string{"/src/project"},
+			wantErr:    ErrInstanceNotFound,
+		},
+		{
+			name:       "same cwd twice",
+			candidates: []Candidate{project, twin},
+			dirs:       []string{"/src/project"},
+			wantErr:    ErrAmbiguousInstance,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SelectCandidate(tt.candidates, tt.dirs)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+
+				var discoveryErr *DiscoveryError
+				require.ErrorAs(t, err, &discoveryErr)
+				for _, candidate := range discoveryErr.Candidates {
+					assert.Contains(t, err.Error(), candidate.Address)
+				}
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	dir := tempSocketDir(t)
+	socketPath := filepath.Join(dir, "nvim.1.0")
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+
+	cmd := startTestNeovim(t, socketPath)
+
+	t.Run("probes neovim", func(t *testing.T) {
+		candidate, err := ProbeSocket(t.Context(), socketPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, socketPath, candidate.Address)
+		assert.Equal(t, cmd.Process.Pid, candidate.PID)
+		assert.Equal(t, resolvePath(t, cwd), resolvePath(t, candidate.Cwd))
+	})
+
+	t.Run("finds the instance working in the client directory", func(t *testing.T) {
+		candidate, err := Discover(t.Context(), DiscoverOptions{
+			Dirs:     []string{cwd},
+			Patterns: []string{filepath.Join(dir, "nvim.*")},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, socketPath, candidate.Address)
+	})
+
+	t.Run("prefers the inherited instance", func(t *testing.T) {
+		candidate, err := Discover(t.Context(), DiscoverOptions{
+			Dirs:      []string{"/nonexistent"},
+			Inherited: socketPath,
+			Patterns:  []string{filepath.Join(dir, "missing.*")},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, socketPath, candidate.Address)
+	})
+
+	t.Run("lists candidates when none matches", func(t *testing.T) {
+		_, err := Discover(t.Context(), DiscoverOptions{
+			Dirs:     []string{"/nonexistent"},
+			Patterns: []string{filepath.Join(dir, "nvim.*")},
+		})
+
+		require.ErrorIs(t, err, ErrInstanceNotFound)
+		assert.Contains(t, err.Error(), socketPath)
+	})
+}
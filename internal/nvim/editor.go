@@ -0,0 +1,112 @@
+package nvim
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/neovim/go-client/nvim"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// luaGetEditorConfig describes the running editor, its directories and option values
+const luaGetEditorConfig = `
+	local version = vim.api.nvim_get_api_info()[2].version
+	local buf = vim.api.nvim_get_current_buf()
+	local global_options, buffer_options = {}, {}
+	for name, info in pairs(vim.api.nvim_get_all_options_info()) do
+		local ok, value = pcall(vim.api.nvim_get_option_value, name, { scope = 'global' })
+		if ok then
+			global_options[name] = value
+		end
+		if info.scope == 'buf' then
+			local bok, bvalue = pcall(vim.api.nvim_get_option_value, name, { buf = buf })
+			if bok then
+				buffer_options[name] = bvalue
+			end
+		end
+	end
+	return {
+		major = version.major,
+		minor = version.minor,
+		patch = version.patch,
+		prerelease = version.prerelease and true or false,
+		api_level = version.api_level,
+		api_compatible = version.api_compatible,
+		init_file = vim.env.MYVIMRC or '',
+		runtimepath = vim.opt.runtimepath:get(),
+		config_dir = vim.fn.stdpath('config'),
+		data_dir = vim.fn.stdpath('data'),
+		state_dir = vim.fn.stdpath('state'),
+		leader = vim.g.mapleader or '\\',
+		local_leader = vim.g.maplocalleader or '\\',
+		cwd = vim.fn.getcwd(),
+		buffer = vim.api.nvim_buf_get_name(buf),
+		global_options = global_options,
+		buffer_options = buffer_options,
+	}
+`
+
+// luaEditorConfig mirrors the table returned by luaGetEditorConfig
+type luaEditorConfig struct {
+	Major         int            `msgpack:"major"`
+	Minor         int            `msgpack:"minor"`
+	Patch         int            `msgpack:"patch"`
+	Prerelease    bool           `msgpack:"prerelease"`
+	APILevel      int            `msgpack:"api_level"`
+	APICompatible int            `msgpack:"api_compatible"`
+	InitFile      string         `msgpack:"init_file"`
+	Runtimepath   []string       `msgpack:"runtimepath"`
+	ConfigDir     string         `msgpack:"config_dir"`
+	DataDir       string         `msgpack:"data_dir"`
+	StateDir      string         `msgpack:"state_dir"`
+	Leader        string         `msgpack:"leader"`
+	LocalLeader   string         `msgpack:"local_leader"`
+	Cwd           string         `msgpack:"cwd"`
+	Buffer        string         `msgpack:"buffer"`
+	GlobalOptions map[string]any `msgpack:"global_options"`
+	BufferOptions map[string]any `msgpack:"buffer_options"`
+}
+
+// GetEditorConfig describes the running neovim instance and its configuration
+func (c *Client) GetEditorConfig(ctx context.Context) (types.EditorConfig, error) {
+	if err := ctx.Err(); err != nil {
+		return types.EditorConfig{}, fmt.Errorf("failed to get editor config: %w", err)
+	}
+
+	var cfg luaEditorConfig
+	err := c.rpc(ctx, func(v *nvim.Nvim) error {
+		return v.ExecLua(luaGetEditorConfig, &cfg)
+	})
+	if err != nil {
+		return types.EditorConfig{}, fmt.Errorf("failed to get editor config: %w", err)
+	}
+
+	version := fmt.Sprintf("%d.%d.%d", cfg.Major, cfg.Minor, cfg.Patch)
+	if cfg.Prerelease {
+		version += "-dev"
+	}
+
+	return types.EditorConfig{
+		Version: types.EditorVersion{
+			Version:       version,
+			Major:         cfg.Major,
+			Minor:         cfg.Minor,
+			Patch:         cfg.Patch,
+			Prerelease:    cfg.Prerelease,
+			APILevel:      cfg.APILevel,
+			APICompatible: cfg.APICompatible,
+		},
+		InitFile:      cfg.InitFile,
+		Runtimepath:   cfg.Runtimepath,
+		ConfigDir:     cfg.ConfigDir,
+		DataDir:       cfg.DataDir,
+		StateDir:      cfg.StateDir,
+		Leader:        cfg.Leader,
+		LocalLeader:   cfg.LocalLeader,
+		Cwd:           cfg.Cwd,
+		Buffer:        cfg.Buffer,
+		GlobalOptions: cfg.GlobalOptions,
+		BufferOptions: cfg.BufferOptions,
+	}, nil
+}
@@ -0,0 +1,59 @@
+package nvim
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- Editor Operations Tests ---
+
+func TestClient_GetEditorConfig(t *testing.T) {
+	client, cleanup := setupTestNeovim(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	t.Run("reports version and directories", func(t *testing.T) {
+		cfg, err := client.GetEditorConfig(ctx)
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, cfg.Version.Version)
+		assert.Positive(t, cfg.Version.APILevel)
+		assert.NotEmpty(t, cfg.Runtimepath)
+		assert.NotEmpty(t, cfg.ConfigDir)
+		assert.NotEmpty(t, cfg.DataDir)
+		assert.NotEmpty(t, cfg.StateDir)
+		assert.NotEmpty(t, cfg.Cwd)
+	})
+
+	t.Run("reports leader keys", func(t *testing.T) {
+		_, err := client.ExecLua(ctx, `vim.g.mapleader = ' '`, nil)
+		require.NoError(t, err)
+
+		cfg, err := client.GetEditorConfig(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, " ", cfg.Leader)
+		assert.Equal(t, `\`, cfg.LocalLeader)
+	})
+
+	t.Run("reports global and buffer options", func(t *testing.T) {
+		tmpFile := createTempFile(t, "content")
+
+		_, err := client.OpenBuffer(ctx, tmpFile)
+		require.NoError(t, err)
+
+		_, err = client.ExecCommand(ctx, "setlocal shiftwidth=7")
+		require.NoError(t, err)
+
+		cfg, err := client.GetEditorConfig(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, resolvePath(t, tmpFile), cfg.Buffer)
+		assert.Contains(t, cfg.GlobalOptions, "tabstop")
+		assert.EqualValues(t, 7, cfg.BufferOptions["shiftwidth"])
+	})
+}
@@ -0,0 +1,460 @@
+package nvim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/neovim/go-client/nvim"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// luaApplyEdits applies the edits of a transaction in order unless a buffer changed
+// since its changedtick. The edits of each buffer are joined into one undo step and a
+// failing edit undoes the buffers edited before it back to their undo state before the
+// transaction, listing in kept those whose content it could not restore (such as with
+// 'undolevels' set to -1). It returns the new changedticks in the order of ticks.
+const luaApplyEdits = `
+	local edits, ticks = ...
+	for _, t in ipairs(ticks) do
+		if vim.api.nvim_buf_get_changedtick(t[1]) ~= t[2] then
+			return { stale = true, failed = 0, error = '', versions = {}, kept = {} }
+		end
+	end
+	local saved, edited, order = {}, {}, {}
+	for i, e in ipairs(edits) do
+		local buf = e[1]
+		if not saved[buf] then
+			saved[buf] = {
+				seq = vim.api.nvim_buf_call(buf, function()
+					return vim.fn.undotree().seq_cur
+				end),
+				lines = vim.api.nvim_buf_get_lines(buf, 0, -1, true),
+			}
+		end
+		local ok, err = pcall(vim.api.nvim_buf_call, buf, function()
+			if edited[buf] then
+				pcall(vim.cmd.undojoin)
+			end
+			if #e == 4 then
+				vim.api.nvim_buf_set_lines(buf, e[2], e[3], true, e[4])
+			else
+				vim.api.nvim_buf_set_text(buf, e[2], e[3], e[4], e[5], e[6])
+			end
+		end)
+		if not ok then
+			local kept = {}
+			for j = #order, 1, -1 do
+				local b = order[j]
+				pcall(vim.api.nvim_buf_call, b, function()
+					vim.cmd('silent undo ' .. saved[b].seq)
+				end)
+				if not vim.deep_equal(vim.api.nvim_buf_get_lines(b, 0, -1, true), saved[b].lines) then
+					table.insert(kept, b)
+				end
+			end
+			return { stale = false, failed = i, error = tostring(err), versions = {}, kept = kept }
+		end
+		if not edited[buf] then
+			edited[buf] = true
+			table.insert(order, buf)
+		end
+	end
+	local versions = {}
+	for _, t in ipairs(ticks) do
+		table.insert(versions, vim.api.nvim_buf_get_changedtick(t[1]))
+	end
+	return { stale = false, failed = 0, error = '', versions = versions, kept = {} }
+`
+
+// bufferState is the content of a buffer as the edits of a transaction change it
+type bufferState struct {
+	info       types.BufferInfo
+	lines      []string
+	tick       int
+	modifiable bool
+	edits      int
+}
+
+// txEdit is an edit of a transaction in the coordinates of its buffer at the time it
+// applies, either whole lines or a byte range
+type txEdit struct {
+	buf  nvim.Buffer
+	line *lineEdit
+	text *textEdit
+}
+
+// editsOutcome is the result of luaApplyEdits
+type editsOutcome struct {
+	Stale    bool   `msgpack:"stale"`
+	Failed   int    `msgpack:"failed"`
+	Error    string `msgpack:"error"`
+	Versions []int  `msgpack:"versions"`
+	Kept     []int  `msgpack:"kept"`
+}
+
+// ApplyEdits applies an ordered list of edits across buffers as one transaction. Every
+// edit is validated against the buffers as the previous edits leave them before any is
+// applied, and when neovim rejects an edit the buffers edited before it are undone. The
+// error tells which buffers keep their edits when the undo fails.
+// The edits of each buffer form one undo step, and an edit with an expected version fails
+// the transaction with a VersionConflictError when its buffer changed since.
+func (c *Client) ApplyEdits(ctx context.Context, edits []types.BufferEdit) (types.ApplyEditsResult, error) {
+	if err := ctx.Err(); err != nil {
+		return types.ApplyEditsResult{}, fmt.Errorf("failed to apply edits: %w", err)
+	}
+
+	if len(edits) == 0 {
+		return types.ApplyEditsResult{}, fmt.Errorf("failed to apply edits: %w: no edits given", ErrInvalidEdit)
+	}
+
+	edits, err := c.anchorEdits(ctx, edits)
+	if err != nil {
+		return types.ApplyEditsResult{}, fmt.Errorf("failed to apply edits: %w", err)
+	}
+
+	infos, err := c.editBuffers(ctx, edits)
+	if err != nil {
+		return types.ApplyEditsResult{}, fmt.Errorf("failed to apply edits: %w", err)
+	}
+
+	for range editAttempts {
+		states, serr := c.readBufferStates(ctx, infos)
+		if serr != nil {
+			return types.ApplyEditsResult{}, fmt.Errorf("failed to apply edits: %w", serr)
+		}
+
+		planned, perr := planEdits(edits, states)
+		if perr != nil {
+			return types.ApplyEditsResult{}, fmt.Errorf("failed to apply edits: %w", perr)
+		}
+
+		err = c.applyTransaction(ctx, planned, states)
+		if errors.Is(err, errStaleBuffer) {
+			continue
+		}
+
+		if err != nil {
+			return types.ApplyEditsResult{}, fmt.Errorf("failed to apply edits: %w", err)
+		}
+
+		return editsResult(states), nil
+	}
+
+	return types.ApplyEditsResult{}, fmt.Errorf("failed to apply edits: %w", ErrBufferChanged)
+}
+
+// ----------------------------------------------------------------------------
+
+// anchorEdits replaces the anchors of edits with their buffer and position. Anchors are
+// resolved before any edit applies, so an anchored edit should come before other edits
+// of its buffer that move it.
+func (c *Client) anchorEdits(ctx context.Context, edits []types.BufferEdit) ([]types.BufferEdit, error) {
+	resolved := make([]types.BufferEdit, len(edits))
+
+	for i, edit := range edits {
+		resolved[i] = edit
+		if edit.Anchor == "" {
+			continue
+		}
+
+		anchor, err := c.ResolveAnchor(ctx, edit.Anchor)
+		if err != nil {
+			return nil, fmt.Errorf("edit %d: %w", i+1, err)
+		}
+
+		resolved[i].BufferTitle = strconv.Itoa(int(anchor.Buffer))
+
+		switch edit.Kind {
+		case types.EditKindLines:
+			resolved[i].StartLine, resolved[i].EndLine = anchor.Range.StartLine, anchor.Range.EndLine
+		case types.EditKindRange:
+			resolved[i].Range, resolved[i].Encoding = &anchor.Range, PositionEncodingByte
+		}
+	}
+
+	return resolved, nil
+}
+
+// editBuffers resolves the buffer of each edit, looking each title up once
+func (c *Client) editBuffers(ctx context.Context, edits []types.BufferEdit) ([]types.BufferInfo, error) {
+	infos := make([]types.BufferInfo, len(edits))
+	byTitle := make(map[string]types.BufferInfo)
+
+	for i, edit := range edits {
+		info, ok := byTitle[edit.BufferTitle]
+		if !ok {
+			var err error
+			if info, err = c.GetBufferByTitle(ctx, edit.BufferTitle); err != nil {
+				return nil, fmt.Errorf("edit %d: buffer `%s`: %w", i+1, edit.BufferTitle, err)
+			}
+
+			byTitle[edit.BufferTitle] = info
+		}
+
+		infos[i] = info
+	}
+
+	return infos, nil
+}
+
+// readBufferStates reads the edited buffers, the state of edit i is states[i] and edits
+// of the same buffer share their state
+func (c *Client) readBufferStates(ctx context.Context, infos []types.BufferInfo) ([]*bufferState, error) {
+	states := make([]*bufferState, len(infos))
+	byBuffer := make(map[nvim.Buffer]*bufferState)
+
+	for i, info := range infos {
+		state, ok := byBuffer[info.Handle]
+		if !ok {
+			var byteLines [][]byte
+
+			state = &bufferState{info: info}
+
+			err := c.batch(ctx, func(b *nvim.Batch) {
+				b.BufferLines(info.Handle, 0, -1, true, &byteLines)
+				b.BufferChangedTick(info.Handle, &state.tick)
+				b.BufferOption(info.Handle, "modifiable", &state.modifiable)
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to read buffer `%s`: %w", info.Path, err)
+			}
+
+			state.lines = make([]string, len(byteLines))
+			for j, line := range byteLines {
+				state.lines[j] = string(line)
+			}
+
+			byBuffer[info.Handle] = state
+		}
+
+		states[i] = state
+	}
+
+	return states, nil
+}
+
+// applyTransaction applies planned edits in a single call and updates the changedticks
+// of the states, failing with errStaleBuffer when a buffer changed since it was read
+func (c *Client) applyTransaction(ctx context.Context, planned []txEdit, states []*bufferState) error {
+	luaEdits := make([]any, len(planned))
+	for i, e := range planned {
+		if e.line != nil {
+			luaEdits[i] = []any{e.buf, e.line.start, e.line.end, e.line.lines}
+		} else {
+			luaEdits[i] = []any{e.buf, e.text.startRow, e.text.startCol, e.text.endRow, e.text.endCol, e.text.lines}
+		}
+	}
+
+	var (
+		ticks  []any
+		unique []*bufferState
+	)
+
+	seen := make(map[*bufferState]bool)
+	for _, state := range states {
+		if !seen[state] {
+			seen[state] = true
+			unique = append(unique, state)
+			ticks = append(ticks, []any{state.info.Handle, state.tick})
+		}
+	}
+
+	var outcome editsOutcome
+	err := c.rpc(ctx, func(v *nvim.Nvim) error {
+		return v.ExecLua(luaApplyEdits, &outcome, luaEdits, ticks)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply edits: %w", err)
+	}
+
+	switch {
+	case outcome.Stale:
+		return errStaleBuffer
+	case outcome.Failed > 0 && len(outcome.Kept) > 0:
+		var kept []string
+		for _, state := range unique {
+			if slices.Contains(outcome.Kept, int(state.info.Handle)) {
+				kept = append(kept, "`"+state.info.Path+"`")
+			}
+		}
+
+		return fmt.Errorf("%w: edit %d in `%s`: %s, and the rollback failed, %s keep the earlier edits (is 'undolevels' negative?)",
+			ErrEditFailed, outcome.Failed, states[outcome.Failed-1].info.Path, outcome.Error, strings.Join(kept, ", "))
+	case outcome.Failed > 0:
+		return fmt.Errorf("%w: edit %d in `%s`, all edits were rolled back: %s",
+			ErrEditFailed, outcome.Failed, states[outcome.Failed-1].info.Path, outcome.Error)
+	default:
+		for i, version := range outcome.Versions {
+			unique[i].tick = version
+		}
+
+		return nil
+	}
+}
+
+// planEdits validates the edits in order against their buffer states and converts them
+// to the positions at which they apply, updating the states as it goes
+func planEdits(edits []types.BufferEdit, states []*bufferState) ([]txEdit, error) {
+	// versions refer to the buffers before the transaction
+	for i, edit := range edits {
+		if edit.ExpectedVersion != 0 && edit.ExpectedVersion != states[i].tick {
+			return nil, fmt.Errorf("edit %d: %w", i+1, editConflict(edit, states[i]))
+		}
+	}
+
+	planned := make([]txEdit, len(edits))
+
+	for i, edit := range edits {
+		state := states[i]
+
+		if !state.modifiable {
+			return nil, fmt.Errorf("edit %d: %w: buffer `%s` is not modifiable", i+1, ErrInvalidEdit, edit.BufferTitle)
+		}
+
+		tx, err := planEdit(edit, state)
+		if err != nil {
+			return nil, fmt.Errorf("edit %d (%s in `%s`): %w", i+1, edit.Kind, edit.BufferTitle, err)
+		}
+
+		tx.buf = state.info.Handle
+		planned[i] = tx
+		state.edits++
+	}
+
+	return planned, nil
+}
+
+// planEdit converts an edit to the positions of the buffer state and applies it to the state
+func planEdit(edit types.BufferEdit, state *bufferState) (txEdit, error) {
+	switch edit.Kind {
+	case types.EditKindLines:
+		if edit.StartLine < 1 || edit.EndLine < edit.StartLine-1 || edit.EndLine > len(state.lines) {
+			return txEdit{}, fmt.Errorf("%w: lines %d to %d of a buffer with %d lines",
+				ErrInvalidRange, edit.StartLine, edit.EndLine, len(state.lines))
+		}
+
+		e := lineEdit{start: edit.StartLine - 1, end: edit.EndLine, lines: edit.Lines}
+		if e.lines == nil {
+			e.lines = []string{}
+		}
+
+		state.lines = spliceLines(state.lines, e)
+
+		return txEdit{line: &e}, nil
+	case types.EditKindRange:
+		if edit.Range == nil {
+			return txEdit{}, fmt.Errorf("%w: range is required", ErrInvalidEdit)
+		}
+
+		if err := ValidateEncoding(edit.Encoding); err != nil {
+			return txEdit{}, err
+		}
+
+		rng, err := toByteRange(*edit.Range, spannedLines(state.lines, *edit.Range), len(state.lines), edit.Encoding)
+		if err != nil {
+			return txEdit{}, err
+		}
+
+		e := textEdit{byteRange: rng, lines: strings.Split(edit.Text, "\n")}
+		state.lines = spliceText(state.lines, e)
+
+		return txEdit{text: &e}, nil
+	case types.EditKindReplace:
+		if edit.OldText == "" {
+			return txEdit{}, fmt.Errorf("%w: old text is empty", ErrTextNotFound)
+		}
+
+		content := strings.Join(state.lines, "\n")
+
+		offsets, err := selectOccurrences(content, edit.OldText, types.ReplaceTextOptions{})
+		if err != nil {
+			return txEdit{}, err
+		}
+
+		replaced, _ := replaceOccurrences(content, edit.OldText, edit.NewText, offsets)
+		e := replaced[0]
+		state.lines = spliceText(state.lines, e)
+
+		return txEdit{text: &e}, nil
+	default:
+		return txEdit{}, fmt.Errorf("%w: unknown kind `%s`, expected %s, %s or %s", ErrInvalidEdit,
+			edit.Kind, types.EditKindLines, types.EditKindRange, types.EditKindReplace)
+	}
+}
+
+// editConflict reports an edit expecting an outdated version with the current lines it targets
+func editConflict(edit types.BufferEdit, state *bufferState) *VersionConflictError {
+	conflict := &VersionConflictError{Buffer: edit.BufferTitle, Expected: edit.ExpectedVersion, Current: state.tick}
+
+	switch edit.Kind {
+	case types.EditKindLines:
+		start, end := max(edit.StartLine, 1), min(edit.EndLine, len(state.lines))
+		if start <= end {
+			conflict.StartLine, conflict.Lines = start, state.lines[start-1:end]
+		}
+	case types.EditKindRange:
+		if edit.Range != nil {
+			if lines := spannedLines(state.lines, *edit.Range); lines != nil {
+				conflict.StartLine, conflict.Lines = edit.Range.StartLine, lines
+			}
+		}
+	case types.EditKindReplace:
+		return occurrenceConflict(edit.BufferTitle, edit.ExpectedVersion, state.tick, state.lines, edit.OldText)
+	}
+
+	return conflict
+}
+
+// spannedLines returns the lines of rng, or nil when rng is outside lines
+func spannedLines(lines []string, rng types.TextRange) []string {
+	if rng.StartLine < 1 || rng.EndLine < rng.StartLine || rng.EndLine > len(lines) {
+		return nil
+	}
+
+	return lines[rng.StartLine-1 : rng.EndLine]
+}
+
+// spliceLines returns lines with a line edit applied
+func spliceLines(lines []string, e lineEdit) []string {
+	result := make([]string, 0, len(lines)-(e.end-e.start)+len(e.lines))
+	result = append(result, lines[:e.start]...)
+	result = append(result, e.lines...)
+
+	return append(result, lines[e.end:]...)
+}
+
+// spliceText returns lines with a text edit applied
+func spliceText(lines []string, e textEdit) []string {
+	replacement := make([]string, len(e.lines))
+	copy(replacement, e.lines)
+
+	replacement[0] = lines[e.startRow][:e.startCol] + replacement[0]
+	replacement[len(replacement)-1] += lines[e.endRow][e.endCol:]
+
+	return spliceLines(lines, lineEdit{start: e.startRow, end: e.endRow + 1, lines: replacement})
+}
+
+// editsResult reports the edited buffers in the order of their first edit
+func editsResult(states []*bufferState) types.ApplyEditsResult {
+	result := types.ApplyEditsResult{Applied: len(states)}
+
+	seen := make(map[*bufferState]bool)
+	for _, state := range states {
+		if !seen[state] {
+			seen[state] = true
+			result.Buffers = append(result.Buffers, types.EditedBuffer{
+				Buffer:  state.info.Handle,
+				Name:    state.info.Path,
+				Edits:   state.edits,
+				Version: state.tick,
+			})
+		}
+	}
+
+	return result
+}
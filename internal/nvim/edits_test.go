@@ -0,0 +1,210 @@
+package nvim
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestPlanEdits(t *testing.T) {
+	newStates := func() (*bufferState, *bufferState) {
+		return &bufferState{info: types.BufferInfo{Handle: 1}, lines: []string{"a := 1", "b := 2", "c := 3"}, modifiable: true},
+			&bufferState{info: types.BufferInfo{Handle: 2}, lines: []string{"naïve x"}, modifiable: true}
+	}
+
+	t.Run("applies edits in order", func(t *testing.T) {
+		first, second := newStates()
+
+		edits := []types.BufferEdit{
+			{BufferTitle: "a", Kind: types.EditKindLines, StartLine: 2, EndLine: 2, Lines: []string{"b := 20", "bb := 21"}},
+			{BufferTitle: "b", Kind: types.EditKindRange, Range: &types.TextRange{StartLine: 1, StartColumn: 7, EndLine: 1, EndColumn: 8}, Text: "y", Encoding: PositionEncodingCodepoint},
+			{BufferTitle: "a", Kind: types.EditKindReplace, OldText: "bb := 21\nc", NewText: "d"},
+			{BufferTitle: "a", Kind: types.EditKindLines, StartLine: 1, EndLine: 0, Lines: []string{"// header"}},
+		}
+
+		planned, err := planEdits(edits, []*bufferState{first, second, first, first})
+		require.NoError(t, err)
+		require.Len(t, planned, 4)
+
+		assert.Equal(t, []string{"// header", "a := 1", "b := 20", "d := 3"}, first.lines)
+		assert.Equal(t, []string{"naïve y"}, second.lines)
+		assert.Equal(t, 3, first.edits)
+
+		assert.Equal(t, &lineEdit{start: 1, end: 2, lines: []string{"b := 20", "bb := 21"}}, planned[0].line)
+		assert.Equal(t, byteRange{startRow: 0, startCol: 7, endRow: 0, endCol: 8}, planned[1].text.byteRange)
+		assert.Equal(t, byteRange{startRow: 2, startCol: 0, endRow: 3, endCol: 1}, planned[2].text.byteRange)
+		assert.Equal(t, first.info.Handle, planned[2].buf)
+	})
+
+	tests := []struct {
+		name    string
+		edit    types.BufferEdit
+		wantErr error
+	}{
+		{name: "line range past the end", edit: types.BufferEdit{Kind: types.EditKindLines, StartLine: 3, EndLine: 4}, wantErr: ErrInvalidRange},
+		{name: "missing range", edit: types.BufferEdit{Kind: types.EditKindRange, Text: "x"}, wantErr: ErrInvalidEdit},
+		{name: "bad column", edit: types.BufferEdit{Kind: types.EditKindRange, Range: &types.TextRange{StartLine: 1, StartColumn: 9, EndLine: 1, EndColumn: 9}}, wantErr: ErrInvalidRange},
+		{name: "missing text", edit: types.BufferEdit{Kind: types.EditKindReplace, OldText: "z := 4"}, wantErr: ErrTextNotFound},
+		{name: "ambiguous text", edit: types.BufferEdit{Kind: types.EditKindReplace, OldText: ":="}, wantErr: ErrAmbiguousText},
+		{name: "unknown kind", edit: types.BufferEdit{Kind: "move"}, wantErr: ErrInvalidEdit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, _ := newStates()
+			valid := types.BufferEdit{Kind: types.EditKindLines, StartLine: 1, EndLine: 1, Lines: []string{"x"}}
+
+			_, err := planEdits([]types.BufferEdit{valid, tt.edit}, []*bufferState{first, first})
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.ErrorContains(t, err, "edit 2")
+		})
+	}
+
+	t.Run("rejects outdated versions", func(t *testing.T) {
+		first, _ := newStates()
+		first.tick = 9
+
+		edits := []types.BufferEdit{
+			{BufferTitle: "a", Kind: types.EditKindLines, StartLine: 1, EndLine: 1, Lines: []string{"x"}, ExpectedVersion: 9},
+			{BufferTitle: "a", Kind: types.EditKindLines, StartLine: 2, EndLine: 3, Lines: []string{"y"}, ExpectedVersion: 8},
+		}
+
+		_, err := planEdits(edits, []*bufferState{first, first})
+
+		var conflict *VersionConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, 9, conflict.Current)
+		assert.Equal(t, 2, conflict.StartLine)
+		assert.Equal(t, []string{"b := 2", "c := 3"}, conflict.Lines)
+		assert.Equal(t, []string{"a := 1", "b := 2", "c := 3"}, first.lines)
+	})
+
+	t.Run("rejects unmodifiable buffers", func(t *testing.T) {
+		first, _ := newStates()
+		first.modifiable = false
+
+		_, err := planEdits([]types.BufferEdit{{Kind: types.EditKindLines, StartLine: 1, EndLine: 1}}, []*bufferState{first})
+		assert.ErrorIs(t, err, ErrInvalidEdit)
+	})
+}
+
+func TestClient_ApplyEdits(t *testing.T) {
+	client, cleanup := setupTestNeovim(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	open := func(t *testing.T, content string) string {
+		t.Helper()
+
+		tmpFile := createTempFile(t, content)
+		_, err := client.OpenBuffer(ctx, tmpFile)
+		require.NoError(t, err)
+
+		return filepath.Base(tmpFile)
+	}
+
+	t.Run("edits several buffers", func(t *testing.T) {
+		first, second := open(t, "one\ntwo"), open(t, "alpha beta")
+
+		result, err := client.ApplyEdits(ctx, []types.BufferEdit{
+			{BufferTitle: first, Kind: types.EditKindLines, StartLine: 2, EndLine: 2, Lines: []string{"TWO"}},
+			{BufferTitle: second, Kind: types.EditKindReplace, OldText: "beta", NewText: "gamma"},
+			{BufferTitle: first, Kind: types.EditKindRange, Range: &types.TextRange{StartLine: 1, StartColumn: 1, EndLine: 1, EndColumn: 4}, Text: "ONE"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.Applied)
+		require.Len(t, result.Buffers, 2)
+		assert.Equal(t, 2, result.Buffers[0].Edits)
+
+		lines, _, err := client.GetBufferLines(ctx, first, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ONE", "TWO"}, lines)
+
+		lines, _, err = client.GetBufferLines(ctx, second, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"alpha gamma"}, lines)
+
+		// the edits of a buffer are undone together
+		require.NoError(t, client.SwitchBuffer(ctx, first))
+		_, err = client.ExecCommand(ctx, "undo")
+		require.NoError(t, err)
+
+		lines, _, err = client.GetBufferLines(ctx, first, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"one", "two"}, lines)
+	})
+
+	t.Run("applies nothing when an edit is invalid", func(t *testing.T) {
+		first, second := open(t, "keep"), open(t, "also keep")
+
+		_, err := client.ApplyEdits(ctx, []types.BufferEdit{
+			{BufferTitle: first, Kind: types.EditKindLines, StartLine: 1, EndLine: 1, Lines: []string{"changed"}},
+			{BufferTitle: second, Kind: types.EditKindReplace, OldText: "missing", NewText: "x"},
+		})
+		require.ErrorIs(t, err, ErrTextNotFound)
+
+		lines, _, err := client.GetBufferLines(ctx, first, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"keep"}, lines)
+	})
+	t.Run("rolls back when neovim rejects an edit", func(t *testing.T) {
+		apply := func(t *testing.T, title string) (editsOutcome, []string) {
+			t.Helper()
+
+			buf, err := client.GetBufferByTitle(ctx, title)
+			require.NoError(t, err)
+
+			var tick int
+			require.NoError(t, client.batch(ctx, func(b *nvim.Batch) { b.BufferChangedTick(buf.Handle, &tick) }))
+
+			// the second edit starts past the end of the buffer
+			luaEdits := []any{
+				[]any{buf.Handle, 0, 1, []string{"changed"}},
+				[]any{buf.Handle, 5, 6, []string{"invalid"}},
+			}
+
+			var outcome editsOutcome
+			require.NoError(t, client.rpc(ctx, func(v *nvim.Nvim) error {
+				return v.ExecLua(luaApplyEdits, &outcome, luaEdits, []any{[]any{buf.Handle, tick}})
+			}))
+
+			lines, _, err := client.GetBufferLines(ctx, title, 1, -1)
+			require.NoError(t, err)
+
+			return outcome, lines
+		}
+
+		t.Run("restores the undo state", func(t *testing.T) {
+			title := open(t, "keep")
+
+			outcome, lines := apply(t, title)
+
+			assert.Equal(t, 2, outcome.Failed)
+			assert.Empty(t, outcome.Kept)
+			assert.Equal(t, []string{"keep"}, lines)
+		})
+
+		t.Run("reports buffers it cannot restore", func(t *testing.T) {
+			title := open(t, "keep")
+			require.NoError(t, client.SwitchBuffer(ctx, title))
+			_, err := client.ExecCommand(ctx, "setlocal undolevels=-1")
+			require.NoError(t, err)
+
+			buf, err := client.GetBufferByTitle(ctx, title)
+			require.NoError(t, err)
+
+			outcome, lines := apply(t, title)
+
+			assert.Equal(t, 2, outcome.Failed)
+			assert.Equal(t, []int{int(buf.Handle)}, outcome.Kept)
+			assert.Equal(t, []string{"changed"}, lines)
+		})
+	})
+}
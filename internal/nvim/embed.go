@@ -0,0 +1,101 @@
+package nvim
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/neovim/go-client/nvim"
+
+	"github.com/cousine/neovim-mcp/internal/logger"
+)
+
+// EmbedAddress is the connection address reported by embedded clients without a socket
+const EmbedAddress = "embedded"
+
+// EmbedConfig configures the neovim child process started by NewEmbeddedClient
+type EmbedConfig struct {
+	// Command is the neovim executable, nvim when empty
+	Command string
+	// InitFile is loaded instead of the user's init file when set, NONE skips it
+	InitFile string
+	// Dir is the working directory of neovim, the current directory when empty
+	Dir string
+	// Listen is a socket neovim serves for `nvim --remote-ui --server`, none when empty
+	Listen string
+}
+
+// NewEmbeddedClient starts a headless neovim as a child process and returns a client
+// talking to it over its stdin and stdout. Close shuts the child down. With WithReconnect
+// a child that exits is started again.
+func NewEmbeddedClient(cfg EmbedConfig, opts ...Option) (*Client, error) {
+	address := cfg.Listen
+	if address == "" {
+		address = EmbedAddress
+	}
+
+	return NewClient(address, append(opts, withDialer(cfg.start))...)
+}
+
+// ----------------------------------------------------------------------------
+
+// start runs a new neovim child process without serving its connection
+func (cfg EmbedConfig) start(ctx context.Context) (*nvim.Nvim, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to start neovim: %w", err)
+	}
+
+	if cfg.Listen != "" {
+		removeStaleSocket(cfg.Listen)
+	}
+
+	opts := []nvim.ChildProcessOption{
+		nvim.ChildProcessArgs(cfg.args()...),
+		nvim.ChildProcessDir(cfg.Dir),
+		nvim.ChildProcessServe(false),
+	}
+	if cfg.Command != "" {
+		opts = append(opts, nvim.ChildProcessCommand(cfg.Command))
+	}
+
+	v, err := nvim.NewChildProcess(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start neovim: %w", err)
+	}
+
+	return v, nil
+}
+
+// args returns the neovim command line, --headless keeps --embed from waiting for a UI
+func (cfg EmbedConfig) args() []string {
+	args := []string{"--embed", "--headless"}
+
+	if cfg.InitFile != "" {
+		args = append(args, "-u", cfg.InitFile)
+	}
+
+	if cfg.Listen != "" {
+		args = append(args, "--listen", cfg.Listen)
+	}
+
+	return args
+}
+
+// removeStaleSocket deletes the socket left at path by a neovim that did not exit cleanly
+func removeStaleSocket(path string) {
+	info, err := os.Stat(path)
+	if err != nil || info.Mode()&os.ModeSocket == 0 {
+		return
+	}
+
+	conn, err := net.Dial("unix", path)
+	if err == nil {
+		_ = conn.Close()
+		return
+	}
+
+	if err = os.Remove(path); err != nil {
+		logger.Debug("nvim: failed to remove stale socket", "path", path, "error", err)
+	}
+}
@@ -0,0 +1,120 @@
+package nvim
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbedConfig_Args(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  EmbedConfig
+		want []string
+	}{
+		{
+			name: "defaults",
+			cfg:  EmbedConfig{},
+			want: []string{"--embed", "--headless"},
+		},
+		{
+			name: "init file and socket",
+			cfg:  EmbedConfig{InitFile: "NONE", Listen: "/tmp/agent.sock"},
+			want: []string{"--embed", "--headless", "-u", "NONE", "--listen", "/tmp/agent.sock"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.cfg.args())
+		})
+	}
+}
+
+func TestRemoveStaleSocket(t *testing.T) {
+	dir := tempSocketDir(t)
+
+	t.Run("removes dead sockets", func(t *testing.T) {
+		path := filepath.Join(dir, "dead.sock")
+
+		listener, err := net.Listen("unix", path)
+		require.NoError(t, err)
+		listener.(*net.UnixListener).SetUnlinkOnClose(false)
+		require.NoError(t, listener.Close())
+
+		removeStaleSocket(path)
+
+		assert.NoFileExists(t, path)
+	})
+
+	t.Run("keeps live sockets", func(t *testing.T) {
+		path := filepath.Join(dir, "live.sock")
+
+		listener, err := net.Listen("unix", path)
+		require.NoError(t, err)
+		defer listener.Close()
+
+		removeStaleSocket(path)
+
+		assert.FileExists(t, path)
+	})
+
+	t.Run("keeps other files", func(t *testing.T) {
+		path := filepath.Join(dir, "file")
+		require.NoError(t, os.WriteFile(path, nil, 0o600))
+
+		removeStaleSocket(path)
+
+		assert.FileExists(t, path)
+	})
+}
+
+func TestNewEmbeddedClient(t *testing.T) {
+	ctx := context.Background()
+	socketPath := filepath.Join(tempSocketDir(t), "embed.sock")
+
+	client, err := NewEmbeddedClient(EmbedConfig{InitFile: "NONE", Listen: socketPath})
+	require.NoError(t, err)
+
+	t.Run("talks to the child process", func(t *testing.T) {
+		tmpFile := createTempFile(t, "embedded")
+
+		_, err := client.OpenBuffer(ctx, tmpFile)
+		require.NoError(t, err)
+
+		lines, _, err := client.GetBufferLines(ctx, tmpFile, 0, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"embedded"}, lines)
+	})
+
+	t.Run("serves the listen socket", func(t *testing.T) {
+		attached, err := NewClient(socketPath)
+		require.NoError(t, err)
+		defer attached.Close()
+
+		buffers, err := attached.GetBuffers(ctx)
+		require.NoError(t, err)
+		assert.NotEmpty(t, buffers)
+	})
+
+	t.Run("shuts the child down on close", func(t *testing.T) {
+		require.NoError(t, client.Close())
+
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(socketPath)
+			return os.IsNotExist(err)
+		}, 5*time.Second, 50*time.Millisecond)
+	})
+}
+
+func TestNewEmbeddedClient_MissingCommand(t *testing.T) {
+	_, err := NewEmbeddedClient(EmbedConfig{Command: "/nonexistent/nvim"})
+
+	assert.Error(t, err)
+}
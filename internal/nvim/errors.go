@@ -1,6 +1,10 @@
 package nvim
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 var (
 	// ErrNotConnected is returned when the Neovim client is not connected
@@ -9,6 +13,9 @@ var (
 	// ErrBufferNotFound is returned when a buffer cannot be found
 	ErrBufferNotFound = errors.New("buffer not found")
 
+	// ErrAmbiguousBuffer is returned when a buffer reference matches several buffers
+	ErrAmbiguousBuffer = errors.New("ambiguous buffer reference")
+
 	// ErrInvalidRange is returned when a line range is invalid
 	ErrInvalidRange = errors.New("invalid line range")
 
@@ -17,4 +24,123 @@ var (
 
 	// ErrInvalidBuffer is returned when a buffer handle is invalid
 	ErrInvalidBuffer = errors.New("invalid buffer")
+
+	// ErrInvalidEncoding is returned for an unknown position encoding
+	ErrInvalidEncoding = errors.New("invalid position encoding")
+
+	// ErrTextNotFound is returned when the text to replace does not occur in the buffer
+	ErrTextNotFound = errors.New("text not found")
+
+	// ErrAmbiguousText is returned when the text to replace occurs several times
+	ErrAmbiguousText = errors.New("ambiguous text")
+
+	// ErrBufferChanged is returned when a buffer keeps changing while it is being edited
+	ErrBufferChanged = errors.New("buffer changed during the edit")
+
+	// ErrVersionConflict is returned when a buffer changed since the version a write expects
+	ErrVersionConflict = errors.New("version conflict")
+
+	// ErrInvalidEdit is returned when an edit of a transaction is malformed
+	ErrInvalidEdit = errors.New("invalid edit")
+
+	// ErrEditFailed is returned when neovim rejects an edit and the transaction is rolled back
+	ErrEditFailed = errors.New("edit failed")
+
+	// ErrEditorBlocked is returned when neovim waits for input, such as at a prompt
+	ErrEditorBlocked = errors.New("neovim is waiting for input")
+
+	// ErrKeysFailed is returned when keys run by FeedKeys raise an error
+	ErrKeysFailed = errors.New("keys failed")
+
+	// ErrAnchorNotFound is returned when an anchor does not exist or its buffer is gone
+	ErrAnchorNotFound = errors.New("anchor not found")
+
+	// ErrInvalidPattern is returned when a search pattern does not compile
+	ErrInvalidPattern = errors.New("invalid pattern")
+
+	// ErrNoLanguageServer is returned when no language server attached to a buffer supports a request
+	ErrNoLanguageServer = errors.New("no language server")
+
+	// ErrSymbolNotFound is returned when no language server knows a workspace symbol
+	ErrSymbolNotFound = errors.New("symbol not found")
+
+	// ErrInvalidPatch is returned when a patch is not a unified diff
+	ErrInvalidPatch = errors.New("invalid patch")
+
+	// ErrInstanceNotFound is returned when discovery finds no matching neovim instance
+	ErrInstanceNotFound = errors.New("no matching neovim instance found")
+
+	// ErrAmbiguousInstance is returned when discovery matches several neovim instances
+	ErrAmbiguousInstance = errors.New("several neovim instances match")
 )
+
+// AmbiguousBufferError lists the buffers matched by an ambiguous buffer reference
+type AmbiguousBufferError struct {
+	Reference  string
+	Candidates []string
+}
+
+// Error implements error
+func (e *AmbiguousBufferError) Error() string {
+	return fmt.Sprintf("%s `%s`, use a handle or full path, candidates: %s",
+		ErrAmbiguousBuffer, e.Reference, strings.Join(e.Candidates, ", "))
+}
+
+// Unwrap allows matching with errors.Is(err, ErrAmbiguousBuffer)
+func (e *AmbiguousBufferError) Unwrap() error {
+	return ErrAmbiguousBuffer
+}
+
+// VersionConflictError holds the current lines targeted by a write whose expected
+// buffer version is outdated
+type VersionConflictError struct {
+	Buffer    string
+	Expected  int
+	Current   int
+	StartLine int
+	Lines     []string
+}
+
+// Error implements error
+func (e *VersionConflictError) Error() string {
+	msg := fmt.Sprintf("%s: buffer `%s` is at version %d, not the expected %d", ErrVersionConflict, e.Buffer, e.Current, e.Expected)
+	if len(e.Lines) == 0 {
+		return msg + ", read it again before writing"
+	}
+
+	return fmt.Sprintf("%s, lines %d to %d now read:\n%s",
+		msg, e.StartLine, e.StartLine+len(e.Lines)-1, strings.Join(e.Lines, "\n"))
+}
+
+// Unwrap allows matching with errors.Is(err, ErrVersionConflict)
+func (e *VersionConflictError) Unwrap() error {
+	return ErrVersionConflict
+}
+
+// DiscoveryError lists the running neovim instances when discovery cannot pick one
+type DiscoveryError struct {
+	Err        error
+	Dirs       []string
+	Candidates []Candidate
+}
+
+// Error implements error
+func (e *DiscoveryError) Error() string {
+	if len(e.Candidates) == 0 {
+		return fmt.Sprintf("%s for %s, no running instance was found, start neovim or set NVIM_MCP_LISTEN_ADDRESS",
+			e.Err, strings.Join(e.Dirs, ", "))
+	}
+
+	candidates := make([]string, 0, len(e.Candidates))
+	for _, candidate := range e.Candidates {
+		candidates = append(candidates, candidate.String())
+	}
+
+	return fmt.Sprintf("%s for %s, set NVIM_MCP_LISTEN_ADDRESS to one of: %s",
+		e.Err, strings.Join(e.Dirs, ", "), strings.Join(candidates, ", "))
+}
+
+// Unwrap allows matching with errors.Is(err, ErrInstanceNotFound) and ErrAmbiguousInstance
+func (e *DiscoveryError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,363 @@
+package nvim
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ignoreRule is a pattern of a .gitignore file
+type ignoreRule struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreFile holds the rules of an ignore file. base is the slash separated path of
+// the directory of a .gitignore file below the walked root relative to it, empty for the
+// root. outer is the slash separated path of the walked root relative to the directory
+// the rules of a file outside of it apply to, empty when it is that directory.
+type ignoreFile struct {
+	base  string
+	outer string
+	rules []ignoreRule
+}
+
+// loadIgnoreFile reads the .gitignore file of dir, it returns false when there is none
+func loadIgnoreFile(dir, base string) (ignoreFile, bool) {
+	rules, ok := readIgnoreRules(filepath.Join(dir, ".gitignore"))
+	if !ok {
+		return ignoreFile{}, false
+	}
+
+	return ignoreFile{base: base, rules: rules}, true
+}
+
+// readIgnoreRules reads the rules of the ignore file at path, it returns false when
+// there is none
+func readIgnoreRules(path string) ([]ignoreRule, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	return parseIgnoreRules(string(data)), true
+}
+
+// loadRepoIgnores returns the ignore files that apply to the walked root from outside
+// of it, lowest precedence first: core.excludesFile, .git/info/exclude and the
+// .gitignore files from the top of the repository holding root down to the parent of
+// root. It returns nothing when root is not inside a git repository. The rules apply to
+// the paths below root only, an explicitly walked root is searched even if ignored.
+func loadRepoIgnores(root string) []ignoreFile {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil
+	}
+
+	top, gitDir, ok := findRepository(root)
+	if !ok {
+		return nil
+	}
+
+	outer := func(dir string) string {
+		rel, err := filepath.Rel(dir, root)
+		if err != nil || rel == "." {
+			return ""
+		}
+
+		return filepath.ToSlash(rel)
+	}
+
+	var files []ignoreFile
+
+	for _, path := range []string{excludesFile(gitDir), filepath.Join(gitDir, "info", "exclude")} {
+		if path == "" {
+			continue
+		}
+
+		if rules, ok := readIgnoreRules(path); ok {
+			files = append(files, ignoreFile{outer: outer(top), rules: rules})
+		}
+	}
+
+	// top is an ancestor of root, the walk loads the .gitignore file of root itself
+	var dirs []string
+	for dir := root; dir != top; {
+		dir = filepath.Dir(dir)
+		dirs = append(dirs, dir)
+	}
+
+	for _, dir := range slices.Backward(dirs) {
+		if file, ok := loadIgnoreFile(dir, ""); ok {
+			file.outer = outer(dir)
+			files = append(files, file)
+		}
+	}
+
+	return files
+}
+
+// findRepository returns the top directory of the git repository holding dir and its
+// git directory, following the .git file of worktrees and submodules to the common git
+// directory. It returns false when dir is not inside a repository.
+func findRepository(dir string) (string, string, bool) {
+	for {
+		dotGit := filepath.Join(dir, ".git")
+
+		info, err := os.Stat(dotGit)
+		switch {
+		case err == nil && info.IsDir():
+			return dir, dotGit, true
+		case err == nil && info.Mode().IsRegular():
+			data, rerr := os.ReadFile(dotGit)
+			gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+			if rerr != nil || !ok {
+				return "", "", false
+			}
+
+			gitDir = strings.TrimSpace(gitDir)
+			if !filepath.IsAbs(gitDir) {
+				gitDir = filepath.Join(dir, gitDir)
+			}
+
+			if common, cerr := os.ReadFile(filepath.Join(gitDir, "commondir")); cerr == nil {
+				commonDir := strings.TrimSpace(string(common))
+				if !filepath.IsAbs(commonDir) {
+					commonDir = filepath.Join(gitDir, commonDir)
+				}
+				gitDir = commonDir
+			}
+
+			return dir, filepath.Clean(gitDir), true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", false
+		}
+		dir = parent
+	}
+}
+
+// excludesFile returns the path of the global ignore file: core.excludesFile of the
+// repository, global or XDG git config, else $XDG_CONFIG_HOME/git/ignore. It returns an
+// empty path when neither the setting nor the config directories are known.
+func excludesFile(gitDir string) string {
+	home, _ := os.UserHomeDir()
+
+	xdg := os.Getenv("XDG_CONFIG_HOME")
+	if xdg == "" && home != "" {
+		xdg = filepath.Join(home, ".config")
+	}
+
+	var configs []string
+	if xdg != "" {
+		configs = append(configs, filepath.Join(xdg, "git", "config"))
+	}
+	if home != "" {
+		configs = append(configs, filepath.Join(home, ".gitconfig"))
+	}
+	configs = append(configs, filepath.Join(gitDir, "config"))
+
+	path := ""
+	if xdg != "" {
+		path = filepath.Join(xdg, "git", "ignore")
+	}
+
+	// the last config setting it wins
+	for _, config := range configs {
+		if value, ok := readExcludesFile(config); ok {
+			path = value
+		}
+	}
+
+	if rest, ok := strings.CutPrefix(path, "~/"); ok && home != "" {
+		path = filepath.Join(home, rest)
+	}
+
+	return path
+}
+
+// readExcludesFile reads core.excludesFile from the git config file at path
+func readExcludesFile(path string) (string, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	var value string
+	var found, core bool
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if strings.HasPrefix(line, "[") {
+			section, _, _ := strings.Cut(strings.Trim(line, "[] "), " ")
+			core = strings.EqualFold(section, "core")
+			continue
+		}
+
+		key, val, ok := strings.Cut(line, "=")
+		if !core || !ok || !strings.EqualFold(strings.TrimSpace(key), "excludesfile") {
+			continue
+		}
+
+		val = strings.TrimSpace(val)
+		if strings.HasPrefix(val, `"`) {
+			val, _, _ = strings.Cut(val[1:], `"`)
+		} else if i := strings.IndexAny(val, "#;"); i >= 0 {
+			val = strings.TrimSpace(val[:i])
+		}
+
+		value, found = val, true
+	}
+
+	return value, found
+}
+
+// parseIgnoreRules parses the patterns of a .gitignore file, skipping the ones that
+// do not compile
+func parseIgnoreRules(content string) []ignoreRule {
+	var rules []ignoreRule
+
+	for line := range strings.SplitSeq(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if !strings.HasSuffix(line, `\ `) {
+			line = strings.TrimRight(line, " ")
+		}
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+		if strings.HasPrefix(line, "!") {
+			rule.negate, line = true, line[1:]
+		}
+
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly, line = true, strings.TrimRight(line, "/")
+		}
+
+		re, err := globRegexp(line)
+		if err != nil || line == "" {
+			continue
+		}
+
+		rule.re = re
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
+// globRegexp translates a gitignore glob into a regexp matching slash separated
+// relative paths. A glob without an inner slash matches a name at any depth, one with
+// a slash is anchored to the start of the path.
+func globRegexp(glob string) (*regexp.Regexp, error) {
+	var b strings.Builder
+
+	b.WriteString("^")
+	if !strings.Contains(glob, "/") {
+		b.WriteString("(?:.*/)?")
+	}
+
+	glob = strings.TrimPrefix(glob, "/")
+
+	for i := 0; i < len(glob); i++ {
+		switch rest := glob[i:]; {
+		case strings.HasPrefix(rest, "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case rest == "/**":
+			b.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(rest, "**"):
+			b.WriteString(".*")
+			i++
+		case rest[0] == '*':
+			b.WriteString("[^/]*")
+		case rest[0] == '?':
+			b.WriteString("[^/]")
+		case rest[0] == '\\' && len(rest) > 1:
+			b.WriteString(regexp.QuoteMeta(rest[1:2]))
+			i++
+		case rest[0] == '[':
+			// a ] right after the [ is part of the class
+			end := -1
+			if len(rest) > 2 {
+				if j := strings.IndexByte(rest[2:], ']'); j >= 0 {
+					end = j + 2
+				}
+			}
+
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+
+			class := rest[1:end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+
+			b.WriteString("[" + class + "]")
+			i += end
+		default:
+			b.WriteString(regexp.QuoteMeta(rest[:1]))
+		}
+	}
+
+	b.WriteString("$")
+
+	return regexp.Compile(b.String())
+}
+
+// isIgnored reports whether the slash separated path rel, relative to the walked root,
+// is ignored by the ignore files of its ancestors, listed from the lowest precedence up. The
+// last matching rule wins, as with git.
+func isIgnored(files []ignoreFile, rel string, dir bool) bool {
+	ignored := false
+
+	for _, file := range files {
+		sub := rel
+		if file.outer != "" {
+			sub = file.outer + "/" + rel
+		}
+
+		if file.base != "" {
+			var ok bool
+			if sub, ok = strings.CutPrefix(rel, file.base+"/"); !ok {
+				continue
+			}
+		}
+
+		for _, rule := range file.rules {
+			if rule.dirOnly && !dir {
+				continue
+			}
+
+			if rule.re.MatchString(sub) {
+				ignored = !rule.negate
+			}
+		}
+	}
+
+	return ignored
+}
+
+// joinRel joins a name to a slash separated relative path
+func joinRel(base, name string) string {
+	if base == "" {
+		return name
+	}
+
+	return path.Join(base, name)
+}
@@ -0,0 +1,82 @@
+package nvim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		glob    string
+		path    string
+		matches bool
+	}{
+		{"*.log", "app.log", true},
+		{"*.log", "logs/app.log", true},
+		{"*.log", "app.log.txt", false},
+		{"/build", "build", true},
+		{"/build", "src/build", false},
+		{"docs/*.md", "docs/a.md", true},
+		{"docs/*.md", "docs/sub/a.md", false},
+		{"docs/*.md", "src/docs/a.md", false},
+		{"**/testdata", "a/b/testdata", true},
+		{"**/testdata", "testdata", true},
+		{"vendor/**", "vendor/a/b.go", true},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file12.txt", false},
+		{"[ab].go", "a.go", true},
+		{"[!ab].go", "a.go", false},
+		{"[!ab].go", "c.go", true},
+		{`\#hash`, "#hash", true},
+		{"a+b", "a+b", true},
+		{"a+b", "aab", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.glob+" "+tt.path, func(t *testing.T) {
+			re, err := globRegexp(tt.glob)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.matches, re.MatchString(tt.path))
+		})
+	}
+}
+
+func TestIsIgnored(t *testing.T) {
+	files := []ignoreFile{
+		{rules: parseIgnoreRules("# build output\n*.log\n!keep.log\nbin/\n\n/tmp\n")},
+		{base: "web", rules: parseIgnoreRules("node_modules\ndist/*.js\n")},
+		{outer: "repo/sub", rules: parseIgnoreRules("/repo/sub/secret.txt\n/notes.txt\n")},
+	}
+
+	tests := []struct {
+		path    string
+		dir     bool
+		ignored bool
+	}{
+		{"app.log", false, true},
+		{"web/app.log", false, true},
+		{"keep.log", false, false},
+		{"bin", true, true},
+		{"bin", false, false},
+		{"tmp", true, true},
+		{"src/tmp", true, false},
+		{"web/node_modules", true, true},
+		{"node_modules", true, false},
+		{"web/dist/app.js", false, true},
+		{"web/src/app.js", false, false},
+		{"main.go", false, false},
+		{"secret.txt", false, true},
+		{"notes.txt", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.ignored, isIgnored(files, tt.path, tt.dir))
+		})
+	}
+}
@@ -0,0 +1,403 @@
+package nvim
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/neovim/go-client/nvim"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+const (
+	// grepMaxFileSize is the size above which files on disk are not searched
+	grepMaxFileSize = 4 << 20
+	// grepBinaryProbe is the number of leading bytes checked for a NUL to skip binary files
+	grepBinaryProbe = 8000
+)
+
+// luaModifiedBuffers returns the cwd and the lines of every loaded file buffer with
+// unsaved changes
+const luaModifiedBuffers = `
+	local r = { cwd = vim.fn.getcwd(), buffers = {} }
+	for _, buf in ipairs(vim.api.nvim_list_bufs()) do
+		if vim.api.nvim_buf_is_loaded(buf) and vim.bo[buf].modified and vim.bo[buf].buftype == '' then
+			local name = vim.api.nvim_buf_get_name(buf)
+			if name ~= '' then
+				table.insert(r.buffers, { path = name, lines = vim.api.nvim_buf_get_lines(buf, 0, -1, false) })
+			end
+		end
+	end
+	return r
+`
+
+// modifiedBuffers is the result of luaModifiedBuffers
+type modifiedBuffers struct {
+	Cwd     string `msgpack:"cwd"`
+	Buffers []struct {
+		Path  string   `msgpack:"path"`
+		Lines []string `msgpack:"lines"`
+	} `msgpack:"buffers"`
+}
+
+// grepRun is the state of a walk of GrepProject
+type grepRun struct {
+	ctx     context.Context
+	root    string
+	re      *regexp.Regexp
+	glob    *regexp.Regexp
+	overlay map[string][]string
+	// unsaved holds the slash separated paths relative to the root of the overlay
+	// buffers below it, which the walk adds when they have no file on disk
+	unsaved []string
+	opts    types.GrepOptions
+	// expand returns the replacement of the match at index in content, nil when the
+	// run only searches
+	expand func(content string, index []int) string
+	// hits holds up to Offset+MaxResults+1 matches, the one past the page tells
+	// whether results were left out
+	hits []grepHit
+}
+
+// walkEntry is an entry of a directory walked by a grepRun, an unsaved buffer without a
+// file on disk or a directory leading to one when it does not exist on disk
+type walkEntry struct {
+	name    string
+	dir     bool
+	regular bool
+}
+
+// grepHit is a match found by a grepRun
+type grepHit struct {
+	types.GrepMatch
+	// digest identifies the content of the file the match was found in
+	digest string
+	// nth is the 1-based position of the match in its file
+	nth int
+	// replacement is the expanded replacement of the match when the run replaces
+	replacement string
+}
+
+// GrepProject searches the files under a root directory, the neovim cwd by default,
+// skipping the files ignored by git (the .gitignore files of the repository down from its
+// top, .git/info/exclude and core.excludesFile), hidden .git directories and binary
+// files. Files with unsaved changes in neovim are searched as they are in the buffer,
+// along with new buffers under the root whose file does not exist yet.
+// Patterns are RE2 regexes or literal strings matched over the whole file, where ^ and
+// $ match at line boundaries. Matches are paged by offset in path order.
+func (c *Client) GrepProject(ctx context.Context, pattern string, opts types.GrepOptions) (types.GrepResults, error) {
+	if err := ctx.Err(); err != nil {
+		return types.GrepResults{}, fmt.Errorf("failed to grep: %w", err)
+	}
+
+	re, err := grepRegexp(pattern, opts)
+	if err != nil {
+		return types.GrepResults{}, fmt.Errorf("failed to grep: %w", err)
+	}
+
+	glob, err := compileGlob(opts.Glob)
+	if err != nil {
+		return types.GrepResults{}, fmt.Errorf("failed to grep: %w", err)
+	}
+
+	root, overlay, err := c.grepSources(ctx, opts.Root)
+	if err != nil {
+		return types.GrepResults{}, fmt.Errorf("failed to grep: %w", err)
+	}
+
+	results, err := grepTree(ctx, root, re, glob, overlay, opts)
+	if err != nil {
+		return types.GrepResults{}, fmt.Errorf("failed to grep `%s`: %w", root, err)
+	}
+
+	return results, nil
+}
+
+// ----------------------------------------------------------------------------
+
+// grepSources returns the absolute root directory to search, relative roots being
+// resolved against the neovim cwd, and the lines of the buffers with unsaved changes
+// by path
+func (c *Client) grepSources(ctx context.Context, root string) (string, map[string][]string, error) {
+	var modified modifiedBuffers
+
+	err := c.rpc(ctx, func(v *nvim.Nvim) error {
+		return v.ExecLua(luaModifiedBuffers, &modified)
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read modified buffers: %w", err)
+	}
+
+	if !filepath.IsAbs(root) {
+		root = filepath.Join(modified.Cwd, root)
+	}
+
+	overlay := make(map[string][]string, len(modified.Buffers))
+	for _, buf := range modified.Buffers {
+		overlay[filepath.Clean(buf.Path)] = buf.Lines
+	}
+
+	return filepath.Clean(root), overlay, nil
+}
+
+// grepRegexp compiles a grep pattern, which neovim does not match so Vim regexes are
+// not supported
+func grepRegexp(pattern string, opts types.GrepOptions) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("%w: the pattern is empty", ErrInvalidPattern)
+	}
+
+	search := types.SearchOptions{Flavor: opts.Flavor, IgnoreCase: opts.IgnoreCase}
+
+	switch opts.Flavor {
+	case "":
+		search.Flavor = types.SearchFlavorRE2
+	case types.SearchFlavorLiteral, types.SearchFlavorRE2:
+	default:
+		return nil, fmt.Errorf("%w: unknown flavor `%s`, expected %s or %s", ErrInvalidPattern,
+			opts.Flavor, types.SearchFlavorRE2, types.SearchFlavorLiteral)
+	}
+
+	return compileSearch(pattern, search)
+}
+
+// compileGlob compiles the glob files must match, nil for an empty glob
+func compileGlob(glob string) (*regexp.Regexp, error) {
+	if glob == "" {
+		return nil, nil
+	}
+
+	re, err := globRegexp(glob)
+	if err != nil {
+		return nil, fmt.Errorf("%w: glob `%s`: %w", ErrInvalidPattern, glob, err)
+	}
+
+	return re, nil
+}
+
+// grepTree searches the files under root for re, reading the lines of the paths in
+// overlay instead of the files on disk
+func grepTree(ctx context.Context, root string, re, glob *regexp.Regexp, overlay map[string][]string, opts types.GrepOptions) (types.GrepResults, error) {
+	run, err := newGrepRun(ctx, root, re, glob, overlay, opts)
+	if err != nil {
+		return types.GrepResults{}, err
+	}
+
+	if err := run.walk("", loadRepoIgnores(root)); err != nil {
+		return types.GrepResults{}, err
+	}
+
+	hits, truncated, next := run.page()
+
+	results := types.GrepResults{Root: root, Matches: make([]types.GrepMatch, 0, len(hits)), Truncated: truncated, NextOffset: next}
+	for _, hit := range hits {
+		results.Matches = append(results.Matches, hit.GrepMatch)
+	}
+
+	return results, nil
+}
+
+// newGrepRun prepares a walk of the directory root
+func newGrepRun(ctx context.Context, root string, re, glob *regexp.Regexp, overlay map[string][]string, opts types.GrepOptions) (*grepRun, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return nil, errors.New("not a directory")
+	}
+
+	if opts.MaxResults <= 0 {
+		opts.MaxResults = DefaultSearchResults
+	}
+
+	opts.Offset = max(opts.Offset, 0)
+
+	var unsaved []string
+	for path := range overlay {
+		if rel, err := filepath.Rel(root, path); err == nil && rel != "." && filepath.IsLocal(rel) {
+			unsaved = append(unsaved, filepath.ToSlash(rel))
+		}
+	}
+
+	return &grepRun{ctx: ctx, root: root, re: re, glob: glob, overlay: overlay, unsaved: unsaved, opts: opts}, nil
+}
+
+// page returns the hits of the requested page, whether more follow and the offset
+// of the next page if so
+func (r *grepRun) page() ([]grepHit, bool, int) {
+	hits := r.hits[min(r.opts.Offset, len(r.hits)):]
+	if len(hits) > r.opts.MaxResults {
+		return hits[:r.opts.MaxResults], true, r.opts.Offset + r.opts.MaxResults
+	}
+
+	return hits, false, 0
+}
+
+// full reports whether the walk found every match it needs
+func (r *grepRun) full() bool {
+	return len(r.hits) > r.opts.Offset+r.opts.MaxResults
+}
+
+// walk searches the directory rel (slash separated, relative to the root) in name
+// order, ignores are the .gitignore files of its ancestors
+func (r *grepRun) walk(rel string, ignores []ignoreFile) error {
+	if err := r.ctx.Err(); err != nil {
+		return err
+	}
+
+	dir := filepath.Join(r.root, filepath.FromSlash(rel))
+
+	entries, err := r.entries(dir, rel)
+	if err != nil {
+		return err
+	}
+
+	if file, ok := loadIgnoreFile(dir, rel); ok {
+		ignores = append(ignores[:len(ignores):len(ignores)], file)
+	}
+
+	for _, entry := range entries {
+		if r.full() {
+			return nil
+		}
+
+		name := joinRel(rel, entry.name)
+
+		if (entry.dir && entry.name == ".git") || isIgnored(ignores, name, entry.dir) {
+			continue
+		}
+
+		if entry.dir {
+			if err := r.walk(name, ignores); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if !entry.regular || (r.glob != nil && !r.glob.MatchString(name)) {
+			continue
+		}
+
+		r.grepFile(filepath.Join(dir, entry.name), name)
+	}
+
+	return nil
+}
+
+// entries lists the directory rel in name order along with the unsaved buffers below
+// it that the directory does not hold, so new buffers without a file are searched too
+func (r *grepRun) entries(dir, rel string) ([]walkEntry, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		if rel == "" {
+			return nil, err
+		}
+
+		// unreadable directories below the root are skipped like ignored ones, only
+		// the unsaved buffers below them are searched
+		dirEntries = nil
+	}
+
+	entries := make([]walkEntry, 0, len(dirEntries))
+	seen := make(map[string]bool, len(dirEntries))
+
+	for _, entry := range dirEntries {
+		entries = append(entries, walkEntry{name: entry.Name(), dir: entry.IsDir(), regular: entry.Type().IsRegular()})
+		seen[entry.Name()] = true
+	}
+
+	prefix := ""
+	if rel != "" {
+		prefix = rel + "/"
+	}
+
+	added := false
+	for _, path := range r.unsaved {
+		rest, ok := strings.CutPrefix(path, prefix)
+		if !ok {
+			continue
+		}
+
+		name, _, nested := strings.Cut(rest, "/")
+		if seen[name] {
+			continue
+		}
+
+		entries = append(entries, walkEntry{name: name, dir: nested, regular: !nested})
+		seen[name] = true
+		added = true
+	}
+
+	if added {
+		slices.SortFunc(entries, func(a, b walkEntry) int { return strings.Compare(a.name, b.name) })
+	}
+
+	return entries, nil
+}
+
+// grepFile searches the file at path, named rel in results
+func (r *grepRun) grepFile(path, rel string) {
+	lines, unsaved := r.overlay[path]
+	if !unsaved {
+		var ok bool
+		if lines, ok = r.readFile(path); !ok {
+			return
+		}
+	}
+
+	needed := r.opts.Offset + r.opts.MaxResults + 1 - len(r.hits)
+
+	matches := regexMatches(lines, r.re, needed)
+	if len(matches) == 0 {
+		return
+	}
+
+	content := strings.Join(lines, "\n")
+	digest := contentDigest(content)
+
+	found := searchResults(lines, matches, 0, types.SearchOptions{MaxResults: len(matches), ContextLines: r.opts.ContextLines})
+	for i, result := range found.Matches {
+		hit := grepHit{
+			GrepMatch: types.GrepMatch{File: filepath.FromSlash(rel), Unsaved: unsaved, SearchResult: result},
+			digest:    digest,
+			nth:       i + 1,
+		}
+
+		if r.expand != nil {
+			hit.replacement = r.expand(content, matches[i].index)
+		}
+
+		r.hits = append(r.hits, hit)
+	}
+}
+
+// readFile returns the lines of a text file on disk that may match, as neovim would
+// split them, or false for large, binary, unreadable and non-matching files
+func (r *grepRun) readFile(path string) ([]string, bool) {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > grepMaxFileSize {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || bytes.IndexByte(data[:min(len(data), grepBinaryProbe)], 0) >= 0 {
+		return nil, false
+	}
+
+	content := strings.TrimSuffix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	if !r.re.MatchString(content) {
+		return nil, false
+	}
+
+	return strings.Split(content, "\n"), true
+}
@@ -0,0 +1,217 @@
+package nvim
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// writeTree creates files (slash separated path to content) under a temporary directory
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	root := resolvePath(t, t.TempDir())
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	return root
+}
+
+func TestGrepTree(t *testing.T) {
+	// keep the global git excludes of the user out of the search
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	ctx := context.Background()
+
+	root := writeTree(t, map[string]string{
+		".gitignore":       "*.log\nbuild/\n",
+		"a.go":             "package a\n\n// TODO: first\n",
+		"b.txt":            "todo lower\r\nTODO: second\r\n",
+		"debug.log":        "TODO: ignored\n",
+		"build/out.go":     "TODO: ignored\n",
+		"sub/.gitignore":   "gen.go\n",
+		"sub/c.go":         "one\nTODO: third\nthree\n",
+		"sub/gen.go":       "TODO: ignored\n",
+		"bin.dat":          "TODO\x00binary",
+		".git/config":      "TODO: ignored\n",
+		"sub/deep/d.go":    "TODO: fourth\n",
+		"sub/deep/keep.md": "nothing\n",
+	})
+
+	grep := func(t *testing.T, pattern string, overlay map[string][]string, opts types.GrepOptions) types.GrepResults {
+		t.Helper()
+
+		re, err := grepRegexp(pattern, opts)
+		require.NoError(t, err)
+
+		var glob *regexp.Regexp
+		if opts.Glob != "" {
+			glob, err = globRegexp(opts.Glob)
+			require.NoError(t, err)
+		}
+
+		results, err := grepTree(ctx, root, re, glob, overlay, opts)
+		require.NoError(t, err)
+
+		return results
+	}
+
+	files := func(results types.GrepResults) []string {
+		var names []string
+		for _, m := range results.Matches {
+			names = append(names, filepath.ToSlash(m.File))
+		}
+		return names
+	}
+
+	t.Run("skips ignored and binary files", func(t *testing.T) {
+		results := grep(t, `TODO: \w+`, nil, types.GrepOptions{})
+
+		assert.Equal(t, []string{"a.go", "b.txt", "sub/c.go", "sub/deep/d.go"}, files(results))
+		assert.Equal(t, root, results.Root)
+		assert.False(t, results.Truncated)
+		assert.Equal(t, types.SearchResult{Line: 2, Column: 1, EndLine: 2, EndColumn: 13, MatchText: "TODO: second"}, results.Matches[1].SearchResult)
+	})
+
+	t.Run("returns context lines", func(t *testing.T) {
+		results := grep(t, "third", nil, types.GrepOptions{ContextLines: 1})
+
+		require.Len(t, results.Matches, 1)
+		assert.Equal(t, []string{"one"}, results.Matches[0].Before)
+		assert.Equal(t, []string{"three"}, results.Matches[0].After)
+	})
+
+	t.Run("filters files by glob", func(t *testing.T) {
+		results := grep(t, "TODO", nil, types.GrepOptions{Glob: "sub/**/*.go"})
+
+		assert.Equal(t, []string{"sub/c.go", "sub/deep/d.go"}, files(results))
+	})
+
+	t.Run("pages matches", func(t *testing.T) {
+		first := grep(t, "TODO", nil, types.GrepOptions{MaxResults: 2})
+
+		assert.Equal(t, []string{"a.go", "b.txt"}, files(first))
+		assert.True(t, first.Truncated)
+		assert.Equal(t, 2, first.NextOffset)
+
+		second := grep(t, "TODO", nil, types.GrepOptions{Offset: first.NextOffset, MaxResults: 2})
+
+		assert.Equal(t, []string{"sub/c.go", "sub/deep/d.go"}, files(second))
+		assert.False(t, second.Truncated)
+		assert.Zero(t, second.NextOffset)
+	})
+
+	t.Run("searches unsaved buffer contents", func(t *testing.T) {
+		overlay := map[string][]string{filepath.Join(root, "a.go"): {"package a", "// TODO: unsaved"}}
+
+		results := grep(t, `TODO: \w+`, overlay, types.GrepOptions{Glob: "a.go"})
+
+		require.Len(t, results.Matches, 1)
+		assert.True(t, results.Matches[0].Unsaved)
+		assert.Equal(t, "TODO: unsaved", results.Matches[0].MatchText)
+	})
+
+	t.Run("searches unsaved buffers without a file", func(t *testing.T) {
+		overlay := map[string][]string{
+			filepath.Join(root, "a2.go"):              {"// TODO: new"},
+			filepath.Join(root, "new", "e.go"):        {"// TODO: nested"},
+			filepath.Join(root, "build", "x.go"):      {"// TODO: ignored"},
+			filepath.Join(filepath.Dir(root), "o.go"): {"// TODO: outside"},
+		}
+
+		results := grep(t, `TODO: \w+`, overlay, types.GrepOptions{})
+
+		assert.Equal(t, []string{"a.go", "a2.go", "b.txt", "new/e.go", "sub/c.go", "sub/deep/d.go"}, files(results))
+		assert.True(t, results.Matches[1].Unsaved)
+		assert.Equal(t, "TODO: nested", results.Matches[3].MatchText)
+	})
+
+	t.Run("ignores case", func(t *testing.T) {
+		results := grep(t, "todo", nil, types.GrepOptions{Flavor: types.SearchFlavorLiteral, IgnoreCase: true, Glob: "*.txt"})
+
+		assert.Len(t, results.Matches, 2)
+	})
+
+	t.Run("rejects vim regexes", func(t *testing.T) {
+		_, err := grepRegexp("TODO", types.GrepOptions{Flavor: types.SearchFlavorVim})
+
+		assert.ErrorIs(t, err, ErrInvalidPattern)
+	})
+}
+
+func TestGrepTree_RepositoryIgnores(t *testing.T) {
+	home := writeTree(t, map[string]string{"global-ignore": "*.bak\n"})
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	repo := writeTree(t, map[string]string{
+		".git/config":       "[core]\n\texcludesFile = ~/global-ignore # user wide\n",
+		".git/info/exclude": "*.secret\n",
+		".gitignore":        "/app/gen/\n*.tmp\n",
+		"app/.gitignore":    "local.txt\n",
+		"app/a.go":          "TODO\n",
+		"app/gen/g.go":      "TODO\n",
+		"app/x.tmp":         "TODO\n",
+		"app/k.secret":      "TODO\n",
+		"app/local.txt":     "TODO\n",
+		"app/old.bak":       "TODO\n",
+		"app/lib/gen/b.go":  "TODO\n",
+	})
+
+	re, err := grepRegexp("TODO", types.GrepOptions{})
+	require.NoError(t, err)
+
+	t.Run("applies the ignore files of the repository to a sub-root", func(t *testing.T) {
+		results, err := grepTree(context.Background(), filepath.Join(repo, "app"), re, nil, nil, types.GrepOptions{})
+		require.NoError(t, err)
+
+		var names []string
+		for _, m := range results.Matches {
+			names = append(names, filepath.ToSlash(m.File))
+		}
+
+		assert.Equal(t, []string{"a.go", "lib/gen/b.go"}, names)
+	})
+
+	t.Run("searches an ignored root", func(t *testing.T) {
+		results, err := grepTree(context.Background(), filepath.Join(repo, "app", "gen"), re, nil, nil, types.GrepOptions{})
+		require.NoError(t, err)
+
+		require.Len(t, results.Matches, 1)
+		assert.Equal(t, "g.go", filepath.ToSlash(results.Matches[0].File))
+	})
+}
+
+func TestClient_GrepProject(t *testing.T) {
+	client, cleanup := setupTestNeovim(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	root := writeTree(t, map[string]string{"a.txt": "saved\n", "b.txt": "saved\n"})
+
+	_, err := client.OpenBuffer(ctx, filepath.Join(root, "a.txt"))
+	require.NoError(t, err)
+
+	_, err = client.SetBufferLines(ctx, "a.txt", 1, 1, []string{"unsaved"}, 0)
+	require.NoError(t, err)
+
+	results, err := client.GrepProject(ctx, "saved", types.GrepOptions{Root: root})
+	require.NoError(t, err)
+
+	require.Len(t, results.Matches, 2)
+	assert.Equal(t, "a.txt", results.Matches[0].File)
+	assert.True(t, results.Matches[0].Unsaved)
+	assert.Equal(t, 3, results.Matches[0].Column)
+	assert.False(t, results.Matches[1].Unsaved)
+}
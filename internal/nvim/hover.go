@@ -0,0 +1,280 @@
+package nvim
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/neovim/go-client/nvim"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// luaMarkdown defines to_markdown(value), which renders LSP documentation, a string,
+// MarkupContent, MarkedString or a list of those, as markdown, and int(value, default),
+// which returns default for a missing or null number
+const luaMarkdown = `
+	local function to_markdown(value)
+		if type(value) == 'string' then
+			return value
+		end
+		if type(value) ~= 'table' then
+			return ''
+		end
+		if value.language then
+			return '` + "```" + `' .. value.language .. '\n' .. (value.value or '') .. '\n` + "```" + `'
+		end
+		if value.kind or value.value then
+			return type(value.value) == 'string' and value.value or ''
+		end
+		local parts = {}
+		for _, item in ipairs(value) do
+			local text = to_markdown(item)
+			if text ~= '' then
+				table.insert(parts, text)
+			end
+		end
+		return table.concat(parts, '\n\n')
+	end
+	local function int(value, default)
+		return type(value) == 'number' and value or default
+	end
+`
+
+// luaHover sends a textDocument/hover request with lsp_request and returns the
+// non-empty contents of each client as markdown
+const luaHover = luaLSPRequest + luaMarkdown + `
+	local buf, uri, row, chars, timeout = ...
+	local clients, answers, err = lsp_request(buf, 'textDocument/hover', uri, row, chars, timeout)
+	local r = { clients = clients, hovers = {}, error = err }
+	for _, answer in ipairs(answers) do
+		local contents = vim.trim(to_markdown(answer.result.contents))
+		if contents ~= '' then
+			table.insert(r.hovers, { client = answer.client.name, contents = contents })
+		end
+	end
+	return r
+`
+
+// luaSignatureHelp sends an invoked textDocument/signatureHelp request with lsp_request
+// and returns the signatures of each client. Active indexes the client left out are -1,
+// parameter labels given as offsets into the signature label are returned as is in
+// the client's encoding.
+const luaSignatureHelp = luaLSPRequest + luaMarkdown + `
+	local buf, uri, row, chars, timeout = ...
+	local context = { triggerKind = 1, isRetrigger = false }
+	local clients, answers, err = lsp_request(buf, 'textDocument/signatureHelp', uri, row, chars, timeout, context)
+	local r = { clients = clients, help = {}, error = err }
+	for _, answer in ipairs(answers) do
+		local result = answer.result
+		local help = {
+			client = answer.client.name,
+			encoding = answer.client.offset_encoding or 'utf-16',
+			active_signature = int(result.activeSignature, -1),
+			active_parameter = int(result.activeParameter, -1),
+			signatures = {},
+		}
+		for _, s in ipairs(type(result.signatures) == 'table' and result.signatures or {}) do
+			local signature = {
+				label = s.label or '',
+				documentation = to_markdown(s.documentation),
+				active_parameter = int(s.activeParameter, -1),
+				parameters = {},
+			}
+			for _, p in ipairs(type(s.parameters) == 'table' and s.parameters or {}) do
+				local parameter = { label = '', start = -1, finish = -1, documentation = to_markdown(p.documentation) }
+				if type(p.label) == 'table' then
+					parameter.start, parameter.finish = int(p.label[1], -1), int(p.label[2], -1)
+				elseif type(p.label) == 'string' then
+					parameter.label = p.label
+				end
+				table.insert(signature.parameters, parameter)
+			end
+			table.insert(help.signatures, signature)
+		end
+		if #help.signatures > 0 then
+			table.insert(r.help, help)
+		end
+	end
+	return r
+`
+
+// hoverOutcome is the result of luaHover
+type hoverOutcome struct {
+	Clients []luaClientStatus `msgpack:"clients"`
+	Hovers  []luaHoverItem    `msgpack:"hovers"`
+	Error   string            `msgpack:"error"`
+}
+
+// luaHoverItem mirrors the hover information of a client in luaHover
+type luaHoverItem struct {
+	Client   string `msgpack:"client"`
+	Contents string `msgpack:"contents"`
+}
+
+// signatureHelpOutcome is the result of luaSignatureHelp
+type signatureHelpOutcome struct {
+	Clients []luaClientStatus      `msgpack:"clients"`
+	Help    []luaSignatureHelpItem `msgpack:"help"`
+	Error   string                 `msgpack:"error"`
+}
+
+// luaSignatureHelpItem mirrors the signature help of a client in luaSignatureHelp
+type luaSignatureHelpItem struct {
+	Client          string         `msgpack:"client"`
+	Encoding        string         `msgpack:"encoding"`
+	ActiveSignature int            `msgpack:"active_signature"`
+	ActiveParameter int            `msgpack:"active_parameter"`
+	Signatures      []luaSignature `msgpack:"signatures"`
+}
+
+// luaSignature mirrors a signature of luaSignatureHelp
+type luaSignature struct {
+	Label           string         `msgpack:"label"`
+	Documentation   string         `msgpack:"documentation"`
+	ActiveParameter int            `msgpack:"active_parameter"`
+	Parameters      []luaParameter `msgpack:"parameters"`
+}
+
+// luaParameter mirrors a parameter of luaSignatureHelp, start and finish are the
+// offsets of its label in the signature label in the client's encoding, -1 when the
+// label is given as a string
+type luaParameter struct {
+	Label         string `msgpack:"label"`
+	Start         int    `msgpack:"start"`
+	Finish        int    `msgpack:"finish"`
+	Documentation string `msgpack:"documentation"`
+}
+
+// Hover asks the language servers attached to a buffer, the current buffer for an
+// empty title, for the hover information of a position, the documentation and type
+// shown by K, as markdown. The position is selected like for FindLocations.
+func (c *Client) Hover(ctx context.Context, title string, opts types.LocationOptions) (types.HoverResult, error) {
+	if err := ctx.Err(); err != nil {
+		return types.HoverResult{}, fmt.Errorf("failed to get hover information: %w", err)
+	}
+
+	buf, pos, err := c.lspTarget(ctx, title, &opts)
+	if err != nil {
+		return types.HoverResult{}, fmt.Errorf("failed to get hover information: %w", err)
+	}
+
+	var outcome hoverOutcome
+	err = c.rpc(ctx, func(v *nvim.Nvim) error {
+		return v.ExecLua(luaHover, &outcome, buf.Handle, pos.uri, pos.row, pos.chars, opts.Timeout.Milliseconds())
+	})
+	if err != nil {
+		return types.HoverResult{}, fmt.Errorf("failed to get hover information in buffer `%s`: %w", buf.Title, err)
+	}
+
+	if len(outcome.Clients) == 0 {
+		return types.HoverResult{}, fmt.Errorf("failed to get hover information in buffer `%s`: %w: none supports textDocument/hover",
+			buf.Title, ErrNoLanguageServer)
+	}
+
+	result := types.HoverResult{Hovers: []types.Hover{}, Clients: clientStatuses(outcome.Clients), TimedOut: outcome.Error != ""}
+	for _, hover := range outcome.Hovers {
+		result.Hovers = append(result.Hovers, types.Hover{Client: hover.Client, Contents: hover.Contents})
+	}
+
+	return result, nil
+}
+
+// SignatureHelp asks the language servers attached to a buffer, the current buffer for
+// an empty title, for the signatures of the call at a position with the active
+// signature and parameter. The position is selected like for FindLocations.
+func (c *Client) SignatureHelp(ctx context.Context, title string, opts types.LocationOptions) (types.SignatureHelpResult, error) {
+	if err := ctx.Err(); err != nil {
+		return types.SignatureHelpResult{}, fmt.Errorf("failed to get signature help: %w", err)
+	}
+
+	buf, pos, err := c.lspTarget(ctx, title, &opts)
+	if err != nil {
+		return types.SignatureHelpResult{}, fmt.Errorf("failed to get signature help: %w", err)
+	}
+
+	var outcome signatureHelpOutcome
+	err = c.rpc(ctx, func(v *nvim.Nvim) error {
+		return v.ExecLua(luaSignatureHelp, &outcome, buf.Handle, pos.uri, pos.row, pos.chars, opts.Timeout.Milliseconds())
+	})
+	if err != nil {
+		return types.SignatureHelpResult{}, fmt.Errorf("failed to get signature help in buffer `%s`: %w", buf.Title, err)
+	}
+
+	if len(outcome.Clients) == 0 {
+		return types.SignatureHelpResult{}, fmt.Errorf("failed to get signature help in buffer `%s`: %w: none supports textDocument/signatureHelp",
+			buf.Title, ErrNoLanguageServer)
+	}
+
+	result := types.SignatureHelpResult{Help: []types.SignatureHelp{}, Clients: clientStatuses(outcome.Clients), TimedOut: outcome.Error != ""}
+	for _, help := range outcome.Help {
+		result.Help = append(result.Help, help.signatureHelp())
+	}
+
+	return result, nil
+}
+
+// ----------------------------------------------------------------------------
+
+// signatureHelp resolves the active signature and parameter and the parameter labels.
+// As in the LSP specification, a missing or out of range active signature is the first
+// one, and a missing active parameter the first parameter while one past the
+// parameters is none. The active parameter of a signature overrides the global one.
+func (h luaSignatureHelpItem) signatureHelp() types.SignatureHelp {
+	help := types.SignatureHelp{Client: h.Client, Signatures: make([]types.Signature, 0, len(h.Signatures)), ActiveParameter: -1}
+
+	for _, s := range h.Signatures {
+		signature := types.Signature{Label: s.Label, Documentation: s.Documentation, Parameters: make([]types.SignatureParameter, 0, len(s.Parameters))}
+		for _, p := range s.Parameters {
+			signature.Parameters = append(signature.Parameters, types.SignatureParameter{
+				Label:         p.label(s.Label, h.Encoding),
+				Documentation: p.Documentation,
+			})
+		}
+
+		help.Signatures = append(help.Signatures, signature)
+	}
+
+	if len(h.Signatures) == 0 {
+		return help
+	}
+
+	if h.ActiveSignature >= 0 && h.ActiveSignature < len(h.Signatures) {
+		help.ActiveSignature = h.ActiveSignature
+	}
+
+	active := h.Signatures[help.ActiveSignature]
+	help.Signature = active.Label
+
+	param := h.ActiveParameter
+	if active.ActiveParameter >= 0 {
+		param = active.ActiveParameter
+	}
+
+	if param < 0 {
+		param = 0
+	}
+
+	if param < len(active.Parameters) {
+		help.ActiveParameter = param
+		help.Parameter = help.Signatures[help.ActiveSignature].Parameters[param].Label
+	}
+
+	return help
+}
+
+// label returns the label of a parameter, cutting it from the signature label when
+// the server gave its offsets in an offset encoding. Offsets outside of the signature
+// label give the string label, which is empty then.
+func (p luaParameter) label(signature, encoding string) string {
+	if p.Start < 0 || p.Finish < p.Start {
+		return p.Label
+	}
+
+	start, serr := byteColumn(signature, p.Start+1, lspEncoding(encoding))
+	end, eerr := byteColumn(signature, p.Finish+1, lspEncoding(encoding))
+	if serr != nil || eerr != nil || end < start || end > len(signature) {
+		return p.Label
+	}
+
+	return signature[start:end]
+}
@@ -0,0 +1,133 @@
+package nvim
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestLuaSignatureHelpItem(t *testing.T) {
+	label := "fmt(héllo string, n int)"
+	signatures := []luaSignature{
+		{
+			Label:           label,
+			ActiveParameter: -1,
+			Parameters: []luaParameter{
+				// UTF-16 offsets, é is one unit but two bytes
+				{Start: 4, Finish: 16, Documentation: "the greeting"},
+				{Label: "n int", Start: -1, Finish: -1},
+			},
+		},
+		{Label: "fmt()", ActiveParameter: -1, Parameters: []luaParameter{}},
+	}
+
+	t.Run("resolves the active signature and parameter", func(t *testing.T) {
+		help := luaSignatureHelpItem{Client: "gopls", Encoding: "utf-16", ActiveSignature: 0, ActiveParameter: 1, Signatures: signatures}.signatureHelp()
+
+		assert.Equal(t, types.SignatureHelp{
+			Client: "gopls",
+			Signatures: []types.Signature{
+				{Label: label, Parameters: []types.SignatureParameter{{Label: "héllo string", Documentation: "the greeting"}, {Label: "n int"}}},
+				{Label: "fmt()", Parameters: []types.SignatureParameter{}},
+			},
+			ActiveSignature: 0,
+			ActiveParameter: 1,
+			Signature:       label,
+			Parameter:       "n int",
+		}, help)
+	})
+
+	t.Run("defaults to the first signature and parameter", func(t *testing.T) {
+		help := luaSignatureHelpItem{Encoding: "utf-16", ActiveSignature: 5, ActiveParameter: -1, Signatures: signatures}.signatureHelp()
+
+		assert.Equal(t, 0, help.ActiveSignature)
+		assert.Equal(t, 0, help.ActiveParameter)
+		assert.Equal(t, "héllo string", help.Parameter)
+	})
+
+	t.Run("prefers the active parameter of the signature", func(t *testing.T) {
+		sigs := append([]luaSignature(nil), signatures...)
+		sigs[0].ActiveParameter = 0
+
+		help := luaSignatureHelpItem{Encoding: "utf-16", ActiveParameter: 1, Signatures: sigs}.signatureHelp()
+
+		assert.Equal(t, 0, help.ActiveParameter)
+	})
+
+	t.Run("has no active parameter past the parameters", func(t *testing.T) {
+		help := luaSignatureHelpItem{Encoding: "utf-16", ActiveSignature: 1, ActiveParameter: 0, Signatures: signatures}.signatureHelp()
+
+		assert.Equal(t, "fmt()", help.Signature)
+		assert.Equal(t, -1, help.ActiveParameter)
+		assert.Empty(t, help.Parameter)
+	})
+
+	t.Run("defaults to the first signature below the signatures", func(t *testing.T) {
+		help := luaSignatureHelpItem{Encoding: "utf-16", ActiveSignature: -1, ActiveParameter: 1, Signatures: signatures}.signatureHelp()
+
+		assert.Equal(t, 0, help.ActiveSignature)
+		assert.Equal(t, label, help.Signature)
+		assert.Equal(t, "n int", help.Parameter)
+	})
+
+	t.Run("prefers the active parameter of the active signature only", func(t *testing.T) {
+		sigs := []luaSignature{
+			{Label: "f(a)", ActiveParameter: 0, Parameters: []luaParameter{{Label: "a", Start: -1, Finish: -1}}},
+			{Label: "f(a, b)", ActiveParameter: 1, Parameters: []luaParameter{{Label: "a", Start: -1, Finish: -1}, {Label: "b", Start: -1, Finish: -1}}},
+		}
+
+		help := luaSignatureHelpItem{Encoding: "utf-16", ActiveSignature: 1, ActiveParameter: 0, Signatures: sigs}.signatureHelp()
+
+		assert.Equal(t, 1, help.ActiveParameter)
+		assert.Equal(t, "b", help.Parameter)
+	})
+
+	t.Run("cuts labels after surrogate pairs", func(t *testing.T) {
+		// 𝒳 is two UTF-16 units and four bytes
+		sigs := []luaSignature{{Label: "f(𝒳 int, y int)", ActiveParameter: -1, Parameters: []luaParameter{{Start: 2, Finish: 8}, {Start: 10, Finish: 15}}}}
+
+		help := luaSignatureHelpItem{Encoding: "utf-16", Signatures: sigs}.signatureHelp()
+
+		assert.Equal(t, []types.SignatureParameter{{Label: "𝒳 int"}, {Label: "y int"}}, help.Signatures[0].Parameters)
+	})
+
+	t.Run("ignores offsets past the signature label", func(t *testing.T) {
+		sigs := []luaSignature{{Label: "f(x)", ActiveParameter: -1, Parameters: []luaParameter{{Start: 2, Finish: 40}, {Start: 30, Finish: 40}}}}
+
+		help := luaSignatureHelpItem{Encoding: "utf-16", Signatures: sigs}.signatureHelp()
+
+		assert.Equal(t, []types.SignatureParameter{{}, {}}, help.Signatures[0].Parameters)
+	})
+}
+
+func TestClient_HoverAndSignatureHelp(t *testing.T) {
+	client, cleanup := setupTestNeovim(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	_, err := client.OpenBuffer(ctx, createTempFile(t, "package main"))
+	require.NoError(t, err)
+
+	t.Run("hover fails without a language server", func(t *testing.T) {
+		_, err := client.Hover(ctx, "", types.LocationOptions{Line: 1, Column: 9})
+
+		assert.ErrorIs(t, err, ErrNoLanguageServer)
+	})
+
+	t.Run("signature help fails without a language server", func(t *testing.T) {
+		_, err := client.SignatureHelp(ctx, "", types.LocationOptions{Line: 1, Column: 9})
+
+		assert.ErrorIs(t, err, ErrNoLanguageServer)
+	})
+
+	t.Run("rejects unknown encodings", func(t *testing.T) {
+		_, err := client.Hover(ctx, "", types.LocationOptions{Encoding: "utf-32"})
+
+		assert.ErrorIs(t, err, ErrInvalidEncoding)
+	})
+}
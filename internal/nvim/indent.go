@@ -0,0 +1,186 @@
+package nvim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/neovim/go-client/nvim"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// luaReindentLines defines reindent_lines(buf, first, last), which re-indents the lines
+// first to last (0-based, end exclusive). The first non-blank line gets the indent
+// computed by 'indentexpr' (which treesitter indent plugins set), 'cindent' or 'lisp',
+// else the indent of the line above. Every line is shifted by the same number of
+// columns, so the others keep their exact offset from it, such as the extra column of
+// block comment lines or alignment. Indents are rendered with 'tabstop' and 'expandtab'.
+// The change is joined to the previous undo step and the new lines and change in length
+// of the last line are returned.
+const luaReindentLines = `
+	local function reindent_lines(buf, first, last)
+		local lines = vim.api.nvim_buf_get_lines(buf, first, last, true)
+		local old_last = #lines > 0 and #lines[#lines] or 0
+		vim.api.nvim_buf_call(buf, function()
+			local ts = vim.bo.tabstop
+			local function width(line)
+				local w = 0
+				for c in line:match('^[ \t]*'):gmatch('.') do
+					w = c == '\t' and w + ts - w % ts or w + 1
+				end
+				return w
+			end
+
+			local lead
+			for i, line in ipairs(lines) do
+				if line:match('%S') then
+					lead = i
+					break
+				end
+			end
+			if not lead then
+				return
+			end
+
+			local lnum = first + lead
+			local target = -1
+			local view = vim.fn.winsaveview()
+			if vim.bo.indentexpr ~= '' then
+				vim.api.nvim_win_set_cursor(0, { lnum, 0 })
+				vim.v.lnum = lnum
+				local ok, indent = pcall(vim.fn.eval, vim.bo.indentexpr)
+				target = ok and tonumber(indent) or -1
+			elseif vim.bo.cindent then
+				target = vim.fn.cindent(lnum)
+			elseif vim.bo.lisp then
+				target = vim.fn.lispindent(lnum)
+			end
+			vim.fn.winrestview(view)
+			if target < 0 then
+				local above = vim.fn.prevnonblank(lnum - 1)
+				target = above > 0 and vim.fn.indent(above) or 0
+			end
+
+			local shift = target - width(lines[lead])
+			for i, line in ipairs(lines) do
+				local text = line:gsub('^[ \t]*', '')
+				if text == '' then
+					lines[i] = ''
+				else
+					local indent = math.max(0, width(line) + shift)
+					local prefix = string.rep(' ', indent)
+					if not vim.bo.expandtab then
+						prefix = string.rep('\t', math.floor(indent / ts)) .. string.rep(' ', indent % ts)
+					end
+					lines[i] = prefix .. text
+				end
+			end
+			pcall(vim.cmd.undojoin)
+			vim.api.nvim_buf_set_lines(buf, first, last, true, lines)
+		end)
+		local shift = #lines > 0 and #lines[#lines] - old_last or 0
+		return lines, shift
+	end
+`
+
+// luaWriteReindent applies a line edit {start, end, lines} or text edit {start_row,
+// start_col, end_row, end_col, lines} unless the buffer changed since changedtick, then
+// re-indents the rows first to last of the result with reindent_lines. Both happen in
+// one call, so nothing can change the buffer in between, and form one undo step. It
+// returns the new changedtick, lines and shift, with a tick of -1 when the buffer changed.
+const luaWriteReindent = luaReindentLines + `
+	local buf, tick, edit, first, last = ...
+	if vim.api.nvim_buf_get_changedtick(buf) ~= tick then
+		return { tick = -1, lines = {}, shift = 0 }
+	end
+	if #edit == 3 then
+		vim.api.nvim_buf_set_lines(buf, edit[1], edit[2], true, edit[3])
+	else
+		vim.api.nvim_buf_set_text(buf, edit[1], edit[2], edit[3], edit[4], edit[5])
+	end
+	local lines, shift = reindent_lines(buf, first, last)
+	return { tick = vim.api.nvim_buf_get_changedtick(buf), lines = lines, shift = shift }
+`
+
+// reindentOutcome is the result of luaWriteReindent
+type reindentOutcome struct {
+	Tick  int      `msgpack:"tick"`
+	Lines []string `msgpack:"lines"`
+	Shift int      `msgpack:"shift"`
+}
+
+// SetReindentedLines sets lines start to end (1-based, inclusive) of a buffer like
+// SetBufferLines and re-indents the new lines following the indent rules of the buffer's
+// filetype, keeping their depth relative to each other. The write and the re-indent form
+// one undo step and are applied together, so the write never succeeds without them. It
+// returns the range of the new lines (byte columns) and the new version, a non-zero
+// expected version is checked as by SetBufferLines.
+func (c *Client) SetReindentedLines(ctx context.Context, title string, start, end int, lines []string, expectedVersion int) (types.TextRange, int, error) {
+	if err := ctx.Err(); err != nil {
+		return types.TextRange{}, 0, fmt.Errorf("failed to set reindented lines: %w", err)
+	}
+
+	buf, err := c.GetBufferByTitle(ctx, title)
+	if err != nil {
+		return types.TextRange{}, 0, fmt.Errorf("failed to set lines in buffer `%s`: %w", title, err)
+	}
+
+	if lines == nil {
+		lines = []string{}
+	}
+
+	for range editAttempts {
+		tick := expectedVersion
+		if tick == 0 {
+			if err = c.batch(ctx, func(b *nvim.Batch) { b.BufferChangedTick(buf.Handle, &tick) }); err != nil {
+				return types.TextRange{}, 0, fmt.Errorf("failed to set lines in buffer `%s`: %w", title, err)
+			}
+		}
+
+		edit := []any{start - 1, end, lines}
+
+		outcome, rerr := c.writeReindent(ctx, buf.Handle, tick, edit, start-1, start-1+len(lines))
+		switch {
+		case errors.Is(rerr, errStaleBuffer) && expectedVersion != 0:
+			rerr = c.conflictError(ctx, buf.Handle, title, expectedVersion, start, end)
+		case errors.Is(rerr, errStaleBuffer):
+			continue
+		}
+
+		if rerr != nil {
+			return types.TextRange{}, 0, fmt.Errorf("failed to set lines in buffer `%s`: %w", title, rerr)
+		}
+
+		rng := types.TextRange{StartLine: start, StartColumn: 1, EndLine: start + len(lines) - 1, EndColumn: 1}
+		if n := len(outcome.Lines); n > 0 {
+			rng.EndColumn = len(outcome.Lines[n-1]) + 1
+		}
+
+		return rng, outcome.Tick, nil
+	}
+
+	return types.TextRange{}, 0, fmt.Errorf("failed to set lines in buffer `%s`: %w", title, ErrBufferChanged)
+}
+
+// ----------------------------------------------------------------------------
+
+// writeReindent applies a line or text edit and re-indents the rows first to last
+// (0-based, end exclusive) of the result in one call, failing with errStaleBuffer when
+// the buffer changed since changedtick tick
+func (c *Client) writeReindent(ctx context.Context, buf nvim.Buffer, tick int, edit []any, first, last int) (reindentOutcome, error) {
+	var outcome reindentOutcome
+
+	err := c.rpc(ctx, func(v *nvim.Nvim) error {
+		return v.ExecLua(luaWriteReindent, &outcome, buf, tick, edit, first, last)
+	})
+	if err != nil {
+		return reindentOutcome{}, fmt.Errorf("failed to write and reindent: %w", err)
+	}
+
+	if outcome.Tick < 0 {
+		return reindentOutcome{}, errStaleBuffer
+	}
+
+	return outcome, nil
+}
@@ -0,0 +1,109 @@
+package nvim
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestClient_SetReindentedLines(t *testing.T) {
+	client, cleanup := setupTestNeovim(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	open := func(t *testing.T, content, options string) string {
+		t.Helper()
+
+		tmpFile := createTempFile(t, content)
+		_, err := client.OpenBuffer(ctx, tmpFile)
+		require.NoError(t, err)
+
+		_, err = client.ExecCommand(ctx, "setlocal indentexpr= nocindent "+options)
+		require.NoError(t, err)
+
+		return filepath.Base(tmpFile)
+	}
+
+	t.Run("follows the line above with spaces", func(t *testing.T) {
+		title := open(t, "a:\n    b:\nx:\n    y", "expandtab shiftwidth=2")
+
+		changed, _, err := client.SetReindentedLines(ctx, title, 3, 4, []string{"x:", "    y"}, 0)
+		require.NoError(t, err)
+		assert.Equal(t, types.TextRange{StartLine: 3, StartColumn: 1, EndLine: 4, EndColumn: 10}, changed)
+
+		lines, _, err := client.GetBufferLines(ctx, title, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a:", "    b:", "    x:", "        y"}, lines)
+	})
+
+	t.Run("uses tabs without expandtab", func(t *testing.T) {
+		title := open(t, "\tblock {\n\told", "noexpandtab tabstop=4 shiftwidth=4")
+
+		_, _, err := client.SetReindentedLines(ctx, title, 2, 2, []string{"  one", "      two", "    three"}, 0)
+		require.NoError(t, err)
+
+		lines, _, err := client.GetBufferLines(ctx, title, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"\tblock {", "\tone", "\t\ttwo", "\t  three"}, lines)
+	})
+
+	t.Run("writes and reindents as one undo step", func(t *testing.T) {
+		title := open(t, "a:\n    b", "expandtab shiftwidth=4")
+
+		_, version, err := client.GetBufferLines(ctx, title, 1, -1)
+		require.NoError(t, err)
+
+		_, _, err = client.SetReindentedLines(ctx, title, 2, 2, []string{"c"}, version)
+		require.NoError(t, err)
+
+		_, _, err = client.SetReindentedLines(ctx, title, 2, 2, []string{"d"}, version)
+		assert.ErrorIs(t, err, ErrVersionConflict)
+
+		_, err = client.ExecCommand(ctx, "undo")
+		require.NoError(t, err)
+
+		lines, _, err := client.GetBufferLines(ctx, title, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a:", "    b"}, lines)
+	})
+
+	t.Run("reindents inserted lines", func(t *testing.T) {
+		title := open(t, "if x:\n    pass", "expandtab shiftwidth=4")
+
+		result, err := client.InsertText(ctx, title, "y = 1\nif y:\n  z()", types.InsertTextOptions{Line: 1, Linewise: true, After: true, Reindent: true})
+		require.NoError(t, err)
+		assert.Equal(t, types.TextRange{StartLine: 2, StartColumn: 1, EndLine: 4, EndColumn: 6}, result.Changed)
+
+		lines, _, err := client.GetBufferLines(ctx, title, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"if x:", "y = 1", "if y:", "  z()", "    pass"}, lines)
+	})
+
+	t.Run("keeps the offsets of block comments", func(t *testing.T) {
+		title := open(t, "class A {\n    int x;\n}", "expandtab shiftwidth=4")
+
+		_, _, err := client.SetReindentedLines(ctx, title, 3, 2, []string{"/**", " * Doc.", " */"}, 0)
+		require.NoError(t, err)
+
+		lines, _, err := client.GetBufferLines(ctx, title, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"class A {", "    int x;", "    /**", "     * Doc.", "     */", "}"}, lines)
+	})
+
+	t.Run("keeps odd alignment", func(t *testing.T) {
+		title := open(t, "\tdo()\n\tx", "noexpandtab tabstop=4 shiftwidth=4")
+
+		_, _, err := client.SetReindentedLines(ctx, title, 2, 2, []string{"call(a,", "     b,", "   c)"}, 0)
+		require.NoError(t, err)
+
+		lines, _, err := client.GetBufferLines(ctx, title, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"\tdo()", "\tcall(a,", "\t\t b,", "\t   c)"}, lines)
+	})
+}
@@ -0,0 +1,213 @@
+package nvim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/neovim/go-client/nvim"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// luaBufferCursor returns the cursor of the current window when it shows buf, else of
+// the first window showing buf, as a (1-based row, 0-based byte column) pair. It returns
+// an empty list when no window shows buf.
+const luaBufferCursor = `
+	local buf = ...
+	local win = vim.api.nvim_get_current_win()
+	if vim.api.nvim_win_get_buf(win) ~= buf then
+		win = vim.fn.bufwinid(buf)
+	end
+	if win == -1 then
+		return {}
+	end
+	return vim.api.nvim_win_get_cursor(win)
+`
+
+// InsertText inserts literal text into a buffer without going through keystrokes, so
+// the current mode and mappings do not matter. An empty title is the current buffer.
+// Charwise text goes before or after the character at the position, linewise text is
+// split into lines put above or below the line. The insertion is a single undo step.
+func (c *Client) InsertText(ctx context.Context, title, text string, opts types.InsertTextOptions) (types.InsertTextResult, error) {
+	if err := ctx.Err(); err != nil {
+		return types.InsertTextResult{}, fmt.Errorf("failed to insert text: %w", err)
+	}
+
+	if err := ValidateEncoding(opts.Encoding); err != nil {
+		return types.InsertTextResult{}, fmt.Errorf("failed to insert text: %w", err)
+	}
+
+	buf, err := c.bufferOrCurrent(ctx, title)
+	if err != nil {
+		return types.InsertTextResult{}, fmt.Errorf("failed to insert text: %w", err)
+	}
+
+	if title == "" {
+		title = buf.Title
+	}
+
+	for range editAttempts {
+		lines, tick, rerr := c.readBuffer(ctx, buf.Handle)
+		if rerr != nil {
+			return types.InsertTextResult{}, fmt.Errorf("failed to insert text in buffer `%s`: %w", title, rerr)
+		}
+
+		row, col, perr := c.insertPosition(ctx, buf.Handle, lines, opts)
+		if perr != nil {
+			return types.InsertTextResult{}, fmt.Errorf("failed to insert text in buffer `%s`: %w", title, perr)
+		}
+
+		if opts.ExpectedVersion != 0 && tick != opts.ExpectedVersion {
+			conflict := &VersionConflictError{Buffer: title, Expected: opts.ExpectedVersion, Current: tick, StartLine: row + 1, Lines: lines[row : row+1]}
+			return types.InsertTextResult{}, fmt.Errorf("failed to insert text in buffer `%s`: %w", title, conflict)
+		}
+
+		var (
+			changed types.TextRange
+			luaEdit []any // the edit as luaWriteReindent takes it
+			version int
+			serr    error
+		)
+
+		if opts.Linewise {
+			edit, rng := linewiseInsert(lines, row, text, opts)
+			changed, luaEdit = rng, []any{edit.start, edit.end, edit.lines}
+			if !opts.Reindent {
+				version, serr = c.setLines(ctx, buf.Handle, tick, []lineEdit{edit})
+			}
+		} else {
+			if opts.After && col < len(lines[row]) {
+				_, size := utf8.DecodeRuneInString(lines[row][col:])
+				col += size
+			}
+
+			changed = insertedRange(row+1, textUnits(lines[row][:col], opts.Encoding)+1, text, opts.Encoding)
+			edit := textEdit{byteRange: byteRange{startRow: row, startCol: col, endRow: row, endCol: col}, lines: strings.Split(text, "\n")}
+			luaEdit = []any{row, col, row, col, edit.lines}
+			if !opts.Reindent {
+				version, serr = c.setText(ctx, buf.Handle, tick, []textEdit{edit})
+			}
+		}
+
+		if opts.Reindent {
+			changed, version, serr = c.reindentInsert(ctx, buf.Handle, tick, luaEdit, changed, opts.Linewise)
+		}
+
+		if !errors.Is(serr, errStaleBuffer) {
+			if serr != nil {
+				return types.InsertTextResult{}, fmt.Errorf("failed to insert text in buffer `%s`: %w", title, serr)
+			}
+
+			return types.InsertTextResult{Changed: changed, Version: version}, nil
+		}
+	}
+
+	return types.InsertTextResult{}, fmt.Errorf("failed to insert text in buffer `%s`: %w", title, ErrBufferChanged)
+}
+
+// ----------------------------------------------------------------------------
+
+// insertPosition returns the 0-based row and byte column of an insertion into lines,
+// reading the cursor of a window showing buf when no line is given
+func (c *Client) insertPosition(ctx context.Context, buf nvim.Buffer, lines []string, opts types.InsertTextOptions) (int, int, error) {
+	if opts.Linewise {
+		row, _, err := c.bufferPosition(ctx, buf, lines, opts.Line, -1, opts.Encoding)
+		return row, 0, err
+	}
+
+	return c.bufferPosition(ctx, buf, lines, opts.Line, opts.Column, opts.Encoding)
+}
+
+// bufferPosition converts a 1-based line and encoding column of lines to a 0-based row
+// and byte column. A zero line is the line of the cursor of a window showing buf, with
+// the cursor column when column is zero too. Otherwise a zero column is the line start
+// and a negative one is not checked.
+func (c *Client) bufferPosition(ctx context.Context, buf nvim.Buffer, lines []string, line, column int, encoding string) (int, int, error) {
+	cursorCol := -1
+
+	if line == 0 {
+		var cursor []int
+		err := c.rpc(ctx, func(v *nvim.Nvim) error {
+			return v.ExecLua(luaBufferCursor, &cursor, buf)
+		})
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to get cursor: %w", err)
+		}
+
+		if len(cursor) != 2 {
+			return 0, 0, fmt.Errorf("%w: no window shows the buffer, give a line", ErrInvalidRange)
+		}
+
+		line = cursor[0]
+		if column == 0 {
+			cursorCol = cursor[1]
+		}
+	}
+
+	if line < 1 || line > len(lines) {
+		return 0, 0, fmt.Errorf("%w: line %d of a buffer with %d lines", ErrInvalidRange, line, len(lines))
+	}
+
+	row := line - 1
+
+	switch {
+	case cursorCol >= 0:
+		return row, min(cursorCol, len(lines[row])), nil
+	case column <= 0:
+		return row, 0, nil
+	}
+
+	col, err := byteColumn(lines[row], column, encoding)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: line %d: %w", ErrInvalidRange, line, err)
+	}
+
+	return row, col, nil
+}
+
+// reindentInsert inserts text with an edit as luaWriteReindent takes it and re-indents
+// its lines in the same call, leaving the first line of a charwise insertion that
+// continues an existing line, and returns the updated range
+func (c *Client) reindentInsert(ctx context.Context, buf nvim.Buffer, tick int, edit []any, changed types.TextRange, linewise bool) (types.TextRange, int, error) {
+	first := changed.StartLine - 1
+	if !linewise {
+		first++
+	}
+
+	outcome, err := c.writeReindent(ctx, buf, tick, edit, first, changed.EndLine)
+	if err != nil {
+		return types.TextRange{}, 0, err
+	}
+
+	changed.EndColumn += outcome.Shift
+
+	return changed, outcome.Tick, nil
+}
+
+// linewiseInsert builds the edit putting text as whole lines above or below row, one
+// trailing newline ends the last line rather than adding an empty one. Inserting into
+// an empty buffer replaces its only line.
+func linewiseInsert(lines []string, row int, text string, opts types.InsertTextOptions) (lineEdit, types.TextRange) {
+	inserted := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+
+	edit := lineEdit{start: row, end: row, lines: inserted}
+	if opts.After {
+		edit.start, edit.end = row+1, row+1
+	}
+
+	if len(lines) == 1 && lines[0] == "" {
+		edit.start, edit.end = 0, 1
+	}
+
+	last := inserted[len(inserted)-1]
+
+	return edit, types.TextRange{
+		StartLine:   edit.start + 1,
+		StartColumn: 1,
+		EndLine:     edit.start + len(inserted),
+		EndColumn:   textUnits(last, opts.Encoding) + 1,
+	}
+}
@@ -0,0 +1,94 @@
+package nvim
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestLinewiseInsert(t *testing.T) {
+	lines := []string{"a", "b"}
+
+	t.Run("above", func(t *testing.T) {
+		edit, rng := linewiseInsert(lines, 1, "x\ny\n", types.InsertTextOptions{})
+
+		assert.Equal(t, lineEdit{start: 1, end: 1, lines: []string{"x", "y"}}, edit)
+		assert.Equal(t, types.TextRange{StartLine: 2, StartColumn: 1, EndLine: 3, EndColumn: 2}, rng)
+	})
+
+	t.Run("below", func(t *testing.T) {
+		edit, _ := linewiseInsert(lines, 1, "x", types.InsertTextOptions{After: true})
+
+		assert.Equal(t, lineEdit{start: 2, end: 2, lines: []string{"x"}}, edit)
+	})
+
+	t.Run("empty buffer", func(t *testing.T) {
+		edit, _ := linewiseInsert([]string{""}, 0, "x", types.InsertTextOptions{After: true})
+
+		assert.Equal(t, lineEdit{start: 0, end: 1, lines: []string{"x"}}, edit)
+	})
+}
+
+func TestClient_InsertText(t *testing.T) {
+	client, cleanup := setupTestNeovim(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	open := func(t *testing.T, content string) string {
+		t.Helper()
+
+		tmpFile := createTempFile(t, content)
+		_, err := client.OpenBuffer(ctx, tmpFile)
+		require.NoError(t, err)
+
+		return filepath.Base(tmpFile)
+	}
+
+	t.Run("inserts at a position", func(t *testing.T) {
+		title := open(t, "héllo world")
+
+		result, err := client.InsertText(ctx, title, "big ", types.InsertTextOptions{Line: 1, Column: 7, Encoding: PositionEncodingCodepoint})
+		require.NoError(t, err)
+		assert.Equal(t, types.TextRange{StartLine: 1, StartColumn: 7, EndLine: 1, EndColumn: 11}, result.Changed)
+
+		lines, _, err := client.GetBufferLines(ctx, title, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"héllo big world"}, lines)
+	})
+
+	t.Run("does not run text as commands", func(t *testing.T) {
+		title := open(t, "keep")
+
+		_, err := client.InsertText(ctx, title, "dd", types.InsertTextOptions{After: true})
+		require.NoError(t, err)
+
+		lines, _, err := client.GetBufferLines(ctx, title, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"kddeep"}, lines)
+	})
+
+	t.Run("inserts lines below", func(t *testing.T) {
+		title := open(t, "first\nlast")
+
+		result, err := client.InsertText(ctx, title, "second\nthird\n", types.InsertTextOptions{Line: 1, Linewise: true, After: true})
+		require.NoError(t, err)
+		assert.Equal(t, types.TextRange{StartLine: 2, StartColumn: 1, EndLine: 3, EndColumn: 6}, result.Changed)
+
+		lines, _, err := client.GetBufferLines(ctx, title, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"first", "second", "third", "last"}, lines)
+	})
+
+	t.Run("rejects lines past the end", func(t *testing.T) {
+		title := open(t, "one")
+
+		_, err := client.InsertText(ctx, title, "x", types.InsertTextOptions{Line: 3})
+		assert.ErrorIs(t, err, ErrInvalidRange)
+	})
+}
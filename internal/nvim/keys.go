@@ -0,0 +1,191 @@
+package nvim
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/neovim/go-client/nvim"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// luaFeedKeys runs keys in key notation with :normal or through the typeahead, in win
+// when it is not 0. Neovim is put in normal mode before the keys and again after them
+// when they leave it in another mode. :normal ends an incomplete command or insert mode
+// itself once the keys run out, so the keys are followed by a <Cmd> that reads the mode
+// they left neovim in first. It returns the modes around the keys and the error they
+// raised.
+const luaFeedKeys = `
+	local keys, method, remap, escape, win, first, last = ...
+	local r = { before = '', after = '', final = '', blocked = false, restored = false, invalid_window = false, error = '' }
+	local function termcodes(s)
+		return vim.api.nvim_replace_termcodes(s, true, true, true)
+	end
+	local function to_normal()
+		local mode = vim.api.nvim_get_mode()
+		if mode.mode == 'n' and not mode.blocking then
+			return false
+		end
+		if mode.blocking then
+			vim.api.nvim_input('<C-\\><C-n>')
+		else
+			vim.api.nvim_feedkeys(termcodes('<C-\\><C-n>'), 'nx', false)
+		end
+		return true
+	end
+
+	local mode = vim.api.nvim_get_mode()
+	r.before, r.blocked = mode.mode, mode.blocking
+	if mode.blocking then
+		return r
+	end
+	if win ~= 0 and not vim.api.nvim_win_is_valid(win) then
+		r.invalid_window = true
+		return r
+	end
+	to_normal()
+
+	keys = termcodes(keys)
+	if escape then
+		keys = keys .. termcodes('<Esc>')
+	end
+	local function run()
+		if method == 'normal' then
+			vim.g.neovim_mcp_keys_mode = nil
+			local read_mode = termcodes('<Cmd>lua vim.g.neovim_mcp_keys_mode = vim.api.nvim_get_mode().mode<CR>')
+			local cmd = { cmd = 'normal', args = { keys .. read_mode }, bang = not remap }
+			if first > 0 then
+				cmd.range = { first, last }
+			end
+			vim.api.nvim_cmd(cmd, {})
+		else
+			vim.api.nvim_feedkeys(keys, (remap and 'm' or 'n') .. 'x!', false)
+		end
+	end
+	local ok, err = pcall(function()
+		if win ~= 0 then
+			vim.api.nvim_win_call(win, run)
+		else
+			run()
+		end
+	end)
+	if not ok then
+		r.error = tostring(err)
+	end
+
+	mode = vim.api.nvim_get_mode()
+	r.after, r.blocked = mode.mode, mode.blocking
+	if method == 'normal' and vim.g.neovim_mcp_keys_mode then
+		r.after = vim.g.neovim_mcp_keys_mode
+		vim.g.neovim_mcp_keys_mode = nil
+	end
+	local restored = to_normal()
+	r.final = vim.api.nvim_get_mode().mode
+	r.restored = restored or r.after ~= r.final
+	return r
+`
+
+// feedKeysOutcome is the result of luaFeedKeys
+type feedKeysOutcome struct {
+	Before        string `msgpack:"before"`
+	After         string `msgpack:"after"`
+	Final         string `msgpack:"final"`
+	Blocked       bool   `msgpack:"blocked"`
+	Restored      bool   `msgpack:"restored"`
+	InvalidWindow bool   `msgpack:"invalid_window"`
+	Error         string `msgpack:"error"`
+}
+
+// FeedKeys runs normal mode keys in key notation, such as motions and operators that
+// have no API equivalent, and reports the state they left neovim in before putting it
+// back in normal mode
+func (c *Client) FeedKeys(ctx context.Context, keys string, opts types.FeedKeysOptions) (types.FeedKeysResult, error) {
+	if err := ctx.Err(); err != nil {
+		return types.FeedKeysResult{}, fmt.Errorf("failed to feed keys: %w", err)
+	}
+
+	if err := validateFeedKeys(keys, opts); err != nil {
+		return types.FeedKeysResult{}, fmt.Errorf("failed to feed keys: %w", err)
+	}
+
+	if opts.Method == "" {
+		opts.Method = types.FeedKeysNormal
+	}
+
+	var outcome feedKeysOutcome
+
+	err := c.rpc(ctx, func(v *nvim.Nvim) error {
+		return v.ExecLua(luaFeedKeys, &outcome, keys, opts.Method, opts.Remap, opts.Escape, opts.Window, opts.StartLine, opts.EndLine)
+	})
+	if err != nil {
+		return types.FeedKeysResult{}, fmt.Errorf("failed to feed keys: %w", err)
+	}
+
+	switch {
+	case outcome.InvalidWindow:
+		return types.FeedKeysResult{}, fmt.Errorf("failed to feed keys: %w: %d", ErrWindowNotFound, opts.Window)
+	case outcome.After == "" && outcome.Blocked:
+		return types.FeedKeysResult{}, fmt.Errorf("failed to feed keys: %w in mode `%s`, answer the prompt first", ErrEditorBlocked, outcome.Before)
+	case outcome.Error != "":
+		return types.FeedKeysResult{}, fmt.Errorf("failed to feed keys: %w: %s", ErrKeysFailed, outcome.Error)
+	}
+
+	return types.FeedKeysResult{
+		ModeBefore: outcome.Before,
+		ModeAfter:  outcome.After,
+		State:      editorState(outcome.After, outcome.Blocked),
+		Restored:   outcome.Restored,
+		Mode:       outcome.Final,
+	}, nil
+}
+
+// ----------------------------------------------------------------------------
+
+// validateFeedKeys checks the keys and options of FeedKeys
+func validateFeedKeys(keys string, opts types.FeedKeysOptions) error {
+	if keys == "" {
+		return fmt.Errorf("%w: no keys given", ErrKeysFailed)
+	}
+
+	switch opts.Method {
+	case "", types.FeedKeysNormal:
+	case types.FeedKeysTypeahead:
+		if opts.StartLine != 0 {
+			return fmt.Errorf("%w: a range needs the %s method", ErrInvalidRange, types.FeedKeysNormal)
+		}
+	default:
+		return fmt.Errorf("%w: unknown method `%s`, expected %s or %s", ErrKeysFailed, opts.Method,
+			types.FeedKeysNormal, types.FeedKeysTypeahead)
+	}
+
+	if opts.StartLine < 0 || (opts.StartLine > 0 && opts.EndLine < opts.StartLine) {
+		return fmt.Errorf("%w: lines %d to %d", ErrInvalidRange, opts.StartLine, opts.EndLine)
+	}
+
+	return nil
+}
+
+// editorState classifies a mode() string, see :help mode()
+func editorState(mode string, blocked bool) string {
+	switch {
+	case blocked, strings.HasPrefix(mode, "r"):
+		return types.EditorStateBlocked
+	case mode == "n":
+		return types.EditorStateNormal
+	case strings.HasPrefix(mode, "no"):
+		return types.EditorStatePending
+	case strings.HasPrefix(mode, "n"):
+		// niI and friends, normal mode entered with i_CTRL-O
+		return types.EditorStateInsert
+	case strings.HasPrefix(mode, "i"), strings.HasPrefix(mode, "R"):
+		return types.EditorStateInsert
+	case strings.HasPrefix(mode, "c"):
+		return types.EditorStateCmdline
+	case strings.HasPrefix(mode, "t"):
+		return types.EditorStateTerminal
+	default:
+		// v, V, CTRL-V and the select modes
+		return types.EditorStateVisual
+	}
+}
@@ -0,0 +1,125 @@
+package nvim
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestEditorState(t *testing.T) {
+	tests := []struct {
+		mode    string
+		blocked bool
+		want    string
+	}{
+		{mode: "n", want: types.EditorStateNormal},
+		{mode: "no", want: types.EditorStatePending},
+		{mode: "nov", want: types.EditorStatePending},
+		{mode: "niI", want: types.EditorStateInsert},
+		{mode: "i", want: types.EditorStateInsert},
+		{mode: "Rv", want: types.EditorStateInsert},
+		{mode: "V", want: types.EditorStateVisual},
+		{mode: "c", want: types.EditorStateCmdline},
+		{mode: "t", want: types.EditorStateTerminal},
+		{mode: "rm", want: types.EditorStateBlocked},
+		{mode: "n", blocked: true, want: types.EditorStateBlocked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			assert.Equal(t, tt.want, editorState(tt.mode, tt.blocked))
+		})
+	}
+}
+
+func TestValidateFeedKeys(t *testing.T) {
+	assert.NoError(t, validateFeedKeys("dap", types.FeedKeysOptions{}))
+	assert.NoError(t, validateFeedKeys(">>", types.FeedKeysOptions{StartLine: 2, EndLine: 4}))
+	assert.ErrorIs(t, validateFeedKeys(">>", types.FeedKeysOptions{StartLine: 4, EndLine: 2}), ErrInvalidRange)
+	assert.ErrorIs(t, validateFeedKeys(">>", types.FeedKeysOptions{Method: types.FeedKeysTypeahead, StartLine: 1, EndLine: 1}), ErrInvalidRange)
+	assert.ErrorIs(t, validateFeedKeys("x", types.FeedKeysOptions{Method: "input"}), ErrKeysFailed)
+	assert.ErrorIs(t, validateFeedKeys("", types.FeedKeysOptions{}), ErrKeysFailed)
+}
+
+func TestClient_FeedKeys(t *testing.T) {
+	client, cleanup := setupTestNeovim(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	open := func(t *testing.T, content string) string {
+		t.Helper()
+
+		tmpFile := createTempFile(t, content)
+		_, err := client.OpenBuffer(ctx, tmpFile)
+		require.NoError(t, err)
+
+		return filepath.Base(tmpFile)
+	}
+
+	t.Run("runs an operator", func(t *testing.T) {
+		title := open(t, "one\n\ntwo\nthree")
+
+		result, err := client.FeedKeys(ctx, "dap", types.FeedKeysOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, types.EditorStateNormal, result.State)
+
+		lines, _, err := client.GetBufferLines(ctx, title, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"two", "three"}, lines)
+	})
+
+	t.Run("repeats over a range", func(t *testing.T) {
+		title := open(t, "a\nb\nc")
+
+		_, err := client.FeedKeys(ctx, "A;", types.FeedKeysOptions{StartLine: 1, EndLine: 2})
+		require.NoError(t, err)
+
+		lines, _, err := client.GetBufferLines(ctx, title, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a;", "b;", "c"}, lines)
+	})
+
+	t.Run("restores normal mode", func(t *testing.T) {
+		title := open(t, "text")
+
+		result, err := client.FeedKeys(ctx, "A!", types.FeedKeysOptions{Method: types.FeedKeysTypeahead})
+		require.NoError(t, err)
+		assert.Equal(t, types.EditorStateInsert, result.State)
+		assert.True(t, result.Restored)
+		assert.Equal(t, "n", result.Mode)
+
+		lines, _, err := client.GetBufferLines(ctx, title, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"text!"}, lines)
+	})
+
+	t.Run("reads the mode the normal method leaves", func(t *testing.T) {
+		title := open(t, "word")
+
+		result, err := client.FeedKeys(ctx, "A!", types.FeedKeysOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "i", result.ModeAfter)
+		assert.Equal(t, types.EditorStateInsert, result.State)
+		assert.True(t, result.Restored)
+		assert.Equal(t, "n", result.Mode)
+
+		result, err = client.FeedKeys(ctx, "d", types.FeedKeysOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, types.EditorStatePending, result.State)
+
+		lines, _, err := client.GetBufferLines(ctx, title, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"word!"}, lines)
+	})
+
+	t.Run("reports errors", func(t *testing.T) {
+		_, err := client.FeedKeys(ctx, "x", types.FeedKeysOptions{Window: 9999})
+		assert.ErrorIs(t, err, ErrWindowNotFound)
+	})
+}
@@ -0,0 +1,394 @@
+package nvim
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/neovim/go-client/nvim"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// DefaultLSPTimeout is how long FindLocations waits for the language servers by default
+const DefaultLSPTimeout = 5 * time.Second
+
+// locationMethods maps location kinds to their LSP methods
+var locationMethods = map[string]string{
+	types.LocationDefinition:     "textDocument/definition",
+	types.LocationReferences:     "textDocument/references",
+	types.LocationImplementation: "textDocument/implementation",
+	types.LocationTypeDefinition: "textDocument/typeDefinition",
+	types.LocationDeclaration:    "textDocument/declaration",
+}
+
+// luaSourceLine defines source_line(path, lnum), which returns the 0-based line lnum
+// of a file from its loaded buffer, else from disk
+const luaSourceLine = `
+	local buffers, files = {}, {}
+	for _, b in ipairs(vim.api.nvim_list_bufs()) do
+		if vim.api.nvim_buf_is_loaded(b) then
+			buffers[vim.api.nvim_buf_get_name(b)] = b
+		end
+	end
+	local function source_line(path, lnum)
+		if buffers[path] then
+			return vim.api.nvim_buf_get_lines(buffers[path], lnum, lnum + 1, false)[1] or ''
+		end
+		if files[path] == nil then
+			files[path] = vim.fn.filereadable(path) == 1 and vim.fn.readfile(path) or {}
+		end
+		return files[path][lnum + 1] or ''
+	end
+	local function uri_path(uri)
+		local ok, path = pcall(vim.uri_to_fname, uri)
+		return ok and path or uri
+	end
+`
+
+// luaLSPRequest defines lsp_request(buf, method, uri, row, chars, timeout, context),
+// which sends a request about a position of the document uri, the buffer's own when
+// empty, to the clients of buf supporting method and waits up to timeout ms. The
+// character of the position is given per offset encoding, neovim 0.11 and later send
+// each client its own while older versions use the encoding of the first client. It
+// returns the status of each client, their non-empty results and the error of the wait.
+const luaLSPRequest = `
+	local function lsp_request(buf, method, uri, row, chars, timeout, context)
+		local statuses, answers = {}, {}
+		local clients = vim.lsp.get_clients({ bufnr = buf, method = method })
+		if #clients == 0 then
+			return statuses, answers, ''
+		end
+		if uri == '' then
+			uri = vim.uri_from_bufnr(buf)
+		end
+		local function params(client)
+			local p = {
+				textDocument = { uri = uri },
+				position = { line = row, character = chars[client.offset_encoding] or chars['utf-16'] },
+			}
+			if type(context) == 'table' then
+				p.context = context
+			end
+			return p
+		end
+		local arg = vim.fn.has('nvim-0.11') == 1 and params or params(clients[1])
+		local responses, err = vim.lsp.buf_request_sync(buf, method, arg, timeout)
+		for _, client in ipairs(clients) do
+			local response = (responses or {})[client.id]
+			local status = { name = client.name, answered = response ~= nil, error = '' }
+			if response and type(response.err) == 'table' then
+				status.error = tostring(response.err.message or response.err.code)
+			end
+			table.insert(statuses, status)
+			if response and type(response.result) == 'table' then
+				table.insert(answers, { client = client, result = response.result })
+			end
+		end
+		return statuses, answers, responses and '' or (err or 'timeout')
+	end
+`
+
+// luaLocations sends an LSP location request with lsp_request and returns the
+// locations in the client's encoding with their start and end lines
+const luaLocations = luaSourceLine + luaLSPRequest + `
+	local buf, method, uri, row, chars, context, timeout = ...
+	local clients, answers, err = lsp_request(buf, method, uri, row, chars, timeout, context)
+	local r = { clients = clients, locations = {}, error = err }
+	for _, answer in ipairs(answers) do
+		local result = answer.result
+		if result.uri or result.targetUri then
+			result = { result }
+		end
+		for _, loc in ipairs(result) do
+			local range = loc.targetSelectionRange or loc.range
+			local path = uri_path(loc.targetUri or loc.uri)
+			table.insert(r.locations, {
+				client = answer.client.name,
+				encoding = answer.client.offset_encoding or 'utf-16',
+				path = path,
+				start_line = range.start.line,
+				start_char = range.start.character,
+				end_line = range['end'].line,
+				end_char = range['end'].character,
+				start_text = source_line(path, range.start.line),
+				end_text = source_line(path, range['end'].line),
+			})
+		end
+	end
+	return r
+`
+
+// luaWorkspaceSymbols asks the clients of buf for the workspace symbols named query and
+// waits up to timeout ms. It returns their positions in the client's encoding with the
+// source line and the number of clients asked.
+const luaWorkspaceSymbols = luaSourceLine + `
+	local buf, query, timeout = ...
+	local r = { clients = 0, symbols = {}, error = '' }
+	local clients = vim.lsp.get_clients({ bufnr = buf, method = 'workspace/symbol' })
+	r.clients = #clients
+	if #clients == 0 then
+		return r
+	end
+	local encodings = {}
+	for _, client in ipairs(clients) do
+		encodings[client.id] = client.offset_encoding or 'utf-16'
+	end
+	local responses, err = vim.lsp.buf_request_sync(buf, 'workspace/symbol', { query = query }, timeout)
+	if not responses then
+		r.error = err or 'timeout'
+		return r
+	end
+	for id, response in pairs(responses) do
+		if type(response.result) == 'table' then
+			for _, s in ipairs(response.result) do
+				if s.name == query and s.location and s.location.range then
+					local path = uri_path(s.location.uri)
+					local start = s.location.range.start
+					table.insert(r.symbols, {
+						uri = s.location.uri,
+						path = path,
+						line = start.line,
+						character = start.character,
+						encoding = encodings[id] or 'utf-16',
+						text = source_line(path, start.line),
+					})
+				end
+			end
+		end
+	end
+	return r
+`
+
+// locationsOutcome is the result of luaLocations
+type locationsOutcome struct {
+	Clients   []luaClientStatus `msgpack:"clients"`
+	Locations []luaLocation     `msgpack:"locations"`
+	Error     string            `msgpack:"error"`
+}
+
+// luaClientStatus mirrors the status of a client of lsp_request
+type luaClientStatus struct {
+	Name     string `msgpack:"name"`
+	Answered bool   `msgpack:"answered"`
+	Error    string `msgpack:"error"`
+}
+
+// luaLocation mirrors a location of luaLocations (0-based, LSP characters)
+type luaLocation struct {
+	Client    string `msgpack:"client"`
+	Encoding  string `msgpack:"encoding"`
+	Path      string `msgpack:"path"`
+	StartLine int    `msgpack:"start_line"`
+	StartChar int    `msgpack:"start_char"`
+	EndLine   int    `msgpack:"end_line"`
+	EndChar   int    `msgpack:"end_char"`
+	StartText string `msgpack:"start_text"`
+	EndText   string `msgpack:"end_text"`
+}
+
+// symbolsOutcome is the result of luaWorkspaceSymbols
+type symbolsOutcome struct {
+	Clients int         `msgpack:"clients"`
+	Symbols []luaSymbol `msgpack:"symbols"`
+	Error   string      `msgpack:"error"`
+}
+
+// luaSymbol mirrors a symbol of luaWorkspaceSymbols (0-based, LSP characters)
+type luaSymbol struct {
+	URI       string `msgpack:"uri"`
+	Path      string `msgpack:"path"`
+	Line      int    `msgpack:"line"`
+	Character int    `msgpack:"character"`
+	Encoding  string `msgpack:"encoding"`
+	Text      string `msgpack:"text"`
+}
+
+// lspPosition is a position to send in an LSP request, the document uri is empty for
+// the buffer the request is sent from
+type lspPosition struct {
+	uri   string
+	row   int
+	chars map[string]int
+}
+
+// FindLocations asks the language servers attached to a buffer, the current buffer for
+// an empty title, where the symbol at a position is defined, referenced, implemented,
+// typed or declared depending on kind. With opts.Symbol the position is the one of
+// the workspace symbol with that name. Servers that do not answer before the timeout
+// are reported, not failed.
+func (c *Client) FindLocations(ctx context.Context, kind, title string, opts types.LocationOptions) (types.LocationResults, error) {
+	if err := ctx.Err(); err != nil {
+		return types.LocationResults{}, fmt.Errorf("failed to find locations: %w", err)
+	}
+
+	method, ok := locationMethods[kind]
+	if !ok {
+		return types.LocationResults{}, fmt.Errorf("failed to find locations: unknown kind `%s`", kind)
+	}
+
+	buf, pos, err := c.lspTarget(ctx, title, &opts)
+	if err != nil {
+		return types.LocationResults{}, fmt.Errorf("failed to find %s locations: %w", kind, err)
+	}
+
+	var context any
+	if kind == types.LocationReferences {
+		context = map[string]any{"includeDeclaration": opts.IncludeDeclaration}
+	}
+
+	var outcome locationsOutcome
+	err = c.rpc(ctx, func(v *nvim.Nvim) error {
+		return v.ExecLua(luaLocations, &outcome, buf.Handle, method, pos.uri, pos.row, pos.chars, context, opts.Timeout.Milliseconds())
+	})
+	if err != nil {
+		return types.LocationResults{}, fmt.Errorf("failed to find %s locations in buffer `%s`: %w", kind, buf.Title, err)
+	}
+
+	if len(outcome.Clients) == 0 {
+		return types.LocationResults{}, fmt.Errorf("failed to find %s locations in buffer `%s`: %w: none supports %s",
+			kind, buf.Title, ErrNoLanguageServer, method)
+	}
+
+	results := types.LocationResults{Locations: []types.Location{}, Clients: clientStatuses(outcome.Clients), TimedOut: outcome.Error != ""}
+	for _, loc := range outcome.Locations {
+		results.Locations = append(results.Locations, loc.location())
+	}
+
+	return results, nil
+}
+
+// ----------------------------------------------------------------------------
+
+// lspTarget returns the buffer an LSP request is sent from, the current buffer for an
+// empty title, and the position it asks about. It validates opts and sets the default
+// timeout.
+func (c *Client) lspTarget(ctx context.Context, title string, opts *types.LocationOptions) (types.BufferInfo, lspPosition, error) {
+	if err := ValidateEncoding(opts.Encoding); err != nil {
+		return types.BufferInfo{}, lspPosition{}, err
+	}
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultLSPTimeout
+	}
+
+	buf, err := c.bufferOrCurrent(ctx, title)
+	if err != nil {
+		return types.BufferInfo{}, lspPosition{}, err
+	}
+
+	var pos lspPosition
+	if opts.Symbol != "" {
+		pos, err = c.symbolPosition(ctx, buf.Handle, opts.Symbol, opts.Timeout)
+	} else {
+		pos, err = c.requestPosition(ctx, buf.Handle, *opts)
+	}
+
+	if err != nil {
+		return types.BufferInfo{}, lspPosition{}, fmt.Errorf("buffer `%s`: %w", buf.Title, err)
+	}
+
+	return buf, pos, nil
+}
+
+// requestPosition returns the position of the buffer selected by opts
+func (c *Client) requestPosition(ctx context.Context, buf nvim.Buffer, opts types.LocationOptions) (lspPosition, error) {
+	lines, _, err := c.readBuffer(ctx, buf)
+	if err != nil {
+		return lspPosition{}, err
+	}
+
+	row, col, err := c.bufferPosition(ctx, buf, lines, opts.Line, opts.Column, opts.Encoding)
+	if err != nil {
+		return lspPosition{}, err
+	}
+
+	return lspPosition{row: row, chars: lspCharacters(lines[row], col)}, nil
+}
+
+// symbolPosition returns the position of the workspace symbol named name, the first
+// one when the servers know several
+func (c *Client) symbolPosition(ctx context.Context, buf nvim.Buffer, name string, timeout time.Duration) (lspPosition, error) {
+	var outcome symbolsOutcome
+
+	err := c.rpc(ctx, func(v *nvim.Nvim) error {
+		return v.ExecLua(luaWorkspaceSymbols, &outcome, buf, name, timeout.Milliseconds())
+	})
+	if err != nil {
+		return lspPosition{}, fmt.Errorf("failed to find symbol `%s`: %w", name, err)
+	}
+
+	switch {
+	case outcome.Clients == 0:
+		return lspPosition{}, fmt.Errorf("%w: none supports workspace/symbol", ErrNoLanguageServer)
+	case outcome.Error != "":
+		return lspPosition{}, fmt.Errorf("failed to find symbol `%s`: %s", name, outcome.Error)
+	case len(outcome.Symbols) == 0:
+		return lspPosition{}, fmt.Errorf("%w: `%s`", ErrSymbolNotFound, name)
+	}
+
+	symbol := outcome.Symbols[0]
+	col := lspByteColumn(symbol.Text, symbol.Character, symbol.Encoding)
+
+	return lspPosition{uri: symbol.URI, row: symbol.Line, chars: lspCharacters(symbol.Text, col)}, nil
+}
+
+// clientStatuses converts the client statuses of lsp_request
+func clientStatuses(clients []luaClientStatus) []types.LSPClientStatus {
+	statuses := make([]types.LSPClientStatus, 0, len(clients))
+	for _, c := range clients {
+		statuses = append(statuses, types.LSPClientStatus{Name: c.Name, Answered: c.Answered, Error: c.Error})
+	}
+
+	return statuses
+}
+
+// location converts a location to 1-based lines and byte columns
+func (l luaLocation) location() types.Location {
+	return types.Location{
+		Path: l.Path,
+		Range: types.TextRange{
+			StartLine:   l.StartLine + 1,
+			StartColumn: lspByteColumn(l.StartText, l.StartChar, l.Encoding) + 1,
+			EndLine:     l.EndLine + 1,
+			EndColumn:   lspByteColumn(l.EndText, l.EndChar, l.Encoding) + 1,
+		},
+		Line:   l.StartText,
+		Client: l.Client,
+	}
+}
+
+// lspEncoding returns the position encoding of an LSP offset encoding
+func lspEncoding(encoding string) string {
+	switch encoding {
+	case "utf-8":
+		return PositionEncodingByte
+	case "utf-32":
+		return PositionEncodingCodepoint
+	default:
+		return PositionEncodingUTF16
+	}
+}
+
+// lspCharacters returns the LSP character of byte column col of line in every offset
+// encoding
+func lspCharacters(line string, col int) map[string]int {
+	prefix := line[:min(col, len(line))]
+
+	return map[string]int{
+		"utf-8":  len(prefix),
+		"utf-16": textUnits(prefix, PositionEncodingUTF16),
+		"utf-32": textUnits(prefix, PositionEncodingCodepoint),
+	}
+}
+
+// lspByteColumn converts a 0-based LSP character of line in an offset encoding to a
+// 0-based byte column, characters past the line end at it
+func lspByteColumn(line string, character int, encoding string) int {
+	col, err := byteColumn(line, character+1, lspEncoding(encoding))
+	if err != nil {
+		return len(line)
+	}
+
+	return col
+}
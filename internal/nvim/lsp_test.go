@@ -0,0 +1,71 @@
+package nvim
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestLSPCharacters(t *testing.T) {
+	// "é" is 2 bytes and 1 utf-16 unit, "𝒳" is 4 bytes and 2 utf-16 units
+	line := "é𝒳 = 1"
+
+	assert.Equal(t, map[string]int{"utf-8": 6, "utf-16": 3, "utf-32": 2}, lspCharacters(line, 6))
+	assert.Equal(t, map[string]int{"utf-8": 0, "utf-16": 0, "utf-32": 0}, lspCharacters(line, 0))
+}
+
+func TestLSPByteColumn(t *testing.T) {
+	line := "é𝒳 = 1"
+
+	assert.Equal(t, 6, lspByteColumn(line, 3, "utf-16"))
+	assert.Equal(t, 6, lspByteColumn(line, 2, "utf-32"))
+	assert.Equal(t, 6, lspByteColumn(line, 6, "utf-8"))
+	assert.Equal(t, len(line), lspByteColumn(line, 99, "utf-16"))
+}
+
+func TestLuaLocation(t *testing.T) {
+	loc := luaLocation{
+		Client:    "gopls",
+		Encoding:  "utf-16",
+		Path:      "/src/main.go",
+		StartLine: 4,
+		StartChar: 1,
+		EndLine:   4,
+		EndChar:   3,
+		StartText: "é𝒳 = 1",
+		EndText:   "é𝒳 = 1",
+	}
+
+	assert.Equal(t, types.Location{
+		Path:   "/src/main.go",
+		Range:  types.TextRange{StartLine: 5, StartColumn: 3, EndLine: 5, EndColumn: 7},
+		Line:   "é𝒳 = 1",
+		Client: "gopls",
+	}, loc.location())
+}
+
+func TestClient_FindLocations(t *testing.T) {
+	client, cleanup := setupTestNeovim(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	_, err := client.OpenBuffer(ctx, createTempFile(t, "package main"))
+	require.NoError(t, err)
+
+	t.Run("fails without a language server", func(t *testing.T) {
+		_, err := client.FindLocations(ctx, types.LocationDefinition, "", types.LocationOptions{Line: 1, Column: 9})
+
+		assert.ErrorIs(t, err, ErrNoLanguageServer)
+	})
+
+	t.Run("rejects unknown kinds", func(t *testing.T) {
+		_, err := client.FindLocations(ctx, "callers", "", types.LocationOptions{})
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,568 @@
+// Package nvimtest provides a mock of types.NeovimClient for unit tests of the MCP
+// tools and resources
+package nvimtest
+
+import (
+	"context"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// Compile-time verification that MockClient implements types.NeovimClient
+var _ types.NeovimClient = (*MockClient)(nil)
+
+// MockClient is a mock implementation of types.NeovimClient for testing
+type MockClient struct {
+	mock.Mock
+}
+
+// NewMockClient creates a new MockClient instance
+func NewMockClient() *MockClient {
+	return &MockClient{}
+}
+
+// GetBuffers returns a list of all open buffers
+func (m *MockClient) GetBuffers(ctx context.Context) ([]types.BufferInfo, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.BufferInfo), args.Error(1)
+}
+
+// GetBufferByTitle finds a buffer by its title
+func (m *MockClient) GetBufferByTitle(ctx context.Context, title string) (types.BufferInfo, error) {
+	args := m.Called(ctx, title)
+	return args.Get(0).(types.BufferInfo), args.Error(1)
+}
+
+// GetCurrentBuffer returns the currently active buffer
+func (m *MockClient) GetCurrentBuffer(ctx context.Context) (types.BufferInfo, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return types.BufferInfo{}, args.Error(1)
+	}
+	return args.Get(0).(types.BufferInfo), args.Error(1)
+}
+
+// OpenBuffer opens a file in a new buffer
+func (m *MockClient) OpenBuffer(ctx context.Context, path string) (types.BufferInfo, error) {
+	args := m.Called(ctx, path)
+	if args.Get(0) == nil {
+		return types.BufferInfo{}, args.Error(1)
+	}
+	return args.Get(0).(types.BufferInfo), args.Error(1)
+}
+
+// CloseBuffer closes a buffer by title
+func (m *MockClient) CloseBuffer(ctx context.Context, title string) error {
+	args := m.Called(ctx, title)
+	return args.Error(0)
+}
+
+// SwitchBuffer switches to a buffer by title
+func (m *MockClient) SwitchBuffer(ctx context.Context, title string) error {
+	args := m.Called(ctx, title)
+	return args.Error(0)
+}
+
+// GetBufferLines retrieves lines from a buffer
+func (m *MockClient) GetBufferLines(ctx context.Context, title string, start, end int) ([]string, int, error) {
+	args := m.Called(ctx, title, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]string), args.Int(1), args.Error(2)
+}
+
+// SetBufferLines sets lines in a buffer
+func (m *MockClient) SetBufferLines(ctx context.Context, title string, start, end int, lines []string, expectedVersion int) (int, error) {
+	args := m.Called(ctx, title, start, end, lines, expectedVersion)
+	return args.Int(0), args.Error(1)
+}
+
+// InsertText inserts literal text into a buffer
+func (m *MockClient) InsertText(ctx context.Context, title, text string, opts types.InsertTextOptions) (types.InsertTextResult, error) {
+	args := m.Called(ctx, title, text, opts)
+	return args.Get(0).(types.InsertTextResult), args.Error(1)
+}
+
+// SetReindentedLines sets lines and re-indents them by the indent rules of the buffer
+func (m *MockClient) SetReindentedLines(ctx context.Context, title string, start, end int, lines []string, expectedVersion int) (types.TextRange, int, error) {
+	args := m.Called(ctx, title, start, end, lines, expectedVersion)
+	return args.Get(0).(types.TextRange), args.Int(1), args.Error(2)
+}
+
+// SendInput queues keys as if typed
+func (m *MockClient) SendInput(ctx context.Context, keys string) error {
+	args := m.Called(ctx, keys)
+	return args.Error(0)
+}
+
+// FeedKeys runs normal mode keys
+func (m *MockClient) FeedKeys(ctx context.Context, keys string, opts types.FeedKeysOptions) (types.FeedKeysResult, error) {
+	args := m.Called(ctx, keys, opts)
+	return args.Get(0).(types.FeedKeysResult), args.Error(1)
+}
+
+// DeleteLines deletes lines from a buffer
+func (m *MockClient) DeleteLines(ctx context.Context, title string, start, end int, expectedVersion int) (int, error) {
+	args := m.Called(ctx, title, start, end, expectedVersion)
+	return args.Int(0), args.Error(1)
+}
+
+// ReplaceText replaces occurrences of text in a buffer
+func (m *MockClient) ReplaceText(ctx context.Context, title, oldText, newText string, opts types.ReplaceTextOptions) (types.ReplaceTextResult, error) {
+	args := m.Called(ctx, title, oldText, newText, opts)
+	return args.Get(0).(types.ReplaceTextResult), args.Error(1)
+}
+
+// GetText returns the text of a range of a buffer
+func (m *MockClient) GetText(ctx context.Context, title string, rng types.TextRange, encoding string) (string, int, error) {
+	args := m.Called(ctx, title, rng, encoding)
+	return args.String(0), args.Int(1), args.Error(2)
+}
+
+// SetText replaces the text of a range of a buffer
+func (m *MockClient) SetText(ctx context.Context, title string, rng types.TextRange, text, encoding string, expectedVersion int) (types.TextRange, int, error) {
+	args := m.Called(ctx, title, rng, text, encoding, expectedVersion)
+	return args.Get(0).(types.TextRange), args.Int(1), args.Error(2)
+}
+
+// ApplyPatch applies a unified diff to buffers
+func (m *MockClient) ApplyPatch(ctx context.Context, patch string, expectedVersions map[string]int) (types.PatchResult, error) {
+	args := m.Called(ctx, patch, expectedVersions)
+	return args.Get(0).(types.PatchResult), args.Error(1)
+}
+
+// ApplyEdits applies edits across buffers as one transaction
+func (m *MockClient) ApplyEdits(ctx context.Context, edits []types.BufferEdit) (types.ApplyEditsResult, error) {
+	args := m.Called(ctx, edits)
+	return args.Get(0).(types.ApplyEditsResult), args.Error(1)
+}
+
+// CreateAnchor names a range of a buffer
+func (m *MockClient) CreateAnchor(ctx context.Context, name, title string, rng types.TextRange) (types.Anchor, error) {
+	args := m.Called(ctx, name, title, rng)
+	return args.Get(0).(types.Anchor), args.Error(1)
+}
+
+// ResolveAnchor returns the current range of an anchor
+func (m *MockClient) ResolveAnchor(ctx context.Context, name string) (types.Anchor, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(types.Anchor), args.Error(1)
+}
+
+// GetCursorPosition returns the current cursor position
+func (m *MockClient) GetCursorPosition(ctx context.Context) (types.CursorPosition, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return types.CursorPosition{}, args.Error(1)
+	}
+	return args.Get(0).(types.CursorPosition), args.Error(1)
+}
+
+// SetCursorPosition sets the cursor position
+func (m *MockClient) SetCursorPosition(ctx context.Context, line, col int) error {
+	args := m.Called(ctx, line, col)
+	return args.Error(0)
+}
+
+// GotoLine moves the cursor to a specific line
+func (m *MockClient) GotoLine(ctx context.Context, line int) error {
+	args := m.Called(ctx, line)
+	return args.Error(0)
+}
+
+// Search finds the matches of a pattern in a buffer
+func (m *MockClient) Search(ctx context.Context, title, pattern string, opts types.SearchOptions) (types.SearchResults, error) {
+	args := m.Called(ctx, title, pattern, opts)
+	return args.Get(0).(types.SearchResults), args.Error(1)
+}
+
+// GrepProject searches the files of a directory
+func (m *MockClient) GrepProject(ctx context.Context, pattern string, opts types.GrepOptions) (types.GrepResults, error) {
+	args := m.Called(ctx, pattern, opts)
+	return args.Get(0).(types.GrepResults), args.Error(1)
+}
+
+// PreviewFindReplace proposes the replacements of the matches of a pattern
+func (m *MockClient) PreviewFindReplace(ctx context.Context, pattern, replacement string, opts types.GrepOptions) (types.FindReplacePreview, error) {
+	args := m.Called(ctx, pattern, replacement, opts)
+	return args.Get(0).(types.FindReplacePreview), args.Error(1)
+}
+
+// ApplyFindReplace replaces the accepted matches of a preview
+func (m *MockClient) ApplyFindReplace(ctx context.Context, pattern, replacement string, ids []string, opts types.GrepOptions) (types.FindReplaceResult, error) {
+	args := m.Called(ctx, pattern, replacement, ids, opts)
+	return args.Get(0).(types.FindReplaceResult), args.Error(1)
+}
+
+// FindLocations asks the language servers for the locations of a symbol
+func (m *MockClient) FindLocations(ctx context.Context, kind, title string, opts types.LocationOptions) (types.LocationResults, error) {
+	args := m.Called(ctx, kind, title, opts)
+	return args.Get(0).(types.LocationResults), args.Error(1)
+}
+
+// Hover asks the language servers for the hover information of a position
+func (m *MockClient) Hover(ctx context.Context, title string, opts types.LocationOptions) (types.HoverResult, error) {
+	args := m.Called(ctx, title, opts)
+	return args.Get(0).(types.HoverResult), args.Error(1)
+}
+
+// SignatureHelp asks the language servers for the signature help of a position
+func (m *MockClient) SignatureHelp(ctx context.Context, title string, opts types.LocationOptions) (types.SignatureHelpResult, error) {
+	args := m.Called(ctx, title, opts)
+	return args.Get(0).(types.SignatureHelpResult), args.Error(1)
+}
+
+// GetWindows returns information about all windows
+func (m *MockClient) GetWindows(ctx context.Context) ([]types.WindowInfo, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.WindowInfo), args.Error(1)
+}
+
+// SplitWindow creates a new window split
+func (m *MockClient) SplitWindow(ctx context.Context, direction string, bufferTitle string) (types.WindowInfo, error) {
+	args := m.Called(ctx, direction, bufferTitle)
+	if args.Get(0) == nil {
+		return types.WindowInfo{}, args.Error(1)
+	}
+	return args.Get(0).(types.WindowInfo), args.Error(1)
+}
+
+// CloseWindow closes a window by ID
+func (m *MockClient) CloseWindow(ctx context.Context, windowID int) error {
+	args := m.Called(ctx, windowID)
+	return args.Error(0)
+}
+
+// ResizeWindow resizes a window
+func (m *MockClient) ResizeWindow(ctx context.Context, windowID, width, height int) error {
+	args := m.Called(ctx, windowID, width, height)
+	return args.Error(0)
+}
+
+// ExecCommand executes a Vim Ex command
+func (m *MockClient) ExecCommand(ctx context.Context, command string) (string, error) {
+	args := m.Called(ctx, command)
+	return args.String(0), args.Error(1)
+}
+
+// ExecLua executes Lua code
+func (m *MockClient) ExecLua(ctx context.Context, code string, luaArgs []any) (any, error) {
+	args := m.Called(ctx, code, luaArgs)
+	return args.Get(0), args.Error(1)
+}
+
+// CallFunction calls a Vim/Neovim function
+func (m *MockClient) CallFunction(ctx context.Context, fname string, fnArgs []any) (any, error) {
+	args := m.Called(ctx, fname, fnArgs)
+	return args.Get(0), args.Error(1)
+}
+
+// GetDiagnostics returns diagnostics grouped by buffer
+func (m *MockClient) GetDiagnostics(ctx context.Context, bufferTitle string) ([]types.BufferDiagnostics, error) {
+	args := m.Called(ctx, bufferTitle)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.BufferDiagnostics), args.Error(1)
+}
+
+// WatchDiagnostics registers a diagnostics change callback
+func (m *MockClient) WatchDiagnostics(ctx context.Context, onChange func()) (func(context.Context) error, error) {
+	args := m.Called(ctx, onChange)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(func(context.Context) error), args.Error(1)
+}
+
+// GetEditorConfig describes the running editor
+func (m *MockClient) GetEditorConfig(ctx context.Context) (types.EditorConfig, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(types.EditorConfig), args.Error(1)
+}
+
+// GetPlugins returns the installed plugins
+func (m *MockClient) GetPlugins(ctx context.Context) (types.PluginInventory, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(types.PluginInventory), args.Error(1)
+}
+
+// GetConnectionStatus returns the state of the neovim connection
+func (m *MockClient) GetConnectionStatus(ctx context.Context) (types.ConnectionStatus, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(types.ConnectionStatus), args.Error(1)
+}
+
+// Close closes the Neovim connection
+func (m *MockClient) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+// --- Test Helper Functions ---
+
+// MockBufferInfo creates a BufferInfo for testing
+func MockBufferInfo(handle int, title, name string) types.BufferInfo {
+	return types.BufferInfo{
+		Handle:    nvim.Buffer(handle),
+		Title:     title,
+		Path:      name,
+		Loaded:    true,
+		Changed:   false,
+		LineCount: 100,
+	}
+}
+
+// MockWindowInfo creates a WindowInfo for testing
+func MockWindowInfo(handle int, buffer types.BufferInfo, width, height int) types.WindowInfo {
+	return types.WindowInfo{
+		Handle: nvim.Window(handle),
+		Buffer: buffer,
+		Width:  width,
+		Height: height,
+	}
+}
+
+// MockCursorPosition creates a CursorPosition for testing
+func MockCursorPosition(line, col int) types.CursorPosition {
+	return types.CursorPosition{
+		Line:   line,
+		Column: col,
+	}
+}
+
+// MockSearchResult creates a SearchResult for testing
+func MockSearchResult(line, col int, matchText string) types.SearchResult {
+	return types.SearchResult{
+		Line:      line,
+		Column:    col,
+		MatchText: matchText,
+	}
+}
+
+// --- Setup Helper Methods ---
+
+// SetupGetBuffers configures the mock to return the given buffers
+func (m *MockClient) SetupGetBuffers(buffers []types.BufferInfo, err error) *mock.Call {
+	return m.On("GetBuffers", mock.Anything).Return(buffers, err)
+}
+
+// SetupGetCurrentBuffer configures the mock to return the given buffer
+func (m *MockClient) SetupGetCurrentBuffer(buffer types.BufferInfo, err error) *mock.Call {
+	return m.On("GetCurrentBuffer", mock.Anything).Return(buffer, err)
+}
+
+// SetupOpenBuffer configures the mock to return the given buffer when opening a path
+func (m *MockClient) SetupOpenBuffer(path string, buffer types.BufferInfo, err error) *mock.Call {
+	return m.On("OpenBuffer", mock.Anything, path).Return(buffer, err)
+}
+
+// SetupCloseBuffer configures the mock for closing a buffer
+func (m *MockClient) SetupCloseBuffer(title string, err error) *mock.Call {
+	return m.On("CloseBuffer", mock.Anything, title).Return(err)
+}
+
+// SetupSwitchBuffer configures the mock for switching buffers
+func (m *MockClient) SetupSwitchBuffer(title string, err error) *mock.Call {
+	return m.On("SwitchBuffer", mock.Anything, title).Return(err)
+}
+
+// SetupGetBufferByTitle configures the mock to return a buffer
+func (m *MockClient) SetupGetBufferByTitle(title string, buffer types.BufferInfo, err error) *mock.Call {
+	return m.On("GetBufferByTitle", mock.Anything, title).Return(buffer, err)
+}
+
+// SetupGetBufferLines configures the mock to return lines from a buffer
+func (m *MockClient) SetupGetBufferLines(title string, start, end int, lines []string, version int, err error) *mock.Call {
+	return m.On("GetBufferLines", mock.Anything, title, start, end).Return(lines, version, err)
+}
+
+// SetupSetBufferLines configures the mock for setting buffer lines
+func (m *MockClient) SetupSetBufferLines(title string, start, end int, lines []string, expectedVersion, version int, err error) *mock.Call {
+	return m.On("SetBufferLines", mock.Anything, title, start, end, lines, expectedVersion).Return(version, err)
+}
+
+// SetupInsertText configures the mock for inserting text
+func (m *MockClient) SetupInsertText(title, text string, opts types.InsertTextOptions, result types.InsertTextResult, err error) *mock.Call {
+	return m.On("InsertText", mock.Anything, title, text, opts).Return(result, err)
+}
+
+// SetupSetReindentedLines configures the mock for setting re-indented lines
+func (m *MockClient) SetupSetReindentedLines(title string, start, end int, lines []string, expectedVersion int, changed types.TextRange, version int, err error) *mock.Call {
+	return m.On("SetReindentedLines", mock.Anything, title, start, end, lines, expectedVersion).Return(changed, version, err)
+}
+
+// SetupSendInput configures the mock for sending keys
+func (m *MockClient) SetupSendInput(keys string, err error) *mock.Call {
+	return m.On("SendInput", mock.Anything, keys).Return(err)
+}
+
+// SetupFeedKeys configures the mock for running normal mode keys
+func (m *MockClient) SetupFeedKeys(keys string, opts types.FeedKeysOptions, result types.FeedKeysResult, err error) *mock.Call {
+	return m.On("FeedKeys", mock.Anything, keys, opts).Return(result, err)
+}
+
+// SetupDeleteLines configures the mock for deleting lines
+func (m *MockClient) SetupDeleteLines(title string, start, end int, expectedVersion, version int, err error) *mock.Call {
+	return m.On("DeleteLines", mock.Anything, title, start, end, expectedVersion).Return(version, err)
+}
+
+// SetupReplaceText configures the mock for replacing text in a buffer
+func (m *MockClient) SetupReplaceText(title, oldText, newText string, opts types.ReplaceTextOptions, result types.ReplaceTextResult, err error) *mock.Call {
+	return m.On("ReplaceText", mock.Anything, title, oldText, newText, opts).Return(result, err)
+}
+
+// SetupGetText configures the mock for reading a range of a buffer
+func (m *MockClient) SetupGetText(title string, rng types.TextRange, encoding, text string, version int, err error) *mock.Call {
+	return m.On("GetText", mock.Anything, title, rng, encoding).Return(text, version, err)
+}
+
+// SetupSetText configures the mock for replacing a range of a buffer
+func (m *MockClient) SetupSetText(title string, rng types.TextRange, text, encoding string, expectedVersion int, changed types.TextRange, version int, err error) *mock.Call {
+	return m.On("SetText", mock.Anything, title, rng, text, encoding, expectedVersion).Return(changed, version, err)
+}
+
+// SetupApplyPatch configures the mock for applying a unified diff
+func (m *MockClient) SetupApplyPatch(patch string, expectedVersions map[string]int, result types.PatchResult, err error) *mock.Call {
+	return m.On("ApplyPatch", mock.Anything, patch, expectedVersions).Return(result, err)
+}
+
+// SetupApplyEdits configures the mock for applying edits as one transaction
+func (m *MockClient) SetupApplyEdits(edits []types.BufferEdit, result types.ApplyEditsResult, err error) *mock.Call {
+	return m.On("ApplyEdits", mock.Anything, edits).Return(result, err)
+}
+
+// SetupCreateAnchor configures the mock for creating an anchor
+func (m *MockClient) SetupCreateAnchor(name, title string, rng types.TextRange, anchor types.Anchor, err error) *mock.Call {
+	return m.On("CreateAnchor", mock.Anything, name, title, rng).Return(anchor, err)
+}
+
+// SetupResolveAnchor configures the mock to resolve an anchor
+func (m *MockClient) SetupResolveAnchor(name string, anchor types.Anchor, err error) *mock.Call {
+	return m.On("ResolveAnchor", mock.Anything, name).Return(anchor, err)
+}
+
+// SetupGetCursorPosition configures the mock to return a cursor position
+func (m *MockClient) SetupGetCursorPosition(pos types.CursorPosition, err error) *mock.Call {
+	return m.On("GetCursorPosition", mock.Anything).Return(pos, err)
+}
+
+// SetupSetCursorPosition configures the mock for setting cursor position
+func (m *MockClient) SetupSetCursorPosition(line, col int, err error) *mock.Call {
+	return m.On("SetCursorPosition", mock.Anything, line, col).Return(err)
+}
+
+// SetupGotoLine configures the mock for going to a line
+func (m *MockClient) SetupGotoLine(line int, err error) *mock.Call {
+	return m.On("GotoLine", mock.Anything, line).Return(err)
+}
+
+// SetupSearch configures the mock to return search results
+func (m *MockClient) SetupSearch(title, pattern string, opts types.SearchOptions, results types.SearchResults, err error) *mock.Call {
+	return m.On("Search", mock.Anything, title, pattern, opts).Return(results, err)
+}
+
+// SetupGrepProject configures the mock to return grep results
+func (m *MockClient) SetupGrepProject(pattern string, opts types.GrepOptions, results types.GrepResults, err error) *mock.Call {
+	return m.On("GrepProject", mock.Anything, pattern, opts).Return(results, err)
+}
+
+// SetupPreviewFindReplace configures the mock to return a replacement preview
+func (m *MockClient) SetupPreviewFindReplace(pattern, replacement string, opts types.GrepOptions, preview types.FindReplacePreview, err error) *mock.Call {
+	return m.On("PreviewFindReplace", mock.Anything, pattern, replacement, opts).Return(preview, err)
+}
+
+// SetupApplyFindReplace configures the mock to return the result of applied replacements
+func (m *MockClient) SetupApplyFindReplace(pattern, replacement string, ids []string, opts types.GrepOptions, result types.FindReplaceResult, err error) *mock.Call {
+	return m.On("ApplyFindReplace", mock.Anything, pattern, replacement, ids, opts).Return(result, err)
+}
+
+// SetupFindLocations configures the mock to return LSP locations
+func (m *MockClient) SetupFindLocations(kind, title string, opts types.LocationOptions, results types.LocationResults, err error) *mock.Call {
+	return m.On("FindLocations", mock.Anything, kind, title, opts).Return(results, err)
+}
+
+// SetupHover configures the mock to return LSP hover information
+func (m *MockClient) SetupHover(title string, opts types.LocationOptions, result types.HoverResult, err error) *mock.Call {
+	return m.On("Hover", mock.Anything, title, opts).Return(result, err)
+}
+
+// SetupSignatureHelp configures the mock to return LSP signature help
+func (m *MockClient) SetupSignatureHelp(title string, opts types.LocationOptions, result types.SignatureHelpResult, err error) *mock.Call {
+	return m.On("SignatureHelp", mock.Anything, title, opts).Return(result, err)
+}
+
+// SetupGetWindows configures the mock to return windows
+func (m *MockClient) SetupGetWindows(windows []types.WindowInfo, err error) *mock.Call {
+	return m.On("GetWindows", mock.Anything).Return(windows, err)
+}
+
+// SetupSplitWindow configures the mock for splitting windows
+func (m *MockClient) SetupSplitWindow(direction, bufferTitle string, window types.WindowInfo, err error) *mock.Call {
+	return m.On("SplitWindow", mock.Anything, direction, bufferTitle).Return(window, err)
+}
+
+// SetupCloseWindow configures the mock for closing a window
+func (m *MockClient) SetupCloseWindow(windowID int, err error) *mock.Call {
+	return m.On("CloseWindow", mock.Anything, windowID).Return(err)
+}
+
+// SetupResizeWindow configures the mock for resizing a window
+func (m *MockClient) SetupResizeWindow(windowID, width, height int, err error) *mock.Call {
+	return m.On("ResizeWindow", mock.Anything, windowID, width, height).Return(err)
+}
+
+// SetupExecCommand configures the mock for executing commands
+func (m *MockClient) SetupExecCommand(command, output string, err error) *mock.Call {
+	return m.On("ExecCommand", mock.Anything, command).Return(output, err)
+}
+
+// SetupExecLua configures the mock for executing Lua code
+func (m *MockClient) SetupExecLua(code string, luaArgs []any, result any, err error) *mock.Call {
+	return m.On("ExecLua", mock.Anything, code, luaArgs).Return(result, err)
+}
+
+// SetupCallFunction configures the mock for calling functions
+func (m *MockClient) SetupCallFunction(fname string, fnArgs []any, result any, err error) *mock.Call {
+	return m.On("CallFunction", mock.Anything, fname, fnArgs).Return(result, err)
+}
+
+// SetupGetDiagnostics configures the mock for getting diagnostics
+func (m *MockClient) SetupGetDiagnostics(bufferTitle string, diagnostics []types.BufferDiagnostics, err error) *mock.Call {
+	return m.On("GetDiagnostics", mock.Anything, bufferTitle).Return(diagnostics, err)
+}
+
+// SetupWatchDiagnostics configures the mock for watching diagnostics
+func (m *MockClient) SetupWatchDiagnostics(stop func(context.Context) error, err error) *mock.Call {
+	return m.On("WatchDiagnostics", mock.Anything, mock.Anything).Return(stop, err)
+}
+
+// SetupGetEditorConfig configures the mock for describing the editor
+func (m *MockClient) SetupGetEditorConfig(cfg types.EditorConfig, err error) *mock.Call {
+	return m.On("GetEditorConfig", mock.Anything).Return(cfg, err)
+}
+
+// SetupGetPlugins configures the mock for listing plugins
+func (m *MockClient) SetupGetPlugins(inventory types.PluginInventory, err error) *mock.Call {
+	return m.On("GetPlugins", mock.Anything).Return(inventory, err)
+}
+
+// SetupGetConnectionStatus configures the mock for reading the connection status
+func (m *MockClient) SetupGetConnectionStatus(status types.ConnectionStatus, err error) *mock.Call {
+	return m.On("GetConnectionStatus", mock.Anything).Return(status, err)
+}
+
+// SetupClose configures the mock for closing the client
+func (m *MockClient) SetupClose(err error) *mock.Call {
+	return m.On("Close").Return(err)
+}
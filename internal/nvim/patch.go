@@ -0,0 +1,291 @@
+package nvim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/neovim/go-client/nvim"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// luaLoadBuffer adds and loads the buffer of an absolute path, bufadd() reuses the
+// buffer whose name is exactly the path
+const luaLoadBuffer = `
+	local path = ...
+	local buf = vim.fn.bufadd(path)
+	vim.fn.bufload(buf)
+	vim.bo[buf].buflisted = true
+	return buf
+`
+
+// luaSetLines applies line edits sorted by position unless the buffer changed since
+// changedtick. The edits are applied last to first and joined into one undo step, and
+// the new changedtick or -1 when the buffer changed is returned.
+const luaSetLines = `
+	local buf, tick, edits = ...
+	if vim.api.nvim_buf_get_changedtick(buf) ~= tick then
+		return -1
+	end
+	vim.api.nvim_buf_call(buf, function()
+		for i = #edits, 1, -1 do
+			if i < #edits then
+				pcall(vim.cmd.undojoin)
+			end
+			local e = edits[i]
+			vim.api.nvim_buf_set_lines(buf, e[1], e[2], true, e[3])
+		end
+	end)
+	return vim.api.nvim_buf_get_changedtick(buf)
+`
+
+// ApplyPatch applies a unified diff to the buffers of its files, loading files that
+// are not open. Hunks are matched with fuzzy context and the applied hunks of each file
+// form one undo step. Rejected hunks are reported in the result rather than as errors.
+// expectedVersions maps file paths of the patch to the buffer version the patch was
+// made from, the patch fails with a VersionConflictError before any file is patched
+// when one of these buffers changed since.
+func (c *Client) ApplyPatch(ctx context.Context, patch string, expectedVersions map[string]int) (types.PatchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return types.PatchResult{}, fmt.Errorf("failed to apply patch: %w", err)
+	}
+
+	files, err := parsePatch(patch)
+	if err != nil {
+		return types.PatchResult{}, fmt.Errorf("failed to apply patch: %w", err)
+	}
+
+	versions, err := c.patchVersions(ctx, files, expectedVersions)
+	if err != nil {
+		return types.PatchResult{}, fmt.Errorf("failed to apply patch: %w", err)
+	}
+
+	var result types.PatchResult
+
+	for i, file := range files {
+		fileResult, ferr := c.applyFilePatch(ctx, file, versions[i])
+		if ferr != nil {
+			if ctx.Err() != nil || errors.Is(ferr, ErrNotConnected) {
+				return types.PatchResult{}, fmt.Errorf("failed to apply patch to `%s`: %w", file.path(), ferr)
+			}
+
+			fileResult = rejectFilePatch(file, ferr.Error())
+		}
+
+		for _, h := range fileResult.Hunks {
+			if h.Applied {
+				result.Applied++
+			} else {
+				result.Rejected++
+			}
+		}
+
+		result.Files = append(result.Files, fileResult)
+	}
+
+	return result, nil
+}
+
+// ----------------------------------------------------------------------------
+
+// patchVersions returns the expected version of each file of a patch, 0 for none, and
+// fails with a VersionConflictError when a buffer is no longer at its expected version
+func (c *Client) patchVersions(ctx context.Context, files []filePatch, expected map[string]int) ([]int, error) {
+	versions := make([]int, len(files))
+	if len(expected) == 0 {
+		return versions, nil
+	}
+
+	indexes := make(map[string]int, len(files))
+	for i, file := range files {
+		path, err := c.absolutePath(ctx, file.path())
+		if err != nil {
+			return nil, err
+		}
+
+		indexes[path] = i
+	}
+
+	for key, version := range expected {
+		path, err := c.absolutePath(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+
+		i, ok := indexes[path]
+		if !ok {
+			return nil, fmt.Errorf("%w: expected version given for `%s`, which the patch does not change", ErrInvalidEdit, key)
+		}
+
+		versions[i] = version
+	}
+
+	for i, file := range files {
+		if versions[i] == 0 || file.newPath == devNull {
+			continue
+		}
+
+		buf, err := c.loadPatchBuffer(ctx, file.path())
+		if err != nil {
+			return nil, fmt.Errorf("failed to check the version of `%s`: %w", file.path(), err)
+		}
+
+		var tick int
+		if err := c.batch(ctx, func(b *nvim.Batch) { b.BufferChangedTick(buf, &tick) }); err != nil {
+			return nil, fmt.Errorf("failed to check the version of `%s`: %w", file.path(), err)
+		}
+
+		if tick != versions[i] {
+			start, end := patchSpan(file)
+			return nil, c.conflictError(ctx, buf, file.path(), versions[i], start, end)
+		}
+	}
+
+	return versions, nil
+}
+
+// applyFilePatch applies the hunks of one file, retrying when the buffer changes
+// between reading it and applying the edits. A non-zero expected version rejects the
+// file when its buffer is no longer at that version.
+func (c *Client) applyFilePatch(ctx context.Context, file filePatch, expected int) (types.FilePatchResult, error) {
+	if file.newPath == devNull {
+		return rejectFilePatch(file, "deleting files is not supported"), nil
+	}
+
+	buf, err := c.loadPatchBuffer(ctx, file.path())
+	if err != nil {
+		return types.FilePatchResult{}, err
+	}
+
+	for range editAttempts {
+		lines, tick, rerr := c.readBuffer(ctx, buf)
+		if rerr != nil {
+			return types.FilePatchResult{}, rerr
+		}
+
+		if expected != 0 && tick != expected {
+			start, end := patchSpan(file)
+			return types.FilePatchResult{}, c.conflictError(ctx, buf, file.path(), expected, start, end)
+		}
+
+		// an empty buffer still has one empty line which a patch does not know about
+		empty := len(lines) == 1 && lines[0] == ""
+		if empty {
+			lines = nil
+		}
+
+		hunks, edits := hunkResults(file.hunks, matchHunks(lines, file.hunks))
+		if empty && len(edits) > 0 {
+			edits[len(edits)-1].end = 1
+		}
+
+		version, serr := c.setLines(ctx, buf, tick, edits)
+		if !errors.Is(serr, errStaleBuffer) {
+			if serr != nil {
+				return types.FilePatchResult{}, serr
+			}
+
+			return types.FilePatchResult{Path: file.path(), Buffer: buf, Version: version, Hunks: hunks}, nil
+		}
+	}
+
+	return types.FilePatchResult{}, ErrBufferChanged
+}
+
+// loadPatchBuffer returns the buffer of a patched file, adding and loading it when no
+// buffer refers to the path yet. The path is resolved exactly against the cwd, never
+// by file name, so a patch cannot edit a like-named file elsewhere.
+func (c *Client) loadPatchBuffer(ctx context.Context, path string) (nvim.Buffer, error) {
+	path, err := c.absolutePath(ctx, path)
+	if err != nil {
+		return 0, err
+	}
+
+	var buf nvim.Buffer
+
+	err = c.rpc(ctx, func(v *nvim.Nvim) error {
+		return v.ExecLua(luaLoadBuffer, &buf, path)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to load buffer: %w", err)
+	}
+
+	return buf, nil
+}
+
+// absolutePath returns path joined with the cwd of neovim unless it is absolute
+func (c *Client) absolutePath(ctx context.Context, path string) (string, error) {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path), nil
+	}
+
+	var cwd string
+	if err := c.rpc(ctx, func(v *nvim.Nvim) error { return v.Call("getcwd", &cwd) }); err != nil {
+		return "", fmt.Errorf("failed to get cwd: %w", err)
+	}
+
+	return filepath.Join(cwd, path), nil
+}
+
+// setLines applies line edits sorted by position as one undo step and returns the new
+// changedtick, failing with errStaleBuffer when the buffer changed since changedtick tick
+func (c *Client) setLines(ctx context.Context, buf nvim.Buffer, tick int, edits []lineEdit) (int, error) {
+	if len(edits) == 0 {
+		return tick, nil
+	}
+
+	luaEdits := make([]any, len(edits))
+	for i, e := range edits {
+		luaEdits[i] = []any{e.start, e.end, e.lines}
+	}
+
+	var version int
+	err := c.rpc(ctx, func(v *nvim.Nvim) error {
+		return v.ExecLua(luaSetLines, &version, buf, tick, luaEdits)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to set lines: %w", err)
+	}
+
+	if version < 0 {
+		return 0, errStaleBuffer
+	}
+
+	return version, nil
+}
+
+// patchSpan returns the lines (1-based, inclusive) spanned by the hunks of a file patch, or an
+// empty span when their headers give no old lines
+func patchSpan(file filePatch) (int, int) {
+	start, end := 0, -1
+	for _, h := range file.hunks {
+		if h.oldStart < 1 || h.oldCount == 0 {
+			continue
+		}
+
+		last := h.oldStart + max(h.oldCount, 1) - 1
+		if start == 0 || h.oldStart < start {
+			start = h.oldStart
+		}
+
+		end = max(end, last)
+	}
+
+	if start == 0 {
+		return 1, 0
+	}
+
+	return start, end
+}
+
+// rejectFilePatch reports every hunk of a file as rejected for reason
+func rejectFilePatch(file filePatch, reason string) types.FilePatchResult {
+	result := types.FilePatchResult{Path: file.path(), Error: reason, Hunks: make([]types.HunkResult, len(file.hunks))}
+	for i, h := range file.hunks {
+		result.Hunks[i] = types.HunkResult{Hunk: i + 1, Header: h.header, Reason: reason}
+	}
+
+	return result
+}
@@ -0,0 +1,156 @@
+package nvim
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ApplyPatch(t *testing.T) {
+	client, cleanup := setupTestNeovim(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	t.Run("patches open and unopened files", func(t *testing.T) {
+		open := createTempFile(t, "one\ntwo\nthree")
+		_, err := client.OpenBuffer(ctx, open)
+		require.NoError(t, err)
+
+		closed := createTempFile(t, "alpha\nbeta")
+		created := filepath.Join(t.TempDir(), "new.txt")
+
+		patch := "--- " + open + "\n+++ " + open + "\n@@ -1,3 +1,3 @@\n one\n-two\n+TWO\n three\n" +
+			"@@ -8,1 +8,1 @@\n-missing\n+gone\n" +
+			"--- " + closed + "\n+++ " + closed + "\n@@ -1,2 +1,3 @@\n alpha\n+between\n beta\n" +
+			"--- /dev/null\n+++ " + created + "\n@@ -0,0 +1,2 @@\n+hello\n+world\n"
+
+		result, err := client.ApplyPatch(ctx, patch, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, result.Applied)
+		assert.Equal(t, 1, result.Rejected)
+		require.Len(t, result.Files, 3)
+		assert.True(t, result.Files[0].Hunks[0].Applied)
+		assert.NotEmpty(t, result.Files[0].Hunks[1].Reason)
+
+		lines, _, err := client.GetBufferLines(ctx, filepath.Base(open), 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"one", "TWO", "three"}, lines)
+
+		lines, _, err = client.GetBufferLines(ctx, filepath.Base(closed), 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"alpha", "between", "beta"}, lines)
+
+		lines, _, err = client.GetBufferLines(ctx, created, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"hello", "world"}, lines)
+
+		// buffers are patched, not written
+		content, err := os.ReadFile(closed)
+		require.NoError(t, err)
+		assert.Equal(t, "alpha\nbeta", string(content))
+	})
+
+	t.Run("resolves paths exactly against the cwd", func(t *testing.T) {
+		dir := resolvePath(t, t.TempDir())
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "cmd"), 0o755))
+
+		nested := filepath.Join(dir, "cmd", "main.go")
+		require.NoError(t, os.WriteFile(nested, []byte("package main\n"), 0o644))
+
+		_, err := client.OpenBuffer(ctx, nested)
+		require.NoError(t, err)
+
+		_, err = client.ExecCommand(ctx, "cd "+dir)
+		require.NoError(t, err)
+
+		patch := "--- /dev/null\n+++ b/main.go\n@@ -0,0 +1,1 @@\n+package root\n"
+
+		result, err := client.ApplyPatch(ctx, patch, nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Applied)
+
+		lines, _, err := client.GetBufferLines(ctx, nested, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"package main"}, lines)
+
+		lines, _, err = client.GetBufferLines(ctx, filepath.Join(dir, "main.go"), 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"package root"}, lines)
+	})
+
+	t.Run("checks expected versions before patching", func(t *testing.T) {
+		first := createTempFile(t, "a\nb\nc")
+		second := createTempFile(t, "x\ny\nz")
+
+		for _, path := range []string{first, second} {
+			_, err := client.OpenBuffer(ctx, path)
+			require.NoError(t, err)
+		}
+
+		_, version, err := client.GetBufferLines(ctx, second, 1, -1)
+		require.NoError(t, err)
+
+		_, err = client.SetBufferLines(ctx, second, 2, 2, []string{"typed"}, 0)
+		require.NoError(t, err)
+
+		patch := "--- " + first + "\n+++ " + first + "\n@@ -1,1 +1,1 @@\n-a\n+A\n" +
+			"--- " + second + "\n+++ " + second + "\n@@ -2,1 +2,1 @@\n-y\n+Y\n"
+
+		_, err = client.ApplyPatch(ctx, patch, map[string]int{second: version})
+
+		var conflict *VersionConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, 2, conflict.StartLine)
+		assert.Equal(t, []string{"typed"}, conflict.Lines)
+
+		lines, _, err := client.GetBufferLines(ctx, first, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c"}, lines)
+
+		_, err = client.ApplyPatch(ctx, patch, map[string]int{"other.go": version})
+		assert.ErrorIs(t, err, ErrInvalidEdit)
+	})
+
+	t.Run("each file is one undo step", func(t *testing.T) {
+		path := createTempFile(t, "a\nb\nc\nd\ne\nf\ng\nh")
+		_, err := client.OpenBuffer(ctx, path)
+		require.NoError(t, err)
+
+		patch := "--- a/" + path + "\n+++ b/" + path + "\n" +
+			"@@ -1,2 +1,2 @@\n-a\n+A\n b\n" +
+			"@@ -7,2 +7,2 @@\n g\n-h\n+H\n"
+
+		result, err := client.ApplyPatch(ctx, patch, nil)
+		require.NoError(t, err)
+		require.Equal(t, 2, result.Applied)
+
+		_, err = client.ExecCommand(ctx, "undo")
+		require.NoError(t, err)
+
+		lines, _, err := client.GetBufferLines(ctx, filepath.Base(path), 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c", "d", "e", "f", "g", "h"}, lines)
+	})
+}
+
+func TestPatchSpan(t *testing.T) {
+	t.Run("spans the old lines of every hunk", func(t *testing.T) {
+		start, end := patchSpan(filePatch{hunks: []hunk{{oldStart: 8, oldCount: 3}, {oldStart: 2, oldCount: 1}}})
+
+		assert.Equal(t, 2, start)
+		assert.Equal(t, 10, end)
+	})
+
+	t.Run("is empty for created files", func(t *testing.T) {
+		start, end := patchSpan(filePatch{oldPath: devNull, hunks: []hunk{{oldStart: 0, oldCount: 0}}})
+
+		assert.Equal(t, 1, start)
+		assert.Equal(t, 0, end)
+	})
+}
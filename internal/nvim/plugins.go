@@ -0,0 +1,268 @@
+package nvim
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/neovim/go-client/nvim"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// luaGetPlugins enumerates plugins from runtimepath and packpath, then enriches
+// them with data from lazy.nvim, packer and vim.pack when present
+const luaGetPlugins = `
+	local normalize = vim.fs.normalize
+
+	local function git_head(dir)
+		local git = dir .. '/.git'
+		local f = io.open(git .. '/HEAD', 'r')
+		if not f then
+			return ''
+		end
+		local head = vim.trim(f:read('*a') or '')
+		f:close()
+		local ref = head:match('^ref:%s*(.+)$')
+		if not ref then
+			return head
+		end
+		local rf = io.open(git .. '/' .. ref, 'r')
+		if rf then
+			local commit = vim.trim(rf:read('*a') or '')
+			rf:close()
+			return commit
+		end
+		local pf = io.open(git .. '/packed-refs', 'r')
+		if not pf then
+			return ''
+		end
+		for line in pf:lines() do
+			local commit, name = line:match('^(%x+)%s+(.+)$')
+			if name == ref then
+				pf:close()
+				return commit
+			end
+		end
+		pf:close()
+		return ''
+	end
+
+	local function collect(out, kind, value)
+		if type(value) == 'string' then
+			table.insert(out, kind .. ':' .. value)
+		elseif type(value) == 'table' then
+			for _, item in ipairs(value) do
+				if type(item) == 'table' then
+					item = item[1] or item.event
+				end
+				if type(item) == 'string' then
+					table.insert(out, kind .. ':' .. item)
+				end
+			end
+		end
+	end
+
+	local function stringify(value)
+		if type(value) == 'string' then
+			return value
+		elseif value == nil or value == false then
+			return ''
+		end
+		local ok, str = pcall(tostring, value)
+		return ok and str or ''
+	end
+
+	local core = {}
+	local function mark_core(path)
+		if path and path ~= '' then
+			core[normalize(path)] = true
+		end
+	end
+	mark_core(vim.fn.stdpath('config'))
+	mark_core(vim.fn.stdpath('data') .. '/site')
+	for _, dir in ipairs(vim.fn.stdpath('config_dirs')) do
+		mark_core(dir)
+	end
+	for _, dir in ipairs(vim.fn.stdpath('data_dirs')) do
+		mark_core(dir .. '/site')
+	end
+	mark_core(vim.env.VIMRUNTIME)
+	mark_core(vim.fn.fnamemodify(vim.v.progpath, ':p:h:h') .. '/lib/nvim')
+
+	local rtp = {}
+	for _, dir in ipairs(vim.opt.runtimepath:get()) do
+		rtp[normalize(dir)] = true
+	end
+
+	local plugins, order = {}, {}
+	local function add(path, manager)
+		path = normalize(path)
+		local p = plugins[path]
+		if not p then
+			p = {
+				name = vim.fs.basename(path),
+				path = path,
+				manager = manager,
+				loaded = rtp[path] == true,
+				lazy = false,
+				triggers = {},
+				version = '',
+				commit = '',
+				url = '',
+			}
+			plugins[path] = p
+			table.insert(order, path)
+		else
+			p.manager = manager
+		end
+		return p
+	end
+
+	for _, dir in ipairs(vim.opt.runtimepath:get()) do
+		local path = normalize(dir)
+		if not core[path] and not path:match('/after$') and vim.fn.isdirectory(path) == 1 then
+			add(path, 'runtimepath')
+		end
+	end
+	for _, dir in ipairs(vim.fn.globpath(vim.o.packpath, 'pack/*/start/*', false, true)) do
+		add(dir, 'pack')
+	end
+	for _, dir in ipairs(vim.fn.globpath(vim.o.packpath, 'pack/*/opt/*', false, true)) do
+		add(dir, 'pack').lazy = true
+	end
+
+	local managers = {}
+
+	if package.loaded['lazy'] then
+		table.insert(managers, 'lazy.nvim')
+		for _, plugin in ipairs(require('lazy').plugins()) do
+			if plugin.dir then
+				local p = add(plugin.dir, 'lazy.nvim')
+				p.name = plugin.name or p.name
+				p.loaded = plugin._ ~= nil and plugin._.loaded ~= nil
+				p.url = plugin.url or ''
+				p.triggers = {}
+				collect(p.triggers, 'event', plugin.event)
+				collect(p.triggers, 'cmd', plugin.cmd)
+				collect(p.triggers, 'ft', plugin.ft)
+				collect(p.triggers, 'keys', plugin.keys)
+				p.lazy = plugin.lazy == true or (plugin.lazy ~= false and #p.triggers > 0)
+				p.version = stringify(plugin.version or plugin.tag or plugin.branch)
+				local ok, info = pcall(function()
+					return require('lazy.manage.git').info(plugin.dir)
+				end)
+				p.commit = ok and info and info.commit or ''
+			end
+		end
+	end
+
+	if type(_G.packer_plugins) == 'table' then
+		table.insert(managers, 'packer')
+		for name, plugin in pairs(_G.packer_plugins) do
+			if type(plugin) == 'table' and plugin.path then
+				local p = add(plugin.path, 'packer')
+				p.name = name
+				p.loaded = plugin.loaded == true
+				p.url = plugin.url or ''
+				p.lazy = p.path:find('/opt/', 1, true) ~= nil
+				collect(p.triggers, 'event', plugin.event)
+				collect(p.triggers, 'cmd', plugin.commands)
+				collect(p.triggers, 'ft', plugin.ft)
+				collect(p.triggers, 'keys', plugin.keys)
+			end
+		end
+	end
+
+	if vim.pack and type(vim.pack.get) == 'function' then
+		local ok, packs = pcall(vim.pack.get)
+		if ok and type(packs) == 'table' then
+			table.insert(managers, 'vim.pack')
+			for _, plugin in ipairs(packs) do
+				if plugin.path then
+					local spec = plugin.spec or {}
+					local p = add(plugin.path, 'vim.pack')
+					p.name = spec.name or p.name
+					p.loaded = plugin.active == true
+					p.url = spec.src or ''
+					p.version = stringify(spec.version)
+					p.commit = stringify(plugin.rev)
+				end
+			end
+		end
+	end
+
+	local results = {}
+	for _, path in ipairs(order) do
+		local p = plugins[path]
+		if p.commit == '' then
+			p.commit = git_head(p.path)
+		end
+		table.insert(results, p)
+	end
+
+	return { managers = managers, plugins = results }
+`
+
+// luaPlugin mirrors a plugin entry returned by luaGetPlugins
+type luaPlugin struct {
+	Name     string   `msgpack:"name"`
+	Path     string   `msgpack:"path"`
+	Manager  string   `msgpack:"manager"`
+	Loaded   bool     `msgpack:"loaded"`
+	Lazy     bool     `msgpack:"lazy"`
+	Triggers []string `msgpack:"triggers"`
+	Version  string   `msgpack:"version"`
+	Commit   string   `msgpack:"commit"`
+	URL      string   `msgpack:"url"`
+}
+
+// luaPluginInventory mirrors the table returned by luaGetPlugins
+type luaPluginInventory struct {
+	Managers []string    `msgpack:"managers"`
+	Plugins  []luaPlugin `msgpack:"plugins"`
+}
+
+// GetPlugins returns the detected plugin managers and installed plugins
+func (c *Client) GetPlugins(ctx context.Context) (types.PluginInventory, error) {
+	if err := ctx.Err(); err != nil {
+		return types.PluginInventory{}, fmt.Errorf("failed to get plugins: %w", err)
+	}
+
+	var inventory luaPluginInventory
+	err := c.rpc(ctx, func(v *nvim.Nvim) error {
+		return v.ExecLua(luaGetPlugins, &inventory)
+	})
+	if err != nil {
+		return types.PluginInventory{}, fmt.Errorf("failed to get plugins: %w", err)
+	}
+
+	plugins := make([]types.PluginInfo, 0, len(inventory.Plugins))
+	for _, p := range inventory.Plugins {
+		triggers := p.Triggers
+		if triggers == nil {
+			triggers = []string{}
+		}
+
+		plugins = append(plugins, types.PluginInfo{
+			Name:     p.Name,
+			Path:     p.Path,
+			Manager:  p.Manager,
+			Loaded:   p.Loaded,
+			Lazy:     p.Lazy,
+			Triggers: triggers,
+			Version:  p.Version,
+			Commit:   p.Commit,
+			URL:      p.URL,
+		})
+	}
+
+	managers := inventory.Managers
+	if managers == nil {
+		managers = []string{}
+	}
+
+	return types.PluginInventory{
+		Managers: managers,
+		Plugins:  plugins,
+	}, nil
+}
@@ -0,0 +1,107 @@
+package nvim
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// createTestPackpath creates a packpath with a started and an optional plugin
+func createTestPackpath(t *testing.T) string {
+	t.Helper()
+
+	root := resolvePath(t, t.TempDir())
+	alpha := filepath.Join(root, "pack", "test", "start", "alpha")
+	beta := filepath.Join(root, "pack", "test", "opt", "beta")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(alpha, ".git", "refs", "heads"), 0o755))
+	require.NoError(t, os.MkdirAll(beta, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(alpha, ".git", "HEAD"), []byte("ref: refs/heads/main\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(alpha, ".git", "refs", "heads", "main"), []byte("0123abcd\n"), 0o600))
+
+	return root
+}
+
+// findPlugin returns the plugin with the given name
+func findPlugin(t *testing.T, inventory types.PluginInventory, name string) types.PluginInfo {
+	t.Helper()
+
+	for _, p := range inventory.Plugins {
+		if p.Name == name {
+			return p
+		}
+	}
+
+	t.Fatalf("plugin %s not found in %+v", name, inventory.Plugins)
+
+	return types.PluginInfo{}
+}
+
+// --- Plugin Operations Tests ---
+
+func TestClient_GetPlugins(t *testing.T) {
+	client, cleanup := setupTestNeovim(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	root := createTestPackpath(t)
+
+	_, err := client.ExecLua(ctx, `
+		local root = ...
+		vim.o.packpath = root
+		vim.opt.runtimepath:append(root .. '/pack/test/start/alpha')
+	`, []any{root})
+	require.NoError(t, err)
+
+	t.Run("lists runtimepath and packpath plugins", func(t *testing.T) {
+		inventory, err := client.GetPlugins(ctx)
+
+		require.NoError(t, err)
+		assert.NotContains(t, inventory.Managers, "lazy.nvim")
+		assert.NotContains(t, inventory.Managers, "packer")
+
+		alpha := findPlugin(t, inventory, "alpha")
+		assert.Equal(t, "pack", alpha.Manager)
+		assert.True(t, alpha.Loaded)
+		assert.False(t, alpha.Lazy)
+		assert.Equal(t, "0123abcd", alpha.Commit)
+
+		beta := findPlugin(t, inventory, "beta")
+		assert.Equal(t, filepath.Join(root, "pack", "test", "opt", "beta"), beta.Path)
+		assert.False(t, beta.Loaded)
+		assert.True(t, beta.Lazy)
+		assert.Empty(t, beta.Commit)
+	})
+
+	t.Run("enriches plugins managed by packer", func(t *testing.T) {
+		_, err := client.ExecLua(ctx, `
+			local root = ...
+			_G.packer_plugins = {
+				beta = {
+					loaded = false,
+					path = root .. '/pack/test/opt/beta/',
+					url = 'https://github.com/example/beta',
+					commands = { 'Beta' },
+				},
+			}
+		`, []any{root})
+		require.NoError(t, err)
+
+		inventory, err := client.GetPlugins(ctx)
+
+		require.NoError(t, err)
+		assert.Contains(t, inventory.Managers, "packer")
+
+		beta := findPlugin(t, inventory, "beta")
+		assert.Equal(t, "packer", beta.Manager)
+		assert.Equal(t, "https://github.com/example/beta", beta.URL)
+		assert.Equal(t, []string{"cmd:Beta"}, beta.Triggers)
+		assert.True(t, beta.Lazy)
+	})
+}
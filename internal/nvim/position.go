@@ -0,0 +1,163 @@
+package nvim
+
+import (
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+const (
+	// PositionEncodingByte counts columns in bytes, like neovim and LSP utf-8
+	PositionEncodingByte = "byte"
+	// PositionEncodingCodepoint counts columns in unicode codepoints, like LSP utf-32
+	PositionEncodingCodepoint = "codepoint"
+	// PositionEncodingUTF16 counts columns in UTF-16 code units, the LSP default
+	PositionEncodingUTF16 = "utf-16"
+)
+
+// byteRange is a range of buffer text as 0-based rows and byte columns, end exclusive
+type byteRange struct {
+	startRow, startCol int
+	endRow, endCol     int
+}
+
+// ValidateEncoding returns ErrInvalidEncoding for unknown position encodings, an empty
+// encoding means PositionEncodingByte
+func ValidateEncoding(encoding string) error {
+	switch encoding {
+	case "", PositionEncodingByte, PositionEncodingCodepoint, PositionEncodingUTF16:
+		return nil
+	default:
+		return fmt.Errorf("%w `%s`, expected %s, %s or %s", ErrInvalidEncoding, encoding,
+			PositionEncodingByte, PositionEncodingCodepoint, PositionEncodingUTF16)
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// toByteRange converts rng to byte columns, lines holds the buffer lines from
+// rng.StartLine to rng.EndLine and lineCount is the number of lines of the buffer
+func toByteRange(rng types.TextRange, lines []string, lineCount int, encoding string) (byteRange, error) {
+	if rng.StartLine < 1 || rng.EndLine < rng.StartLine || rng.EndLine > lineCount {
+		return byteRange{}, fmt.Errorf("%w: lines %d to %d of a buffer with %d lines",
+			ErrInvalidRange, rng.StartLine, rng.EndLine, lineCount)
+	}
+
+	if len(lines) != rng.EndLine-rng.StartLine+1 {
+		return byteRange{}, fmt.Errorf("%w: lines %d to %d changed while reading them",
+			ErrInvalidRange, rng.StartLine, rng.EndLine)
+	}
+
+	startCol, err := byteColumn(lines[0], rng.StartColumn, encoding)
+	if err != nil {
+		return byteRange{}, fmt.Errorf("%w: start of line %d: %w", ErrInvalidRange, rng.StartLine, err)
+	}
+
+	endCol, err := byteColumn(lines[len(lines)-1], rng.EndColumn, encoding)
+	if err != nil {
+		return byteRange{}, fmt.Errorf("%w: end of line %d: %w", ErrInvalidRange, rng.EndLine, err)
+	}
+
+	if rng.StartLine == rng.EndLine && endCol < startCol {
+		return byteRange{}, fmt.Errorf("%w: column %d ends before column %d",
+			ErrInvalidRange, rng.EndColumn, rng.StartColumn)
+	}
+
+	return byteRange{
+		startRow: rng.StartLine - 1,
+		startCol: startCol,
+		endRow:   rng.EndLine - 1,
+		endCol:   endCol,
+	}, nil
+}
+
+// byteColumn converts a 1-based column of line in encoding to a 0-based byte column,
+// the column after the last character is valid
+func byteColumn(line string, column int, encoding string) (int, error) {
+	if column < 1 {
+		return 0, fmt.Errorf("column %d is not 1-based", column)
+	}
+
+	units := column - 1
+	if encoding == "" || encoding == PositionEncodingByte {
+		if units > len(line) {
+			return 0, fmt.Errorf("column %d is past the end of the line (%d bytes)", column, len(line))
+		}
+
+		return units, nil
+	}
+
+	offset, counted := 0, 0
+	for counted < units {
+		if offset >= len(line) {
+			return 0, fmt.Errorf("column %d is past the end of the line (%d %s units)",
+				column, textUnits(line, encoding), encoding)
+		}
+
+		r, size := utf8.DecodeRuneInString(line[offset:])
+		offset += size
+		counted += runeUnits(r, encoding)
+	}
+
+	if counted != units {
+		return 0, fmt.Errorf("column %d splits a character", column)
+	}
+
+	return offset, nil
+}
+
+// textUnits returns the length of text in encoding units
+func textUnits(text, encoding string) int {
+	switch encoding {
+	case PositionEncodingCodepoint:
+		return utf8.RuneCountInString(text)
+	case PositionEncodingUTF16:
+		units := 0
+		for _, r := range text {
+			units += runeUnits(r, encoding)
+		}
+
+		return units
+	default:
+		return len(text)
+	}
+}
+
+// runeUnits returns the number of encoding units of r, invalid bytes count as one
+func runeUnits(r rune, encoding string) int {
+	if encoding != PositionEncodingUTF16 {
+		return 1
+	}
+
+	if n := utf16.RuneLen(r); n > 0 {
+		return n
+	}
+
+	return 1
+}
+
+// insertedRange returns the range covered by text once inserted at the 1-based line
+// and encoding column of start
+func insertedRange(startLine, startColumn int, text, encoding string) types.TextRange {
+	rng := types.TextRange{StartLine: startLine, StartColumn: startColumn, EndLine: startLine}
+
+	lines := 0
+	last := text
+	for i := range len(text) {
+		if text[i] == '\n' {
+			lines++
+			last = text[i+1:]
+		}
+	}
+
+	rng.EndLine += lines
+	if lines == 0 {
+		rng.EndColumn = startColumn + textUnits(text, encoding)
+	} else {
+		rng.EndColumn = textUnits(last, encoding) + 1
+	}
+
+	return rng
+}
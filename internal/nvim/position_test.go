@@ -0,0 +1,105 @@
+package nvim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestByteColumn(t *testing.T) {
+	// é is 2 bytes and 1 UTF-16 unit, 😀 is 4 bytes and 2 UTF-16 units
+	line := "aé😀b"
+
+	tests := []struct {
+		name     string
+		column   int
+		encoding string
+		want     int
+		wantErr  bool
+	}{
+		{name: "byte", column: 4, encoding: PositionEncodingByte, want: 3},
+		{name: "empty encoding is byte", column: 2, encoding: "", want: 1},
+		{name: "byte end of line", column: 9, encoding: PositionEncodingByte, want: 8},
+		{name: "byte past end", column: 10, encoding: PositionEncodingByte, wantErr: true},
+		{name: "codepoint", column: 3, encoding: PositionEncodingCodepoint, want: 3},
+		{name: "codepoint after emoji", column: 4, encoding: PositionEncodingCodepoint, want: 7},
+		{name: "codepoint end of line", column: 5, encoding: PositionEncodingCodepoint, want: 8},
+		{name: "codepoint past end", column: 6, encoding: PositionEncodingCodepoint, wantErr: true},
+		{name: "utf-16", column: 3, encoding: PositionEncodingUTF16, want: 3},
+		{name: "utf-16 after surrogate pair", column: 5, encoding: PositionEncodingUTF16, want: 7},
+		{name: "utf-16 inside surrogate pair", column: 4, encoding: PositionEncodingUTF16, wantErr: true},
+		{name: "not 1-based", column: 0, encoding: PositionEncodingByte, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := byteColumn(line, tt.column, tt.encoding)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestToByteRange(t *testing.T) {
+	lines := []string{"héllo", "wörld"}
+
+	t.Run("converts both ends", func(t *testing.T) {
+		rng := types.TextRange{StartLine: 2, StartColumn: 3, EndLine: 3, EndColumn: 4}
+
+		got, err := toByteRange(rng, lines, 3, PositionEncodingCodepoint)
+		require.NoError(t, err)
+
+		assert.Equal(t, byteRange{startRow: 1, startCol: 3, endRow: 2, endCol: 4}, got)
+	})
+
+	t.Run("rejects lines past the end of the buffer", func(t *testing.T) {
+		rng := types.TextRange{StartLine: 2, StartColumn: 1, EndLine: 4, EndColumn: 1}
+
+		_, err := toByteRange(rng, lines, 3, PositionEncodingByte)
+		assert.ErrorIs(t, err, ErrInvalidRange)
+	})
+
+	t.Run("rejects reversed columns", func(t *testing.T) {
+		rng := types.TextRange{StartLine: 1, StartColumn: 3, EndLine: 1, EndColumn: 2}
+
+		_, err := toByteRange(rng, lines[:1], 3, PositionEncodingByte)
+		assert.ErrorIs(t, err, ErrInvalidRange)
+	})
+}
+
+func TestInsertedRange(t *testing.T) {
+	t.Run("single line", func(t *testing.T) {
+		got := insertedRange(3, 5, "日本", PositionEncodingUTF16)
+
+		assert.Equal(t, types.TextRange{StartLine: 3, StartColumn: 5, EndLine: 3, EndColumn: 7}, got)
+	})
+
+	t.Run("several lines", func(t *testing.T) {
+		got := insertedRange(3, 5, "a\nbc\ndéf", PositionEncodingByte)
+
+		assert.Equal(t, types.TextRange{StartLine: 3, StartColumn: 5, EndLine: 5, EndColumn: 5}, got)
+	})
+
+	t.Run("deletion", func(t *testing.T) {
+		got := insertedRange(3, 5, "", PositionEncodingCodepoint)
+
+		assert.Equal(t, types.TextRange{StartLine: 3, StartColumn: 5, EndLine: 3, EndColumn: 5}, got)
+	})
+}
+
+func TestValidateEncoding(t *testing.T) {
+	for _, encoding := range []string{"", PositionEncodingByte, PositionEncodingCodepoint, PositionEncodingUTF16} {
+		assert.NoError(t, ValidateEncoding(encoding))
+	}
+
+	assert.ErrorIs(t, ValidateEncoding("utf-32"), ErrInvalidEncoding)
+}
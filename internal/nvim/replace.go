@@ -0,0 +1,347 @@
+package nvim
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/neovim/go-client/nvim"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// luaLoadFile returns the buffer of a file, loading it without showing it in a window
+// when it is not loaded yet, or -1 when there is neither such a buffer nor file
+const luaLoadFile = `
+	local path = ...
+	if vim.fn.bufexists(path) == 0 and vim.fn.filereadable(path) == 0 then
+		return -1
+	end
+	local buf = vim.fn.bufadd(path)
+	vim.fn.bufload(buf)
+	vim.bo[buf].buflisted = true
+	return buf
+`
+
+// luaReplaceMatches applies text edits sorted by position unless the buffer changed
+// since changedtick, joining them into one undo step. It returns the new changedtick
+// and undo sequence number, with a tick of -1 when the buffer changed.
+const luaReplaceMatches = `
+	local buf, tick, edits = ...
+	if vim.api.nvim_buf_get_changedtick(buf) ~= tick then
+		return { tick = -1, seq = 0 }
+	end
+	local seq = 0
+	vim.api.nvim_buf_call(buf, function()
+		for i = #edits, 1, -1 do
+			if i < #edits then
+				pcall(vim.cmd.undojoin)
+			end
+			local e = edits[i]
+			vim.api.nvim_buf_set_text(buf, e[1], e[2], e[3], e[4], e[5])
+		end
+		seq = vim.fn.undotree().seq_cur
+	end)
+	return { tick = vim.api.nvim_buf_get_changedtick(buf), seq = seq }
+`
+
+// replaceOutcome is the result of luaReplaceMatches
+type replaceOutcome struct {
+	Tick int `msgpack:"tick"`
+	Seq  int `msgpack:"seq"`
+}
+
+// replaceSelection is the accepted matches of one file, nths are the sorted 1-based
+// positions of the matches in the file
+type replaceSelection struct {
+	file   string
+	digest string
+	ids    []string
+	nths   []int
+}
+
+// PreviewFindReplace searches the files under a root directory like GrepProject and
+// proposes the replacement of each match. RE2 replacements refer to capture groups as
+// $1 or ${name}, literal ones are used as is. Each match has an ID to accept with
+// ApplyFindReplace, which is valid as long as the content of its file is unchanged.
+func (c *Client) PreviewFindReplace(ctx context.Context, pattern, replacement string, opts types.GrepOptions) (types.FindReplacePreview, error) {
+	if err := ctx.Err(); err != nil {
+		return types.FindReplacePreview{}, fmt.Errorf("failed to preview replacements: %w", err)
+	}
+
+	re, err := grepRegexp(pattern, opts)
+	if err != nil {
+		return types.FindReplacePreview{}, fmt.Errorf("failed to preview replacements: %w", err)
+	}
+
+	glob, err := compileGlob(opts.Glob)
+	if err != nil {
+		return types.FindReplacePreview{}, fmt.Errorf("failed to preview replacements: %w", err)
+	}
+
+	root, overlay, err := c.grepSources(ctx, opts.Root)
+	if err != nil {
+		return types.FindReplacePreview{}, fmt.Errorf("failed to preview replacements: %w", err)
+	}
+
+	run, err := newGrepRun(ctx, root, re, glob, overlay, opts)
+	if err != nil {
+		return types.FindReplacePreview{}, fmt.Errorf("failed to preview replacements in `%s`: %w", root, err)
+	}
+
+	run.expand = replacementExpander(re, replacement, opts.Flavor)
+	if err := run.walk("", loadRepoIgnores(root)); err != nil {
+		return types.FindReplacePreview{}, fmt.Errorf("failed to preview replacements in `%s`: %w", root, err)
+	}
+
+	hits, truncated, next := run.page()
+
+	preview := types.FindReplacePreview{Root: root, Matches: make([]types.ReplaceMatch, 0, len(hits)), Truncated: truncated, NextOffset: next}
+	for _, hit := range hits {
+		preview.Matches = append(preview.Matches, types.ReplaceMatch{
+			ID:          matchID(hit.File, hit.digest, hit.nth),
+			GrepMatch:   hit.GrepMatch,
+			Replacement: hit.replacement,
+		})
+	}
+
+	return preview, nil
+}
+
+// ApplyFindReplace replaces the accepted matches of a preview made with the same
+// pattern, replacement, root and flavor. Files that are not open are loaded into
+// buffers, the replacements of each file form one undo step and are left unsaved.
+// Files whose content changed since the preview are skipped.
+func (c *Client) ApplyFindReplace(ctx context.Context, pattern, replacement string, ids []string, opts types.GrepOptions) (types.FindReplaceResult, error) {
+	if err := ctx.Err(); err != nil {
+		return types.FindReplaceResult{}, fmt.Errorf("failed to apply replacements: %w", err)
+	}
+
+	re, err := grepRegexp(pattern, opts)
+	if err != nil {
+		return types.FindReplaceResult{}, fmt.Errorf("failed to apply replacements: %w", err)
+	}
+
+	selections, err := selectMatches(ids)
+	if err != nil {
+		return types.FindReplaceResult{}, fmt.Errorf("failed to apply replacements: %w", err)
+	}
+
+	root, err := c.absolutePath(ctx, opts.Root)
+	if err != nil {
+		return types.FindReplaceResult{}, fmt.Errorf("failed to apply replacements: %w", err)
+	}
+
+	expand := replacementExpander(re, replacement, opts.Flavor)
+	result := types.FindReplaceResult{Files: []types.ReplacedFile{}}
+
+	for _, sel := range selections {
+		file, reason, rerr := c.replaceInFile(ctx, root, sel, re, expand)
+		if rerr != nil {
+			return types.FindReplaceResult{}, fmt.Errorf("failed to apply replacements in `%s`: %w", sel.file, rerr)
+		}
+
+		if reason != "" {
+			result.Skipped = append(result.Skipped, types.SkippedFile{File: filepath.FromSlash(sel.file), IDs: sel.ids, Reason: reason})
+			continue
+		}
+
+		result.Replaced += file.Replaced
+		result.Files = append(result.Files, file)
+	}
+
+	return result, nil
+}
+
+// ----------------------------------------------------------------------------
+
+// replaceInFile replaces the selected matches of a file in its buffer, it returns why
+// the file was skipped instead when its content or matches differ from the preview
+func (c *Client) replaceInFile(ctx context.Context, root string, sel replaceSelection, re *regexp.Regexp, expand func(string, []int) string) (types.ReplacedFile, string, error) {
+	var handle int
+
+	path := filepath.Join(root, filepath.FromSlash(sel.file))
+	err := c.rpc(ctx, func(v *nvim.Nvim) error {
+		return v.ExecLua(luaLoadFile, &handle, path)
+	})
+	if err != nil {
+		return types.ReplacedFile{}, "", fmt.Errorf("failed to load file: %w", err)
+	}
+
+	if handle < 0 {
+		return types.ReplacedFile{}, "file not found", nil
+	}
+
+	buf := nvim.Buffer(handle)
+
+	for range editAttempts {
+		lines, tick, rerr := c.readBuffer(ctx, buf)
+		if rerr != nil {
+			return types.ReplacedFile{}, "", rerr
+		}
+
+		content := strings.Join(lines, "\n")
+		if contentDigest(content) != sel.digest {
+			return types.ReplacedFile{}, "content changed since the preview", nil
+		}
+
+		matches := regexMatches(lines, re, -1)
+
+		edits := make([]textEdit, 0, len(sel.nths))
+		for _, nth := range sel.nths {
+			if nth > len(matches) {
+				return types.ReplacedFile{}, fmt.Sprintf("match %d not found, the pattern differs from the preview", nth), nil
+			}
+
+			m := matches[nth-1]
+			edits = append(edits, textEdit{byteRange: m.byteRange, lines: strings.Split(expand(content, m.index), "\n")})
+		}
+
+		outcome, rerr := c.replaceMatches(ctx, buf, tick, edits)
+		if errors.Is(rerr, errStaleBuffer) {
+			continue
+		}
+
+		if rerr != nil {
+			return types.ReplacedFile{}, "", rerr
+		}
+
+		return types.ReplacedFile{
+			File:     filepath.FromSlash(sel.file),
+			Buffer:   buf,
+			Replaced: len(edits),
+			Version:  outcome.Tick,
+			UndoSeq:  outcome.Seq,
+		}, "", nil
+	}
+
+	return types.ReplacedFile{}, "buffer kept changing during the replacement", nil
+}
+
+// replaceMatches applies edits sorted by position as one undo step, failing with
+// errStaleBuffer when the buffer changed since changedtick tick
+func (c *Client) replaceMatches(ctx context.Context, buf nvim.Buffer, tick int, edits []textEdit) (replaceOutcome, error) {
+	luaEdits := make([]any, len(edits))
+	for i, e := range edits {
+		luaEdits[i] = []any{e.startRow, e.startCol, e.endRow, e.endCol, e.lines}
+	}
+
+	var outcome replaceOutcome
+	err := c.rpc(ctx, func(v *nvim.Nvim) error {
+		return v.ExecLua(luaReplaceMatches, &outcome, buf, tick, luaEdits)
+	})
+	if err != nil {
+		return replaceOutcome{}, fmt.Errorf("failed to replace matches: %w", err)
+	}
+
+	if outcome.Tick < 0 {
+		return replaceOutcome{}, errStaleBuffer
+	}
+
+	return outcome, nil
+}
+
+// replacementExpander returns the function expanding the replacement of the match at
+// index in content, RE2 replacements refer to capture groups as $1 or ${name} while
+// literal ones are used as is
+func replacementExpander(re *regexp.Regexp, replacement, flavor string) func(string, []int) string {
+	if flavor == types.SearchFlavorLiteral {
+		return func(string, []int) string { return replacement }
+	}
+
+	return func(content string, index []int) string {
+		return string(re.ExpandString(nil, replacement, content, index))
+	}
+}
+
+// contentDigest identifies the content of a file in match IDs. Line ends are normalized
+// first: the preview reads files from disk with \r\n turned into \n, while neovim keeps
+// the \r at the end of the lines of a file with mixed line ends.
+func contentDigest(content string) string {
+	content = strings.TrimSuffix(strings.ReplaceAll(content, "\r\n", "\n"), "\r")
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:6])
+}
+
+// matchID identifies the nth match of a file whose content has the given digest
+func matchID(file, digest string, nth int) string {
+	return filepath.ToSlash(file) + ":" + digest + ":" + strconv.Itoa(nth)
+}
+
+// parseMatchID splits a match ID into the file, content digest and match position
+func parseMatchID(id string) (string, string, int, bool) {
+	rest, n, ok := cutLast(id, ":")
+	if !ok {
+		return "", "", 0, false
+	}
+
+	file, digest, ok := cutLast(rest, ":")
+	if !ok || file == "" || !filepath.IsLocal(filepath.FromSlash(file)) {
+		return "", "", 0, false
+	}
+
+	nth, err := strconv.Atoi(n)
+	if err != nil || nth < 1 {
+		return "", "", 0, false
+	}
+
+	return file, digest, nth, true
+}
+
+// cutLast slices s around the last instance of sep
+func cutLast(s, sep string) (string, string, bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+
+	return s[:i], s[i+len(sep):], true
+}
+
+// selectMatches groups match IDs by file in path order, ignoring duplicates. The
+// matches of a file must come from the same preview.
+func selectMatches(ids []string) ([]replaceSelection, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: no match IDs given", ErrInvalidEdit)
+	}
+
+	byFile := make(map[string]*replaceSelection)
+	for _, id := range ids {
+		file, digest, nth, ok := parseMatchID(id)
+		if !ok {
+			return nil, fmt.Errorf("%w: malformed match ID `%s`", ErrInvalidEdit, id)
+		}
+
+		sel, found := byFile[file]
+		if !found {
+			sel = &replaceSelection{file: file, digest: digest}
+			byFile[file] = sel
+		}
+
+		if sel.digest != digest {
+			return nil, fmt.Errorf("%w: match IDs of `%s` come from different previews", ErrInvalidEdit, file)
+		}
+
+		if !slices.Contains(sel.nths, nth) {
+			sel.ids = append(sel.ids, id)
+			sel.nths = append(sel.nths, nth)
+		}
+	}
+
+	selections := make([]replaceSelection, 0, len(byFile))
+	for _, sel := range byFile {
+		slices.Sort(sel.nths)
+		selections = append(selections, *sel)
+	}
+
+	slices.SortFunc(selections, func(a, b replaceSelection) int { return strings.Compare(a.file, b.file) })
+
+	return selections, nil
+}
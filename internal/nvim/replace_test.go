@@ -0,0 +1,169 @@
+package nvim
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestParseMatchID(t *testing.T) {
+	t.Run("round trips", func(t *testing.T) {
+		file, digest, nth, ok := parseMatchID(matchID(filepath.Join("a:b", "c.go"), "0123abcd", 3))
+
+		require.True(t, ok)
+		assert.Equal(t, "a:b/c.go", file)
+		assert.Equal(t, "0123abcd", digest)
+		assert.Equal(t, 3, nth)
+	})
+
+	for _, id := range []string{"", "c.go", "c.go:3", "c.go:ab:0", "c.go:ab:x", ":ab:1", "../c.go:ab:1", "/etc/c.go:ab:1"} {
+		t.Run("rejects "+id, func(t *testing.T) {
+			_, _, _, ok := parseMatchID(id)
+
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestSelectMatches(t *testing.T) {
+	t.Run("groups by file", func(t *testing.T) {
+		selections, err := selectMatches([]string{"b.go:d1:2", "a.go:d2:1", "b.go:d1:1", "b.go:d1:2"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []replaceSelection{
+			{file: "a.go", digest: "d2", ids: []string{"a.go:d2:1"}, nths: []int{1}},
+			{file: "b.go", digest: "d1", ids: []string{"b.go:d1:2", "b.go:d1:1"}, nths: []int{1, 2}},
+		}, selections)
+	})
+
+	t.Run("rejects mixed previews", func(t *testing.T) {
+		_, err := selectMatches([]string{"a.go:d1:1", "a.go:d2:2"})
+
+		assert.ErrorIs(t, err, ErrInvalidEdit)
+	})
+
+	t.Run("rejects empty and malformed IDs", func(t *testing.T) {
+		_, err := selectMatches(nil)
+		assert.ErrorIs(t, err, ErrInvalidEdit)
+
+		_, err = selectMatches([]string{"nope"})
+		assert.ErrorIs(t, err, ErrInvalidEdit)
+	})
+}
+
+func TestReplacementExpander(t *testing.T) {
+	lines := []string{"old_name := 1"}
+
+	re, err := grepRegexp(`(?P<base>\w+)_name`, types.GrepOptions{})
+	require.NoError(t, err)
+
+	m := regexMatches(lines, re, -1)[0]
+
+	assert.Equal(t, "new_old", replacementExpander(re, "new_${base}", types.SearchFlavorRE2)(lines[0], m.index))
+	assert.Equal(t, "new_old", replacementExpander(re, "new_$1", "")(lines[0], m.index))
+	assert.Equal(t, "$1", replacementExpander(re, "$1", types.SearchFlavorLiteral)(lines[0], m.index))
+}
+
+func TestContentDigest(t *testing.T) {
+	assert.Equal(t, contentDigest("one\ntwo"), contentDigest("one\r\ntwo\r"))
+	assert.Equal(t, contentDigest("one\ntwo"), contentDigest("one\r\ntwo"))
+	assert.NotEqual(t, contentDigest("one\ntwo"), contentDigest("one\rtwo"))
+}
+
+func TestGrepRun_Replacements(t *testing.T) {
+	root := writeTree(t, map[string]string{"a.go": "foo(1)\nfoo(2)\n", "b.go": "bar\n"})
+
+	re, err := grepRegexp(`foo\((\d)\)`, types.GrepOptions{})
+	require.NoError(t, err)
+
+	run, err := newGrepRun(context.Background(), root, re, nil, nil, types.GrepOptions{})
+	require.NoError(t, err)
+
+	run.expand = replacementExpander(re, "baz($1)", "")
+	require.NoError(t, run.walk("", nil))
+
+	hits, truncated, _ := run.page()
+
+	require.Len(t, hits, 2)
+	assert.False(t, truncated)
+	assert.Equal(t, "baz(2)", hits[1].replacement)
+	assert.Equal(t, 2, hits[1].nth)
+	assert.Equal(t, contentDigest("foo(1)\nfoo(2)"), hits[0].digest)
+}
+
+func TestClient_FindReplace(t *testing.T) {
+	client, cleanup := setupTestNeovim(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	root := writeTree(t, map[string]string{
+		"a.txt": "old one\nold two\n",
+		"b.txt": "old three\n",
+		"c.txt": "old four\n",
+	})
+	opts := types.GrepOptions{Root: root, Flavor: types.SearchFlavorLiteral}
+
+	preview, err := client.PreviewFindReplace(ctx, "old", "new", opts)
+	require.NoError(t, err)
+	require.Len(t, preview.Matches, 4)
+	assert.Equal(t, "new", preview.Matches[0].Replacement)
+
+	// c.txt changes on disk after the preview
+	require.NoError(t, os.WriteFile(filepath.Join(root, "c.txt"), []byte("old changed\n"), 0o644))
+
+	accepted := []string{preview.Matches[1].ID, preview.Matches[2].ID, preview.Matches[3].ID}
+
+	result, err := client.ApplyFindReplace(ctx, "old", "new", accepted, opts)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, result.Replaced)
+	require.Len(t, result.Files, 2)
+	assert.Equal(t, "a.txt", result.Files[0].File)
+	assert.Positive(t, result.Files[0].UndoSeq)
+	require.Len(t, result.Skipped, 1)
+	assert.Equal(t, "c.txt", result.Skipped[0].File)
+
+	lines, _, err := client.GetBufferLines(ctx, filepath.Join(root, "a.txt"), 1, -1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old one", "new two"}, lines)
+
+	lines, _, err = client.GetBufferLines(ctx, filepath.Join(root, "b.txt"), 1, -1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new three"}, lines)
+
+	// applying again finds the buffers changed by the first apply
+	result, err = client.ApplyFindReplace(ctx, "old", "new", accepted[:1], opts)
+	require.NoError(t, err)
+	assert.Empty(t, result.Files)
+	assert.Len(t, result.Skipped, 1)
+	t.Run("applies to files with CRLF line ends", func(t *testing.T) {
+		crlf := writeTree(t, map[string]string{
+			"dos.txt":   "old one\r\nold two\r\n",
+			"mixed.txt": "old one\r\nold two\n",
+		})
+		opts := types.GrepOptions{Root: crlf, Flavor: types.SearchFlavorLiteral}
+
+		preview, err := client.PreviewFindReplace(ctx, "old", "new", opts)
+		require.NoError(t, err)
+		require.Len(t, preview.Matches, 4)
+
+		ids := []string{preview.Matches[0].ID, preview.Matches[2].ID}
+
+		result, err := client.ApplyFindReplace(ctx, "old", "new", ids, opts)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, result.Replaced)
+		assert.Empty(t, result.Skipped)
+
+		lines, _, err := client.GetBufferLines(ctx, filepath.Join(crlf, "dos.txt"), 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"new one", "old two"}, lines)
+	})
+}
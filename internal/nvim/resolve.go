@@ -0,0 +1,94 @@
+package nvim
+
+import (
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+// resolveBufferRef finds the buffer addressed by ref among names (buffer handle to name).
+// A reference is tried, in order, as a buffer handle, a file:// URI, an absolute path,
+// a path relative to cwd and finally as a unique file name or trailing path. A number
+// that is not the handle of a buffer is resolved as a path, such as a file named 2024.
+func resolveBufferRef(ref, cwd string, names map[nvim.Buffer]string) (nvim.Buffer, error) {
+	if ref == "" {
+		return 0, ErrBufferNotFound
+	}
+
+	if handle, err := strconv.Atoi(ref); err == nil {
+		if _, ok := names[nvim.Buffer(handle)]; ok {
+			return nvim.Buffer(handle), nil
+		}
+	}
+
+	path := ref
+	if u, err := url.Parse(ref); err == nil && u.Scheme == "file" {
+		path = u.Path
+	}
+
+	if buf, ok := findBufferByPath(bufferPathCandidates(path, cwd), names); ok {
+		return buf, nil
+	}
+
+	suffix := "/" + strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "/")
+
+	var matches []nvim.Buffer
+	for buf, name := range names {
+		if name != "" && strings.HasSuffix(filepath.ToSlash(name), suffix) {
+			matches = append(matches, buf)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return 0, ErrBufferNotFound
+	case 1:
+		return matches[0], nil
+	}
+
+	candidates := make([]string, 0, len(matches))
+	for _, buf := range matches {
+		candidates = append(candidates, names[buf])
+	}
+
+	slices.Sort(candidates)
+
+	return 0, &AmbiguousBufferError{Reference: ref, Candidates: candidates}
+}
+
+// bufferPathCandidates returns the full paths a buffer path reference may refer to
+func bufferPathCandidates(path, cwd string) []string {
+	if !filepath.IsAbs(path) {
+		if cwd == "" {
+			return nil
+		}
+
+		path = filepath.Join(cwd, path)
+	}
+
+	path = filepath.Clean(path)
+	candidates := []string{path}
+
+	if resolved, err := filepath.EvalSymlinks(path); err == nil && resolved != path {
+		candidates = append(candidates, resolved)
+	}
+
+	return candidates
+}
+
+// findBufferByPath returns the buffer whose name exactly matches one of paths
+func findBufferByPath(paths []string, names map[nvim.Buffer]string) (nvim.Buffer, bool) {
+	for _, path := range paths {
+		for buf, name := range names {
+			if name != "" && filepath.Clean(name) == path {
+				return buf, true
+			}
+		}
+	}
+
+	return 0, false
+}
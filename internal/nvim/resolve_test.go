@@ -0,0 +1,69 @@
+package nvim
+
+import (
+	"testing"
+
+	"github.com/neovim/go-client/nvim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveBufferRef(t *testing.T) {
+	names := map[nvim.Buffer]string{
+		1: "",
+		2: "/project/cmd/main.go",
+		3: "/project/internal/main.go",
+		4: "/project/README.md",
+		5: "/project/docs/my notes.md",
+		6: "/project/logs/2024",
+	}
+
+	tests := []struct {
+		name    string
+		ref     string
+		cwd     string
+		want    nvim.Buffer
+		wantErr error
+	}{
+		{name: "buffer handle", ref: "3", cwd: "/project", want: 3},
+		{name: "unknown buffer handle", ref: "42", cwd: "/project", wantErr: ErrBufferNotFound},
+		{name: "numeric file name", ref: "2024", cwd: "/elsewhere", want: 6},
+		{name: "numeric relative path", ref: "2024", cwd: "/project/logs", want: 6},
+		{name: "absolute path", ref: "/project/cmd/main.go", cwd: "/", want: 2},
+		{name: "unclean absolute path", ref: "/project/cmd/../internal/main.go", cwd: "/", want: 3},
+		{name: "cwd relative path", ref: "internal/main.go", cwd: "/project", want: 3},
+		{name: "dot relative path", ref: "./cmd/main.go", cwd: "/project", want: 2},
+		{name: "file uri", ref: "file:///project/cmd/main.go", cwd: "/", want: 2},
+		{name: "escaped file uri", ref: "file:///project/docs/my%20notes.md", cwd: "/", want: 5},
+		{name: "unique file name", ref: "README.md", cwd: "/elsewhere", want: 4},
+		{name: "unique trailing path", ref: "cmd/main.go", cwd: "/elsewhere", want: 2},
+		{name: "ambiguous file name", ref: "main.go", cwd: "/elsewhere", wantErr: ErrAmbiguousBuffer},
+		{name: "partial file name", ref: "ain.go", cwd: "/project", wantErr: ErrBufferNotFound},
+		{name: "unknown path", ref: "/other/main.go", cwd: "/project", wantErr: ErrBufferNotFound},
+		{name: "empty reference", ref: "", cwd: "/project", wantErr: ErrBufferNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveBufferRef(tt.ref, tt.cwd, names)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("ambiguous error lists sorted candidates", func(t *testing.T) {
+		_, err := resolveBufferRef("main.go", "/elsewhere", names)
+
+		var ambiguous *AmbiguousBufferError
+		require.ErrorAs(t, err, &ambiguous)
+		assert.Equal(t, "main.go", ambiguous.Reference)
+		assert.Equal(t, []string{"/project/cmd/main.go", "/project/internal/main.go"}, ambiguous.Candidates)
+		assert.Contains(t, err.Error(), "/project/cmd/main.go, /project/internal/main.go")
+	})
+}
@@ -0,0 +1,67 @@
+package nvim
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/neovim/go-client/nvim"
+
+	"github.com/cousine/neovim-mcp/internal/logger"
+)
+
+// InterruptKeys are sent to neovim to abort the operation of a cancelled call
+const InterruptKeys = "<C-c>"
+
+// rpc runs fn, which performs one or more neovim rpc calls on the current connection,
+// until it completes or ctx is done. go-client calls cannot be cancelled, so on
+// cancellation rpc returns immediately, leaving fn to finish in the background, and
+// interrupts neovim so the pending request is aborted and the editor is usable again.
+// Callers must not use values written by fn when rpc returns an error.
+func (c *Client) rpc(ctx context.Context, fn func(v *nvim.Nvim) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	v, err := c.conn()
+	if err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(v)
+	}()
+
+	select {
+	case err = <-done:
+		if isConnectionError(err) {
+			c.disconnect(v, err)
+			return fmt.Errorf("%w: %w", ErrNotConnected, err)
+		}
+
+		return err
+	case <-ctx.Done():
+		c.interrupt(v)
+		return ctx.Err()
+	}
+}
+
+// batch runs the calls queued by fill as a single atomic batch through rpc
+func (c *Client) batch(ctx context.Context, fill func(b *nvim.Batch)) error {
+	return c.rpc(ctx, func(v *nvim.Nvim) error {
+		b := v.NewBatch()
+		fill(b)
+
+		return b.Execute()
+	})
+}
+
+// interrupt sends InterruptKeys to neovim without waiting for the result. nvim_input is
+// processed even while neovim is busy handling another request or waiting at a prompt.
+func (c *Client) interrupt(v *nvim.Nvim) {
+	go func() {
+		if _, err := v.Input(InterruptKeys); err != nil {
+			logger.Debug("nvim: failed to interrupt neovim", "error", err)
+		}
+	}()
+}
@@ -0,0 +1,63 @@
+package nvim
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- RPC Cancellation Tests ---
+
+func TestClient_RPCCancellation(t *testing.T) {
+	client, cleanup := setupTestNeovim(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	t.Run("returns deadline exceeded for a blocked call", func(t *testing.T) {
+		timeout, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := client.ExecCommand(timeout, "while 1 | endwhile")
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 2*time.Second)
+	})
+
+	t.Run("interrupts neovim after a deadline", func(t *testing.T) {
+		// the interrupted loop must release neovim for the next call
+		require.Eventually(t, func() bool {
+			probe, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+			defer cancel()
+
+			out, err := client.ExecLua(probe, "return 1", nil)
+			return err == nil && out == int64(1)
+		}, 10*time.Second, 100*time.Millisecond)
+	})
+
+	t.Run("returns canceled for a cancelled call", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			cancel()
+		}()
+
+		_, err := client.ExecCommand(cancelled, "while 1 | endwhile")
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("keeps the client usable", func(t *testing.T) {
+		require.Eventually(t, func() bool {
+			probe, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+			defer cancel()
+
+			_, err := client.GetBuffers(probe)
+			return err == nil
+		}, 10*time.Second, 100*time.Millisecond)
+	})
+}
@@ -0,0 +1,322 @@
+package nvim
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/neovim/go-client/nvim"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// DefaultSearchResults is the number of matches Search returns without a limit
+const DefaultSearchResults = 100
+
+// luaSearch finds up to limit non-overlapping matches of a Vim regex in buf. It searches
+// in a window showing buf, or the autocmd window, and restores its cursor and view as
+// well as the search register and highlighting. Each match is returned as its start
+// and end (inclusive) positions with the matchlist() of the lines it spans.
+const luaSearch = `
+	local buf, pattern, ignore_case, limit = ...
+	local r = { tick = vim.api.nvim_buf_get_changedtick(buf), matches = {}, error = '' }
+	vim.api.nvim_buf_call(buf, function()
+		local view = vim.fn.winsaveview()
+		local register, hlsearch = vim.fn.getreg('/'), vim.v.hlsearch
+		local pat = (ignore_case and '\\c' or '\\C') .. pattern
+		local ok, err = pcall(function()
+			vim.api.nvim_win_set_cursor(0, { 1, 0 })
+			local flags = 'cW'
+			while #r.matches < limit do
+				local s = vim.fn.searchpos(pat, flags)
+				if s[1] == 0 then
+					break
+				end
+				local e = vim.fn.searchpos(pat, 'ceW')
+				local text = table.concat(vim.api.nvim_buf_get_lines(buf, s[1] - 1, e[1], false), '\n')
+				table.insert(r.matches, { s[1], s[2], e[1], e[2], vim.fn.matchlist(text, pat, s[2] - 1) })
+				flags = 'W'
+			end
+		end)
+		vim.fn.winrestview(view)
+		vim.fn.setreg('/', register)
+		vim.v.hlsearch = hlsearch
+		if not ok then
+			r.error = tostring(err)
+		end
+	end)
+	return r
+`
+
+// vimSearchOutcome is the result of luaSearch
+type vimSearchOutcome struct {
+	Tick    int     `msgpack:"tick"`
+	Matches [][]any `msgpack:"matches"`
+	Error   string  `msgpack:"error"`
+}
+
+// searchMatch is a match in a buffer as 0-based rows and byte columns, end exclusive
+type searchMatch struct {
+	byteRange
+	text   string
+	groups []string
+	// index holds the submatch offsets in the joined lines, only set by regexMatches
+	index []int
+}
+
+// Search finds the matches of a pattern in a buffer, the current buffer for an empty
+// title, without moving the cursor or changing the search register. Vim regexes are
+// matched by neovim, literal strings and RE2 regexes over the buffer text, where they
+// can span lines and ^ and $ match at line boundaries.
+func (c *Client) Search(ctx context.Context, title, pattern string, opts types.SearchOptions) (types.SearchResults, error) {
+	if err := ctx.Err(); err != nil {
+		return types.SearchResults{}, fmt.Errorf("failed to search: %w", err)
+	}
+
+	if pattern == "" {
+		return types.SearchResults{}, fmt.Errorf("failed to search: %w: the pattern is empty", ErrInvalidPattern)
+	}
+
+	var re *regexp.Regexp
+
+	switch opts.Flavor {
+	case "", types.SearchFlavorVim:
+	case types.SearchFlavorLiteral, types.SearchFlavorRE2:
+		var err error
+		if re, err = compileSearch(pattern, opts); err != nil {
+			return types.SearchResults{}, fmt.Errorf("failed to search: %w", err)
+		}
+	default:
+		return types.SearchResults{}, fmt.Errorf("failed to search: %w: unknown flavor `%s`, expected %s, %s or %s", ErrInvalidPattern,
+			opts.Flavor, types.SearchFlavorVim, types.SearchFlavorLiteral, types.SearchFlavorRE2)
+	}
+
+	if opts.MaxResults <= 0 {
+		opts.MaxResults = DefaultSearchResults
+	}
+
+	buf, err := c.bufferOrCurrent(ctx, title)
+	if err != nil {
+		return types.SearchResults{}, fmt.Errorf("failed to search: %w", err)
+	}
+
+	for range editAttempts {
+		lines, tick, rerr := c.readBuffer(ctx, buf.Handle)
+		if rerr != nil {
+			return types.SearchResults{}, fmt.Errorf("failed to search buffer `%s`: %w", buf.Title, rerr)
+		}
+
+		// one match past the limit tells whether results were left out
+		limit := opts.MaxResults + 1
+
+		matches := regexMatches(lines, re, limit)
+		if re == nil {
+			var current int
+			if matches, current, rerr = c.vimMatches(ctx, buf.Handle, lines, pattern, opts.IgnoreCase, limit); rerr != nil {
+				return types.SearchResults{}, fmt.Errorf("failed to search buffer `%s`: %w", buf.Title, rerr)
+			}
+
+			if current != tick {
+				continue
+			}
+		}
+
+		return searchResults(lines, matches, tick, opts), nil
+	}
+
+	return types.SearchResults{}, fmt.Errorf("failed to search buffer `%s`: %w", buf.Title, ErrBufferChanged)
+}
+
+// ----------------------------------------------------------------------------
+
+// compileSearch compiles a literal or RE2 search pattern for multi-line matching
+func compileSearch(pattern string, opts types.SearchOptions) (*regexp.Regexp, error) {
+	if opts.Flavor == types.SearchFlavorLiteral {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+
+	flags := "(?m)"
+	if opts.IgnoreCase {
+		flags = "(?mi)"
+	}
+
+	re, err := regexp.Compile(flags + pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPattern, err)
+	}
+
+	return re, nil
+}
+
+// regexMatches returns up to limit matches of re in lines, none for a nil re
+func regexMatches(lines []string, re *regexp.Regexp, limit int) []searchMatch {
+	if re == nil {
+		return nil
+	}
+
+	content := strings.Join(lines, "\n")
+
+	var matches []searchMatch
+	for _, idx := range re.FindAllStringSubmatchIndex(content, limit) {
+		m := searchMatch{text: content[idx[0]:idx[1]], index: idx}
+		m.startRow, m.startCol = positionAt(content, idx[0])
+		m.endRow, m.endCol = positionAt(content, idx[1])
+
+		for g := 1; g < len(idx)/2; g++ {
+			group := ""
+			if idx[2*g] >= 0 {
+				group = content[idx[2*g]:idx[2*g+1]]
+			}
+
+			m.groups = append(m.groups, group)
+		}
+
+		matches = append(matches, m)
+	}
+
+	return matches
+}
+
+// vimMatches returns up to limit matches of a Vim regex in the buffer and the
+// changedtick they were found at, lines are the buffer lines read before
+func (c *Client) vimMatches(ctx context.Context, buf nvim.Buffer, lines []string, pattern string, ignoreCase bool, limit int) ([]searchMatch, int, error) {
+	var outcome vimSearchOutcome
+
+	err := c.rpc(ctx, func(v *nvim.Nvim) error {
+		return v.ExecLua(luaSearch, &outcome, buf, pattern, ignoreCase, limit)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if outcome.Error != "" {
+		return nil, 0, fmt.Errorf("%w: %s", ErrInvalidPattern, outcome.Error)
+	}
+
+	matches := make([]searchMatch, 0, len(outcome.Matches))
+	for _, raw := range outcome.Matches {
+		m, ok := vimMatch(lines, raw)
+		if !ok {
+			// lines changed since they were read, the changedtick tells the caller
+			return nil, -1, nil
+		}
+
+		matches = append(matches, m)
+	}
+
+	return matches, outcome.Tick, nil
+}
+
+// vimMatch converts a match of luaSearch, the start and inclusive end position (1-based)
+// followed by its matchlist(), which gives the exact match text when it is not empty
+func vimMatch(lines []string, raw []any) (searchMatch, bool) {
+	if len(raw) != 5 {
+		return searchMatch{}, false
+	}
+
+	var pos [4]int
+	for i := range pos {
+		n, ok := toInt(raw[i])
+		if !ok {
+			return searchMatch{}, false
+		}
+
+		pos[i] = n
+	}
+
+	m := searchMatch{byteRange: byteRange{startRow: pos[0] - 1, startCol: pos[1] - 1, endRow: pos[2] - 1, endCol: pos[3] - 1}}
+	if m.startRow < 0 || m.endRow >= len(lines) || m.startCol > len(lines[m.startRow]) || m.endCol > len(lines[m.endRow]) {
+		return searchMatch{}, false
+	}
+
+	list, _ := raw[4].([]any)
+	for _, item := range list {
+		s, _ := item.(string)
+		m.groups = append(m.groups, s)
+	}
+
+	if len(m.groups) > 0 {
+		m.text, m.groups = m.groups[0], trimGroups(m.groups[1:])
+
+		rows, col := positionAt(m.text, len(m.text))
+		if rows == 0 {
+			col += m.startCol
+		}
+
+		m.endRow, m.endCol = m.startRow+rows, col
+
+		return m, m.endRow < len(lines)
+	}
+
+	// the end position is the start of the last matched character
+	_, size := utf8.DecodeRuneInString(lines[m.endRow][m.endCol:])
+	m.endCol += size
+	m.text = rangeText(lines, m.byteRange)
+
+	return m, true
+}
+
+// rangeText returns the text of lines covered by rng
+func rangeText(lines []string, rng byteRange) string {
+	if rng.startRow == rng.endRow {
+		return lines[rng.startRow][rng.startCol:rng.endCol]
+	}
+
+	text := []string{lines[rng.startRow][rng.startCol:]}
+	text = append(text, lines[rng.startRow+1:rng.endRow]...)
+
+	return strings.Join(append(text, lines[rng.endRow][:rng.endCol]), "\n")
+}
+
+// trimGroups drops the unused groups matchlist() pads its result with
+func trimGroups(groups []string) []string {
+	for len(groups) > 0 && groups[len(groups)-1] == "" {
+		groups = groups[:len(groups)-1]
+	}
+
+	return groups
+}
+
+// toInt converts a msgpack integer decoded into an any
+func toInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int64:
+		return int(n), true
+	case uint64:
+		return int(n), true
+	default:
+		return 0, false
+	}
+}
+
+// searchResults reports matches with their context, matches may hold one match past
+// the limit to flag truncated results
+func searchResults(lines []string, matches []searchMatch, tick int, opts types.SearchOptions) types.SearchResults {
+	results := types.SearchResults{Matches: []types.SearchResult{}, Version: tick}
+
+	if len(matches) > opts.MaxResults {
+		matches, results.Truncated = matches[:opts.MaxResults], true
+	}
+
+	for _, m := range matches {
+		result := types.SearchResult{
+			Line:      m.startRow + 1,
+			Column:    m.startCol + 1,
+			EndLine:   m.endRow + 1,
+			EndColumn: m.endCol + 1,
+			MatchText: m.text,
+			Groups:    m.groups,
+		}
+
+		if n := opts.ContextLines; n > 0 {
+			result.Before = lines[max(m.startRow-n, 0):m.startRow]
+			result.After = lines[min(m.endRow+1, len(lines)):min(m.endRow+1+n, len(lines))]
+		}
+
+		results.Matches = append(results.Matches, result)
+	}
+
+	return results
+}
@@ -0,0 +1,134 @@
+package nvim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestRegexMatches(t *testing.T) {
+	lines := []string{"let a = 1", "let bb = 22", "const c = 3"}
+
+	t.Run("returns spans and groups", func(t *testing.T) {
+		re, err := compileSearch(`let (\w+) = (\d+)`, types.SearchOptions{Flavor: types.SearchFlavorRE2})
+		require.NoError(t, err)
+
+		matches := regexMatches(lines, re, 10)
+
+		require.Len(t, matches, 2)
+		assert.Equal(t, searchMatch{
+			byteRange: byteRange{startRow: 1, startCol: 0, endRow: 1, endCol: 11},
+			text:      "let bb = 22",
+			groups:    []string{"bb", "22"},
+			index:     []int{10, 21, 14, 16, 19, 21},
+		}, matches[1])
+	})
+
+	t.Run("anchors at line boundaries", func(t *testing.T) {
+		re, err := compileSearch(`\d$`, types.SearchOptions{Flavor: types.SearchFlavorRE2})
+		require.NoError(t, err)
+
+		assert.Len(t, regexMatches(lines, re, 10), 3)
+	})
+
+	t.Run("spans lines", func(t *testing.T) {
+		re, err := compileSearch("1\nlet", types.SearchOptions{Flavor: types.SearchFlavorLiteral})
+		require.NoError(t, err)
+
+		matches := regexMatches(lines, re, 10)
+
+		require.Len(t, matches, 1)
+		assert.Equal(t, byteRange{startRow: 0, startCol: 8, endRow: 1, endCol: 3}, matches[0].byteRange)
+	})
+
+	t.Run("quotes literal patterns and ignores case", func(t *testing.T) {
+		re, err := compileSearch("LET A", types.SearchOptions{Flavor: types.SearchFlavorLiteral, IgnoreCase: true})
+		require.NoError(t, err)
+
+		assert.Len(t, regexMatches(lines, re, 10), 1)
+
+		re, err = compileSearch("a.=", types.SearchOptions{Flavor: types.SearchFlavorLiteral})
+		require.NoError(t, err)
+
+		assert.Empty(t, regexMatches(lines, re, 10))
+	})
+
+	t.Run("stops at the limit", func(t *testing.T) {
+		re, err := compileSearch("=", types.SearchOptions{Flavor: types.SearchFlavorRE2})
+		require.NoError(t, err)
+
+		assert.Len(t, regexMatches(lines, re, 2), 2)
+	})
+
+	t.Run("rejects invalid regexes", func(t *testing.T) {
+		_, err := compileSearch("(", types.SearchOptions{Flavor: types.SearchFlavorRE2})
+
+		assert.ErrorIs(t, err, ErrInvalidPattern)
+	})
+}
+
+func TestVimMatch(t *testing.T) {
+	lines := []string{"héllo world", "foo", "bar"}
+
+	t.Run("uses the matchlist text", func(t *testing.T) {
+		m, ok := vimMatch(lines, []any{int64(1), int64(8), int64(1), int64(12), []any{"world", "wor", "", ""}})
+
+		require.True(t, ok)
+		assert.Equal(t, byteRange{startRow: 0, startCol: 7, endRow: 0, endCol: 12}, m.byteRange)
+		assert.Equal(t, "world", m.text)
+		assert.Equal(t, []string{"wor"}, m.groups)
+	})
+
+	t.Run("spans lines", func(t *testing.T) {
+		m, ok := vimMatch(lines, []any{int64(2), int64(2), int64(3), int64(1), []any{"oo\nb"}})
+
+		require.True(t, ok)
+		assert.Equal(t, byteRange{startRow: 1, startCol: 1, endRow: 2, endCol: 1}, m.byteRange)
+	})
+
+	t.Run("falls back to the end position", func(t *testing.T) {
+		m, ok := vimMatch(lines, []any{int64(1), int64(1), int64(1), int64(2), []any{}})
+
+		require.True(t, ok)
+		assert.Equal(t, byteRange{startRow: 0, startCol: 0, endRow: 0, endCol: 3}, m.byteRange)
+		assert.Equal(t, "hé", m.text)
+	})
+
+	t.Run("rejects positions past the lines", func(t *testing.T) {
+		_, ok := vimMatch(lines, []any{int64(4), int64(1), int64(4), int64(1), []any{}})
+
+		assert.False(t, ok)
+	})
+}
+
+func TestSearchResults(t *testing.T) {
+	lines := []string{"a", "b", "c", "d"}
+	matches := []searchMatch{
+		{byteRange: byteRange{startRow: 0, endRow: 0, endCol: 1}, text: "a"},
+		{byteRange: byteRange{startRow: 2, endRow: 2, endCol: 1}, text: "c"},
+	}
+
+	t.Run("flags truncated results", func(t *testing.T) {
+		results := searchResults(lines, matches, 7, types.SearchOptions{MaxResults: 1})
+
+		assert.Equal(t, types.SearchResults{
+			Matches:   []types.SearchResult{{Line: 1, Column: 1, EndLine: 1, EndColumn: 2, MatchText: "a"}},
+			Truncated: true,
+			Version:   7,
+		}, results)
+	})
+
+	t.Run("adds context lines", func(t *testing.T) {
+		results := searchResults(lines, matches, 7, types.SearchOptions{MaxResults: 2, ContextLines: 1})
+
+		require.Len(t, results.Matches, 2)
+		assert.Empty(t, results.Matches[0].Before)
+		assert.Equal(t, []string{"b"}, results.Matches[0].After)
+		assert.Equal(t, []string{"b"}, results.Matches[1].Before)
+		assert.Equal(t, []string{"d"}, results.Matches[1].After)
+		assert.False(t, results.Truncated)
+	})
+}
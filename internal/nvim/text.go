@@ -0,0 +1,401 @@
+package nvim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/neovim/go-client/nvim"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+// editAttempts is how often an edit is recomputed when the buffer changes under it
+const editAttempts = 3
+
+// luaSetText applies text edits sorted by position unless the buffer changed since
+// changedtick, the edits are applied last to first so earlier positions stay valid.
+// It returns the new changedtick or -1 when the buffer changed.
+const luaSetText = `
+	local buf, tick, edits = ...
+	if vim.api.nvim_buf_get_changedtick(buf) ~= tick then
+		return -1
+	end
+	for i = #edits, 1, -1 do
+		local e = edits[i]
+		vim.api.nvim_buf_set_text(buf, e[1], e[2], e[3], e[4], e[5])
+	end
+	return vim.api.nvim_buf_get_changedtick(buf)
+`
+
+// errStaleBuffer is reported by setText when the buffer changed since it was read
+var errStaleBuffer = errors.New("buffer changed since it was read")
+
+// textEdit replaces the text of a byte range with lines
+type textEdit struct {
+	byteRange
+	lines []string
+}
+
+// ReplaceText replaces old text with new text in a buffer and returns the replaced ranges.
+// The old text must occur exactly once unless opts selects an occurrence or all of them,
+// and opts.ExpectedVersion fails the edit when the buffer changed since that version.
+func (c *Client) ReplaceText(ctx context.Context, title, oldText, newText string, opts types.ReplaceTextOptions) (types.ReplaceTextResult, error) {
+	if err := ctx.Err(); err != nil {
+		return types.ReplaceTextResult{}, fmt.Errorf("failed to replace text: %w", err)
+	}
+
+	if oldText == "" {
+		return types.ReplaceTextResult{}, fmt.Errorf("failed to replace text in buffer `%s`: %w: old text is empty", title, ErrTextNotFound)
+	}
+
+	buf, err := c.GetBufferByTitle(ctx, title)
+	if err != nil {
+		return types.ReplaceTextResult{}, fmt.Errorf("failed to replace text in buffer `%s`: %w", title, err)
+	}
+
+	for range editAttempts {
+		lines, tick, rerr := c.readBuffer(ctx, buf.Handle)
+		if rerr != nil {
+			return types.ReplaceTextResult{}, fmt.Errorf("failed to replace text in buffer `%s`: %w", title, rerr)
+		}
+
+		content := strings.Join(lines, "\n")
+
+		if opts.ExpectedVersion != 0 && tick != opts.ExpectedVersion {
+			conflict := occurrenceConflict(title, opts.ExpectedVersion, tick, lines, oldText)
+			return types.ReplaceTextResult{}, fmt.Errorf("failed to replace text in buffer `%s`: %w", title, conflict)
+		}
+
+		offsets, serr := selectOccurrences(content, oldText, opts)
+		if serr != nil {
+			return types.ReplaceTextResult{}, fmt.Errorf("failed to replace text in buffer `%s`: %w", title, serr)
+		}
+
+		edits, result := replaceOccurrences(content, oldText, newText, offsets)
+
+		result.Version, err = c.setText(ctx, buf.Handle, tick, edits)
+		if !errors.Is(err, errStaleBuffer) {
+			if err != nil {
+				return types.ReplaceTextResult{}, fmt.Errorf("failed to replace text in buffer `%s`: %w", title, err)
+			}
+
+			return result, nil
+		}
+	}
+
+	return types.ReplaceTextResult{}, fmt.Errorf("failed to replace text in buffer `%s`: %w", title, ErrBufferChanged)
+}
+
+// GetText returns the text of a buffer between two 1-based positions and the buffer
+// version it was read at, the end column is exclusive and columns are counted in the
+// position encoding
+func (c *Client) GetText(ctx context.Context, title string, rng types.TextRange, encoding string) (string, int, error) {
+	if err := ctx.Err(); err != nil {
+		return "", 0, fmt.Errorf("failed to get text: %w", err)
+	}
+
+	if err := ValidateEncoding(encoding); err != nil {
+		return "", 0, fmt.Errorf("failed to get text from buffer `%s`: %w", title, err)
+	}
+
+	buf, err := c.GetBufferByTitle(ctx, title)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to get text from buffer `%s`: %w", title, err)
+	}
+
+	lines, byteRng, version, err := c.readRange(ctx, buf.Handle, rng, encoding)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to get text from buffer `%s`: %w", title, err)
+	}
+
+	last := len(lines) - 1
+	lines[last] = lines[last][:byteRng.endCol]
+	lines[0] = lines[0][byteRng.startCol:]
+
+	return strings.Join(lines, "\n"), version, nil
+}
+
+// SetText replaces the text of a buffer between two 1-based positions and returns the
+// range of the new text and the new buffer version. Columns are counted in the position
+// encoding and the edit is retried when the buffer changes while the columns are
+// converted, unless a non-zero expected version makes the change a conflict.
+func (c *Client) SetText(ctx context.Context, title string, rng types.TextRange, text, encoding string, expectedVersion int) (types.TextRange, int, error) {
+	if err := ctx.Err(); err != nil {
+		return types.TextRange{}, 0, fmt.Errorf("failed to set text: %w", err)
+	}
+
+	if err := ValidateEncoding(encoding); err != nil {
+		return types.TextRange{}, 0, fmt.Errorf("failed to set text in buffer `%s`: %w", title, err)
+	}
+
+	buf, err := c.GetBufferByTitle(ctx, title)
+	if err != nil {
+		return types.TextRange{}, 0, fmt.Errorf("failed to set text in buffer `%s`: %w", title, err)
+	}
+
+	for range editAttempts {
+		lines, byteRng, tick, rerr := c.readRange(ctx, buf.Handle, rng, encoding)
+		if rerr != nil {
+			return types.TextRange{}, 0, fmt.Errorf("failed to set text in buffer `%s`: %w", title, rerr)
+		}
+
+		if expectedVersion != 0 && tick != expectedVersion {
+			conflict := &VersionConflictError{Buffer: title, Expected: expectedVersion, Current: tick, StartLine: rng.StartLine, Lines: lines}
+			return types.TextRange{}, 0, fmt.Errorf("failed to set text in buffer `%s`: %w", title, conflict)
+		}
+
+		version, serr := c.setText(ctx, buf.Handle, tick, []textEdit{{byteRange: byteRng, lines: strings.Split(text, "\n")}})
+		if !errors.Is(serr, errStaleBuffer) {
+			if serr != nil {
+				return types.TextRange{}, 0, fmt.Errorf("failed to set text in buffer `%s`: %w", title, serr)
+			}
+
+			return insertedRange(rng.StartLine, rng.StartColumn, text, encoding), version, nil
+		}
+	}
+
+	return types.TextRange{}, 0, fmt.Errorf("failed to set text in buffer `%s`: %w", title, ErrBufferChanged)
+}
+
+// ----------------------------------------------------------------------------
+
+// readRange reads the lines spanned by rng and converts rng to byte columns
+func (c *Client) readRange(ctx context.Context, buf nvim.Buffer, rng types.TextRange, encoding string) ([]string, byteRange, int, error) {
+	if rng.StartLine < 1 || rng.EndLine < rng.StartLine {
+		return nil, byteRange{}, 0, fmt.Errorf("%w: lines %d to %d", ErrInvalidRange, rng.StartLine, rng.EndLine)
+	}
+
+	var (
+		byteLines [][]byte
+		lineCount int
+		tick      int
+	)
+
+	err := c.batch(ctx, func(b *nvim.Batch) {
+		b.BufferLines(buf, rng.StartLine-1, rng.EndLine, false, &byteLines)
+		b.BufferLineCount(buf, &lineCount)
+		b.BufferChangedTick(buf, &tick)
+	})
+	if err != nil {
+		return nil, byteRange{}, 0, fmt.Errorf("failed to read buffer: %w", err)
+	}
+
+	lines := make([]string, len(byteLines))
+	for i, line := range byteLines {
+		lines[i] = string(line)
+	}
+
+	byteRng, err := toByteRange(rng, lines, lineCount, encoding)
+	if err != nil {
+		return nil, byteRange{}, 0, err
+	}
+
+	return lines, byteRng, tick, nil
+}
+
+// readBuffer returns the lines and changedtick of a buffer from a single snapshot
+func (c *Client) readBuffer(ctx context.Context, buf nvim.Buffer) ([]string, int, error) {
+	var (
+		byteLines [][]byte
+		tick      int
+	)
+
+	err := c.batch(ctx, func(b *nvim.Batch) {
+		b.BufferLines(buf, 0, -1, true, &byteLines)
+		b.BufferChangedTick(buf, &tick)
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read buffer: %w", err)
+	}
+
+	lines := make([]string, len(byteLines))
+	for i, line := range byteLines {
+		lines[i] = string(line)
+	}
+
+	return lines, tick, nil
+}
+
+// setText applies edits sorted by position and returns the new changedtick, failing
+// with errStaleBuffer when the buffer changed since changedtick tick
+func (c *Client) setText(ctx context.Context, buf nvim.Buffer, tick int, edits []textEdit) (int, error) {
+	luaEdits := make([]any, len(edits))
+	for i, e := range edits {
+		luaEdits[i] = []any{e.startRow, e.startCol, e.endRow, e.endCol, e.lines}
+	}
+
+	var version int
+	err := c.rpc(ctx, func(v *nvim.Nvim) error {
+		return v.ExecLua(luaSetText, &version, buf, tick, luaEdits)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to set text: %w", err)
+	}
+
+	if version < 0 {
+		return 0, errStaleBuffer
+	}
+
+	return version, nil
+}
+
+// conflictError reads the current lines start to end (1-based, inclusive) of a buffer
+// to report a write expecting an outdated version
+func (c *Client) conflictError(ctx context.Context, buf nvim.Buffer, title string, expected, start, end int) error {
+	var (
+		byteLines [][]byte
+		tick      int
+	)
+
+	err := c.batch(ctx, func(b *nvim.Batch) {
+		b.BufferLines(buf, max(start-1, 0), end, false, &byteLines)
+		b.BufferChangedTick(buf, &tick)
+	})
+	if err != nil {
+		return fmt.Errorf("%w: buffer `%s` changed since version %d", ErrVersionConflict, title, expected)
+	}
+
+	lines := make([]string, len(byteLines))
+	for i, line := range byteLines {
+		lines[i] = string(line)
+	}
+
+	return &VersionConflictError{Buffer: title, Expected: expected, Current: tick, StartLine: max(start, 1), Lines: lines}
+}
+
+// occurrenceConflict reports a write expecting an outdated version with the lines where
+// the old text occurs now, or without lines when it no longer occurs exactly once
+func occurrenceConflict(title string, expected, current int, lines []string, oldText string) *VersionConflictError {
+	conflict := &VersionConflictError{Buffer: title, Expected: expected, Current: current}
+
+	content := strings.Join(lines, "\n")
+
+	offsets := findOccurrences(content, oldText)
+	if oldText == "" || len(offsets) != 1 {
+		return conflict
+	}
+
+	startRow, _ := positionAt(content, offsets[0])
+	endRow, _ := positionAt(content, offsets[0]+len(oldText))
+
+	conflict.StartLine = startRow + 1
+	conflict.Lines = lines[startRow : endRow+1]
+
+	return conflict
+}
+
+// findOccurrences returns the byte offsets of the non-overlapping occurrences of text
+func findOccurrences(content, text string) []int {
+	var offsets []int
+
+	for start := 0; ; {
+		i := strings.Index(content[start:], text)
+		if i < 0 {
+			return offsets
+		}
+
+		offsets = append(offsets, start+i)
+		start += i + len(text)
+	}
+}
+
+// selectOccurrences returns the offsets of the occurrences of text chosen by opts
+func selectOccurrences(content, text string, opts types.ReplaceTextOptions) ([]int, error) {
+	offsets := findOccurrences(content, text)
+
+	switch {
+	case len(offsets) == 0:
+		return nil, fmt.Errorf("%w: the old text does not occur in the buffer", ErrTextNotFound)
+	case opts.ReplaceAll:
+		return offsets, nil
+	case opts.Occurrence > len(offsets) || opts.Occurrence < 0:
+		return nil, fmt.Errorf("%w: occurrence %d requested but the old text occurs %d times",
+			ErrTextNotFound, opts.Occurrence, len(offsets))
+	case opts.Occurrence > 0:
+		return offsets[opts.Occurrence-1 : opts.Occurrence], nil
+	case len(offsets) > 1:
+		return nil, fmt.Errorf("%w: the old text occurs %d times (lines %s), add context to make it unique or pass an occurrence or replace_all",
+			ErrAmbiguousText, len(offsets), occurrenceLines(content, offsets))
+	default:
+		return offsets, nil
+	}
+}
+
+// occurrenceLines lists the 1-based lines of the offsets
+func occurrenceLines(content string, offsets []int) string {
+	lines := make([]string, len(offsets))
+	for i, offset := range offsets {
+		line, _ := positionAt(content, offset)
+		lines[i] = strconv.Itoa(line + 1)
+	}
+
+	return strings.Join(lines, ", ")
+}
+
+// replaceOccurrences returns the edits replacing old text at offsets with new text and
+// the ranges of the replacements in the updated content
+func replaceOccurrences(content, oldText, newText string, offsets []int) ([]textEdit, types.ReplaceTextResult) {
+	newLines := strings.Split(newText, "\n")
+
+	edits := make([]textEdit, len(offsets))
+	ranges := make([]types.TextRange, len(offsets))
+
+	var updated strings.Builder
+	last := 0
+
+	for i, offset := range offsets {
+		startRow, startCol := positionAt(content, offset)
+		endRow, endCol := positionAt(content, offset+len(oldText))
+		edits[i] = textEdit{
+			byteRange: byteRange{startRow: startRow, startCol: startCol, endRow: endRow, endCol: endCol},
+			lines:     newLines,
+		}
+
+		updated.WriteString(content[last:offset])
+		start := updated.Len()
+		updated.WriteString(newText)
+		last = offset + len(oldText)
+
+		ranges[i] = textRange(updated.String(), start, updated.Len())
+	}
+
+	result := types.ReplaceTextResult{
+		Replacements: len(ranges),
+		Ranges:       ranges,
+	}
+
+	if len(ranges) > 0 {
+		result.Changed = types.TextRange{
+			StartLine:   ranges[0].StartLine,
+			StartColumn: ranges[0].StartColumn,
+			EndLine:     ranges[len(ranges)-1].EndLine,
+			EndColumn:   ranges[len(ranges)-1].EndColumn,
+		}
+	}
+
+	return edits, result
+}
+
+// textRange converts the byte offsets start and end of content to a 1-based range
+func textRange(content string, start, end int) types.TextRange {
+	startRow, startCol := positionAt(content, start)
+	endRow, endCol := positionAt(content, end)
+
+	return types.TextRange{
+		StartLine:   startRow + 1,
+		StartColumn: startCol + 1,
+		EndLine:     endRow + 1,
+		EndColumn:   endCol + 1,
+	}
+}
+
+// positionAt converts a byte offset of content to a 0-based row and byte column
+func positionAt(content string, offset int) (int, int) {
+	before := content[:offset]
+	row := strings.Count(before, "\n")
+
+	return row, offset - (strings.LastIndexByte(before, '\n') + 1)
+}
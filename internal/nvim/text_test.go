@@ -0,0 +1,262 @@
+package nvim
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cousine/neovim-mcp/internal/types"
+)
+
+func TestSelectOccurrences(t *testing.T) {
+	content := "foo := 1\nbar := foo\nfoo++"
+
+	tests := []struct {
+		name    string
+		text    string
+		opts    types.ReplaceTextOptions
+		want    []int
+		wantErr error
+	}{
+		{name: "unique", text: "bar", want: []int{9}},
+		{name: "missing", text: "baz", wantErr: ErrTextNotFound},
+		{name: "ambiguous", text: "foo", wantErr: ErrAmbiguousText},
+		{name: "occurrence", text: "foo", opts: types.ReplaceTextOptions{Occurrence: 2}, want: []int{16}},
+		{name: "occurrence out of range", text: "foo", opts: types.ReplaceTextOptions{Occurrence: 4}, wantErr: ErrTextNotFound},
+		{name: "all", text: "foo", opts: types.ReplaceTextOptions{ReplaceAll: true}, want: []int{0, 16, 20}},
+		{name: "multiline", text: "1\nbar", want: []int{7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectOccurrences(content, tt.text, tt.opts)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("lists ambiguous lines", func(t *testing.T) {
+		_, err := selectOccurrences(content, "foo", types.ReplaceTextOptions{})
+
+		assert.ErrorContains(t, err, "lines 1, 2, 3")
+	})
+
+	t.Run("does not overlap", func(t *testing.T) {
+		assert.Equal(t, []int{0, 2}, findOccurrences("aaaaa", "aa"))
+	})
+}
+
+func TestReplaceOccurrences(t *testing.T) {
+	t.Run("single line", func(t *testing.T) {
+		edits, result := replaceOccurrences("a foo b\nfoo", "foo", "quux", []int{2, 8})
+
+		assert.Equal(t, []textEdit{
+			{byteRange: byteRange{startRow: 0, startCol: 2, endRow: 0, endCol: 5}, lines: []string{"quux"}},
+			{byteRange: byteRange{startRow: 1, startCol: 0, endRow: 1, endCol: 3}, lines: []string{"quux"}},
+		}, edits)
+
+		assert.Equal(t, 2, result.Replacements)
+		assert.Equal(t, []types.TextRange{
+			{StartLine: 1, StartColumn: 3, EndLine: 1, EndColumn: 7},
+			{StartLine: 2, StartColumn: 1, EndLine: 2, EndColumn: 5},
+		}, result.Ranges)
+		assert.Equal(t, types.TextRange{StartLine: 1, StartColumn: 3, EndLine: 2, EndColumn: 5}, result.Changed)
+	})
+
+	t.Run("line count changes", func(t *testing.T) {
+		edits, result := replaceOccurrences("x\nold\ny\nold", "old", "new1\nnew2", []int{2, 8})
+
+		assert.Equal(t, textEdit{byteRange: byteRange{startRow: 3, startCol: 0, endRow: 3, endCol: 3}, lines: []string{"new1", "new2"}}, edits[1])
+		assert.Equal(t, []types.TextRange{
+			{StartLine: 2, StartColumn: 1, EndLine: 3, EndColumn: 5},
+			{StartLine: 5, StartColumn: 1, EndLine: 6, EndColumn: 5},
+		}, result.Ranges)
+	})
+
+	t.Run("deletion", func(t *testing.T) {
+		edits, result := replaceOccurrences("keep\ndrop\nkeep", "drop\n", "", []int{5})
+
+		assert.Equal(t, textEdit{byteRange: byteRange{startRow: 1, startCol: 0, endRow: 2, endCol: 0}, lines: []string{""}}, edits[0])
+		assert.Equal(t, types.TextRange{StartLine: 2, StartColumn: 1, EndLine: 2, EndColumn: 1}, result.Changed)
+	})
+}
+
+func TestOccurrenceConflict(t *testing.T) {
+	lines := []string{"a", "foo(", ")", "foo"}
+
+	conflict := occurrenceConflict("main.go", 3, 5, lines, "foo(\n)")
+	assert.Equal(t, 2, conflict.StartLine)
+	assert.Equal(t, []string{"foo(", ")"}, conflict.Lines)
+	assert.ErrorIs(t, conflict, ErrVersionConflict)
+	assert.ErrorContains(t, conflict, "version 5, not the expected 3, lines 2 to 3 now read:\nfoo(\n)")
+
+	conflict = occurrenceConflict("main.go", 3, 5, lines, "foo")
+	assert.Empty(t, conflict.Lines)
+	assert.ErrorContains(t, conflict, "read it again before writing")
+}
+
+func TestClient_ReplaceText(t *testing.T) {
+	client, cleanup := setupTestNeovim(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	open := func(t *testing.T, content string) string {
+		t.Helper()
+
+		tmpFile := createTempFile(t, content)
+		_, err := client.OpenBuffer(ctx, tmpFile)
+		require.NoError(t, err)
+
+		return filepath.Base(tmpFile)
+	}
+
+	t.Run("replaces unique text", func(t *testing.T) {
+		title := open(t, "func a() {\n\treturn 1\n}")
+
+		result, err := client.ReplaceText(ctx, title, "return 1", "return 2", types.ReplaceTextOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, types.TextRange{StartLine: 2, StartColumn: 2, EndLine: 2, EndColumn: 10}, result.Changed)
+
+		lines, _, err := client.GetBufferLines(ctx, title, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"func a() {", "\treturn 2", "}"}, lines)
+	})
+
+	t.Run("replaces across lines", func(t *testing.T) {
+		title := open(t, "one\ntwo\nthree")
+
+		_, err := client.ReplaceText(ctx, title, "one\ntwo", "1", types.ReplaceTextOptions{})
+		require.NoError(t, err)
+
+		lines, _, err := client.GetBufferLines(ctx, title, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1", "three"}, lines)
+	})
+
+	t.Run("rejects ambiguous text", func(t *testing.T) {
+		title := open(t, "x = 1\nx = 1")
+
+		_, err := client.ReplaceText(ctx, title, "x = 1", "x = 2", types.ReplaceTextOptions{})
+		assert.ErrorIs(t, err, ErrAmbiguousText)
+	})
+
+	t.Run("replaces all occurrences", func(t *testing.T) {
+		title := open(t, "x = 1\nx = 1")
+
+		result, err := client.ReplaceText(ctx, title, "1", "2", types.ReplaceTextOptions{ReplaceAll: true})
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Replacements)
+
+		lines, _, err := client.GetBufferLines(ctx, title, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"x = 2", "x = 2"}, lines)
+	})
+}
+
+func TestClient_GetText(t *testing.T) {
+	client, cleanup := setupTestNeovim(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	tmpFile := createTempFile(t, "let naïve = 1\nlet 😀 = 2")
+	_, err := client.OpenBuffer(ctx, tmpFile)
+	require.NoError(t, err)
+
+	title := filepath.Base(tmpFile)
+
+	t.Run("reads part of a line", func(t *testing.T) {
+		text, _, err := client.GetText(ctx, title, types.TextRange{StartLine: 1, StartColumn: 5, EndLine: 1, EndColumn: 10}, PositionEncodingCodepoint)
+		require.NoError(t, err)
+
+		assert.Equal(t, "naïve", text)
+	})
+
+	t.Run("reads across lines", func(t *testing.T) {
+		text, _, err := client.GetText(ctx, title, types.TextRange{StartLine: 1, StartColumn: 13, EndLine: 2, EndColumn: 8}, PositionEncodingUTF16)
+		require.NoError(t, err)
+
+		assert.Equal(t, "1\nlet 😀 ", text)
+	})
+
+	t.Run("rejects ranges past the end", func(t *testing.T) {
+		_, _, err := client.GetText(ctx, title, types.TextRange{StartLine: 2, StartColumn: 1, EndLine: 3, EndColumn: 1}, PositionEncodingByte)
+
+		assert.ErrorIs(t, err, ErrInvalidRange)
+	})
+}
+
+func TestClient_SetText(t *testing.T) {
+	client, cleanup := setupTestNeovim(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	open := func(t *testing.T, content string) string {
+		t.Helper()
+
+		tmpFile := createTempFile(t, content)
+		_, err := client.OpenBuffer(ctx, tmpFile)
+		require.NoError(t, err)
+
+		return filepath.Base(tmpFile)
+	}
+
+	t.Run("renames an identifier mid-line", func(t *testing.T) {
+		title := open(t, "x := résumé(a, b)")
+
+		changed, _, err := client.SetText(ctx, title, types.TextRange{StartLine: 1, StartColumn: 6, EndLine: 1, EndColumn: 12}, "cv", PositionEncodingUTF16, 0)
+		require.NoError(t, err)
+		assert.Equal(t, types.TextRange{StartLine: 1, StartColumn: 6, EndLine: 1, EndColumn: 8}, changed)
+
+		lines, _, err := client.GetBufferLines(ctx, title, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"x := cv(a, b)"}, lines)
+	})
+
+	t.Run("inserts lines", func(t *testing.T) {
+		title := open(t, "first\nlast")
+
+		changed, _, err := client.SetText(ctx, title, types.TextRange{StartLine: 1, StartColumn: 6, EndLine: 1, EndColumn: 6}, "\nmiddle", "", 0)
+		require.NoError(t, err)
+		assert.Equal(t, types.TextRange{StartLine: 1, StartColumn: 6, EndLine: 2, EndColumn: 7}, changed)
+
+		lines, _, err := client.GetBufferLines(ctx, title, 1, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"first", "middle", "last"}, lines)
+	})
+
+	t.Run("rejects unknown encodings", func(t *testing.T) {
+		title := open(t, "text")
+
+		_, _, err := client.SetText(ctx, title, types.TextRange{StartLine: 1, StartColumn: 1, EndLine: 1, EndColumn: 1}, "x", "utf-32", 0)
+		assert.ErrorIs(t, err, ErrInvalidEncoding)
+	})
+
+	t.Run("rejects outdated versions", func(t *testing.T) {
+		title := open(t, "one\ntwo")
+
+		_, version, err := client.GetText(ctx, title, types.TextRange{StartLine: 2, StartColumn: 1, EndLine: 2, EndColumn: 4}, "")
+		require.NoError(t, err)
+
+		_, _, err = client.SetText(ctx, title, types.TextRange{StartLine: 1, StartColumn: 1, EndLine: 1, EndColumn: 4}, "ONE", "", version)
+		require.NoError(t, err)
+
+		_, _, err = client.SetText(ctx, title, types.TextRange{StartLine: 2, StartColumn: 1, EndLine: 2, EndColumn: 4}, "TWO", "", version)
+
+		var conflict *VersionConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, []string{"two"}, conflict.Lines)
+	})
+}
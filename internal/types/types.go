@@ -3,6 +3,7 @@ package types
 
 import (
 	"context"
+	"time"
 
 	"github.com/neovim/go-client/nvim"
 )
@@ -19,16 +20,34 @@ type NeovimClient interface {
 	SwitchBuffer(ctx context.Context, title string) error
 
 	// Text operations
-	GetBufferLines(ctx context.Context, title string, start, end int) ([]string, error)
-	SetBufferLines(ctx context.Context, title string, start, end int, lines []string) error
-	InsertText(ctx context.Context, text string) error
-	DeleteLines(ctx context.Context, title string, start, end int) error
+	GetBufferLines(ctx context.Context, title string, start, end int) ([]string, int, error)
+	SetBufferLines(ctx context.Context, title string, start, end int, lines []string, expectedVersion int) (int, error)
+	InsertText(ctx context.Context, title, text string, opts InsertTextOptions) (InsertTextResult, error)
+	DeleteLines(ctx context.Context, title string, start, end int, expectedVersion int) (int, error)
+	ReplaceText(ctx context.Context, title, oldText, newText string, opts ReplaceTextOptions) (ReplaceTextResult, error)
+	GetText(ctx context.Context, title string, rng TextRange, encoding string) (string, int, error)
+	SetText(ctx context.Context, title string, rng TextRange, text, encoding string, expectedVersion int) (TextRange, int, error)
+	SetReindentedLines(ctx context.Context, title string, start, end int, lines []string, expectedVersion int) (TextRange, int, error)
+	ApplyPatch(ctx context.Context, patch string, expectedVersions map[string]int) (PatchResult, error)
+	ApplyEdits(ctx context.Context, edits []BufferEdit) (ApplyEditsResult, error)
+
+	// Anchor operations
+	CreateAnchor(ctx context.Context, name, title string, rng TextRange) (Anchor, error)
+	ResolveAnchor(ctx context.Context, name string) (Anchor, error)
 
 	// Cursor operations
 	GetCursorPosition(ctx context.Context) (CursorPosition, error)
 	SetCursorPosition(ctx context.Context, line, col int) error
 	GotoLine(ctx context.Context, line int) error
-	Search(ctx context.Context, pattern string, flags string) ([]SearchResult, error)
+	Search(ctx context.Context, title, pattern string, opts SearchOptions) (SearchResults, error)
+	GrepProject(ctx context.Context, pattern string, opts GrepOptions) (GrepResults, error)
+	PreviewFindReplace(ctx context.Context, pattern, replacement string, opts GrepOptions) (FindReplacePreview, error)
+	ApplyFindReplace(ctx context.Context, pattern, replacement string, ids []string, opts GrepOptions) (FindReplaceResult, error)
+
+	// LSP operations
+	FindLocations(ctx context.Context, kind, title string, opts LocationOptions) (LocationResults, error)
+	Hover(ctx context.Context, title string, opts LocationOptions) (HoverResult, error)
+	SignatureHelp(ctx context.Context, title string, opts LocationOptions) (SignatureHelpResult, error)
 
 	// Window operations
 	GetWindows(ctx context.Context) ([]WindowInfo, error)
@@ -38,10 +57,21 @@ type NeovimClient interface {
 
 	// Command operations
 	ExecCommand(ctx context.Context, command string) (string, error)
+	SendInput(ctx context.Context, keys string) error
+	FeedKeys(ctx context.Context, keys string, opts FeedKeysOptions) (FeedKeysResult, error)
 	ExecLua(ctx context.Context, code string, args []any) (any, error)
 	CallFunction(ctx context.Context, fname string, args []any) (any, error)
 
+	// Diagnostic operations
+	GetDiagnostics(ctx context.Context, bufferTitle string) ([]BufferDiagnostics, error)
+	WatchDiagnostics(ctx context.Context, onChange func()) (func(context.Context) error, error)
+
+	// Editor operations
+	GetEditorConfig(ctx context.Context) (EditorConfig, error)
+	GetPlugins(ctx context.Context) (PluginInventory, error)
+
 	// Lifecycle
+	GetConnectionStatus(ctx context.Context) (ConnectionStatus, error)
 	Close() error
 }
 
@@ -61,11 +91,357 @@ type CursorPosition struct {
 	Column int `json:"column" jsonschema:"cursor column number"`
 }
 
-// SearchResult represents a search match
+// Regex flavors of Search
+const (
+	SearchFlavorVim     = "vim"
+	SearchFlavorLiteral = "literal"
+	SearchFlavorRE2     = "re2"
+)
+
+// SearchOptions controls how Search matches a pattern and what it reports
+type SearchOptions struct {
+	Flavor       string // SearchFlavorVim (default), SearchFlavorLiteral or SearchFlavorRE2
+	IgnoreCase   bool   // match case-insensitively, otherwise case always matters
+	MaxResults   int    // maximum number of matches, 0 for the default
+	ContextLines int    // lines of context to return before and after each match
+}
+
+// SearchResult represents a search match (1-based positions, byte columns, end exclusive)
 type SearchResult struct {
-	Line      int    `json:"line" jsonschema:"line number where match was found"`
-	Column    int    `json:"column" jsonschema:"column number of match start"`
-	MatchText string `json:"match_text" jsonschema:"the matched text"`
+	Line      int      `json:"line" jsonschema:"line number where match was found"`
+	Column    int      `json:"column" jsonschema:"column number of match start"`
+	EndLine   int      `json:"end_line" jsonschema:"line number where the match ends"`
+	EndColumn int      `json:"end_column" jsonschema:"column after the match end (exclusive)"`
+	MatchText string   `json:"match_text" jsonschema:"the matched text"`
+	Groups    []string `json:"groups,omitempty" jsonschema:"text of the capture groups, empty for groups that did not take part"`
+	Before    []string `json:"context_before,omitempty" jsonschema:"lines before the match"`
+	After     []string `json:"context_after,omitempty" jsonschema:"lines after the match"`
+}
+
+// SearchResults lists the matches of Search in buffer order
+type SearchResults struct {
+	Matches   []SearchResult `json:"matches" jsonschema:"matches in buffer order"`
+	Truncated bool           `json:"truncated" jsonschema:"whether more matches were left out by the result limit"`
+	Version   int            `json:"version" jsonschema:"buffer version that was searched"`
+}
+
+// GrepOptions controls which files GrepProject searches and which matches it reports
+type GrepOptions struct {
+	Root         string // directory to search, relative to the neovim cwd, empty for the cwd
+	Glob         string // gitignore-style glob files must match, empty for all files
+	Flavor       string // SearchFlavorRE2 (default) or SearchFlavorLiteral
+	IgnoreCase   bool   // match case-insensitively, otherwise case always matters
+	ContextLines int    // lines of context to return before and after each match
+	Offset       int    // number of matches to skip, for paging
+	MaxResults   int    // maximum number of matches, 0 for the default
+}
+
+// GrepMatch is a match of GrepProject in a file
+type GrepMatch struct {
+	File    string `json:"file" jsonschema:"path of the file relative to the root"`
+	Unsaved bool   `json:"unsaved,omitempty" jsonschema:"whether the match comes from unsaved changes in a neovim buffer rather than the file on disk"`
+	SearchResult
+}
+
+// GrepResults lists a page of the matches of GrepProject in path order
+type GrepResults struct {
+	Root       string      `json:"root" jsonschema:"absolute path of the searched directory"`
+	Matches    []GrepMatch `json:"matches" jsonschema:"matches ordered by file path and position"`
+	Truncated  bool        `json:"truncated" jsonschema:"whether more matches follow this page"`
+	NextOffset int         `json:"next_offset,omitempty" jsonschema:"offset of the next page when truncated"`
+}
+
+// ReplaceMatch is a match of PreviewFindReplace with its replacement
+type ReplaceMatch struct {
+	ID string `json:"id" jsonschema:"match ID to accept when applying, it stays valid while the file content is unchanged"`
+	GrepMatch
+	Replacement string `json:"replacement" jsonschema:"text the match would be replaced with"`
+}
+
+// FindReplacePreview lists a page of the replacements PreviewFindReplace proposes
+type FindReplacePreview struct {
+	Root       string         `json:"root" jsonschema:"absolute path of the searched directory"`
+	Matches    []ReplaceMatch `json:"matches" jsonschema:"matches ordered by file path and position"`
+	Truncated  bool           `json:"truncated" jsonschema:"whether more matches follow this page"`
+	NextOffset int            `json:"next_offset,omitempty" jsonschema:"offset of the next page when truncated"`
+}
+
+// ReplacedFile reports the replacements ApplyFindReplace made in one file
+type ReplacedFile struct {
+	File     string      `json:"file" jsonschema:"path of the file relative to the root"`
+	Buffer   nvim.Buffer `json:"buffer" jsonschema:"handle of the buffer holding the file"`
+	Replaced int         `json:"replaced" jsonschema:"number of replaced matches"`
+	Version  int         `json:"version" jsonschema:"buffer version after the replacements"`
+	UndoSeq  int         `json:"undo_seq" jsonschema:"undo sequence number of the replacements, which form one undo step of the buffer"`
+}
+
+// SkippedFile is a file ApplyFindReplace left unchanged
+type SkippedFile struct {
+	File   string   `json:"file" jsonschema:"path of the file relative to the root"`
+	IDs    []string `json:"ids" jsonschema:"accepted match IDs that were not applied"`
+	Reason string   `json:"reason" jsonschema:"why the file was skipped"`
+}
+
+// FindReplaceResult describes the replacements made by ApplyFindReplace, changes are
+// written to buffers and left unsaved
+type FindReplaceResult struct {
+	Replaced int            `json:"replaced" jsonschema:"number of replaced matches"`
+	Files    []ReplacedFile `json:"files" jsonschema:"changed files in path order"`
+	Skipped  []SkippedFile  `json:"skipped,omitempty" jsonschema:"files left unchanged, such as files whose content changed since the preview"`
+}
+
+// Buffer versions are the b:changedtick of a buffer: read tools return the version they
+// read and writes given a non-zero expected version fail when the buffer changed since.
+
+// TextRange is a range of buffer text (1-based positions). Columns count bytes unless
+// a position encoding says otherwise.
+type TextRange struct {
+	StartLine   int `json:"start_line" jsonschema:"starting line number (1-based, inclusive)"`
+	StartColumn int `json:"start_column" jsonschema:"starting column (1-based, inclusive)"`
+	EndLine     int `json:"end_line" jsonschema:"ending line number (1-based, inclusive)"`
+	EndColumn   int `json:"end_column" jsonschema:"ending column (1-based, exclusive)"`
+}
+
+// ReplaceTextOptions selects the occurrences replaced by ReplaceText. Without options
+// the old text must occur exactly once.
+type ReplaceTextOptions struct {
+	// Occurrence is the 1-based occurrence to replace
+	Occurrence int
+	// ReplaceAll replaces every occurrence
+	ReplaceAll bool
+	// ExpectedVersion fails the replacement when the buffer version differs, 0 skips the check
+	ExpectedVersion int
+}
+
+// ReplaceTextResult describes where ReplaceText changed a buffer
+type ReplaceTextResult struct {
+	Replacements int         `json:"replacements" jsonschema:"number of replaced occurrences"`
+	Changed      TextRange   `json:"changed" jsonschema:"range spanning all replacements in the updated buffer"`
+	Ranges       []TextRange `json:"ranges" jsonschema:"range of each replacement in the updated buffer"`
+	Version      int         `json:"version" jsonschema:"buffer version after the replacement"`
+}
+
+// InsertTextOptions selects where InsertText puts its text. Without a line the text goes
+// at the cursor of a window showing the buffer.
+type InsertTextOptions struct {
+	Line     int    // 1-based line, 0 for the cursor line
+	Column   int    // 1-based column of a charwise insertion, 0 for the start of Line
+	Linewise bool   // insert the text as whole lines above or below the line
+	After    bool   // insert after the character or below the line instead of before it
+	Reindent bool   // re-indent the inserted lines by the indent rules of the buffer
+	Encoding string // how Column and the returned range count columns
+
+	ExpectedVersion int // fail with a version conflict unless the buffer is at this version
+}
+
+// InsertTextResult describes where InsertText put its text
+type InsertTextResult struct {
+	Changed TextRange `json:"changed" jsonschema:"range of the inserted text in the updated buffer"`
+	Version int       `json:"version" jsonschema:"buffer version after the insertion"`
+}
+
+// PatchResult describes how ApplyPatch applied a unified diff
+type PatchResult struct {
+	Applied  int               `json:"applied" jsonschema:"number of applied hunks"`
+	Rejected int               `json:"rejected" jsonschema:"number of rejected hunks"`
+	Files    []FilePatchResult `json:"files" jsonschema:"result of each file of the patch"`
+}
+
+// FilePatchResult describes how the hunks of one file were applied to its buffer
+type FilePatchResult struct {
+	Path    string       `json:"path" jsonschema:"path of the file in the patch"`
+	Buffer  nvim.Buffer  `json:"buffer,omitempty" jsonschema:"handle of the patched buffer"`
+	Version int          `json:"version,omitempty" jsonschema:"buffer version after the patch"`
+	Error   string       `json:"error,omitempty" jsonschema:"why no hunk of the file was applied"`
+	Hunks   []HunkResult `json:"hunks" jsonschema:"result of each hunk of the file"`
+}
+
+// HunkResult describes how a hunk was applied or why it was rejected
+type HunkResult struct {
+	Hunk              int        `json:"hunk" jsonschema:"hunk number within the file (1-based)"`
+	Header            string     `json:"header" jsonschema:"the @@ header of the hunk"`
+	Applied           bool       `json:"applied" jsonschema:"whether the hunk was applied"`
+	Offset            int        `json:"offset,omitempty" jsonschema:"lines between the position in the header and where the hunk applied"`
+	Fuzz              int        `json:"fuzz,omitempty" jsonschema:"context lines ignored at each end of the hunk"`
+	IgnoredWhitespace bool       `json:"ignored_whitespace,omitempty" jsonschema:"whether the hunk only matched ignoring whitespace"`
+	Changed           *TextRange `json:"changed,omitempty" jsonschema:"range of the new lines in the updated buffer"`
+	Reason            string     `json:"reason,omitempty" jsonschema:"why the hunk was rejected"`
+}
+
+// Kinds of BufferEdit
+const (
+	EditKindLines   = "lines"
+	EditKindRange   = "range"
+	EditKindReplace = "replace"
+)
+
+// BufferEdit is one edit of ApplyEdits. Kind selects the fields it uses: lines replaces
+// StartLine to EndLine with Lines, range replaces Range with Text and replace replaces
+// the unique OldText with NewText. Positions refer to the buffer after earlier edits,
+// and an Anchor stands for the buffer and the lines or range it covers.
+type BufferEdit struct {
+	BufferTitle string     `json:"buffer_title,omitempty" jsonschema:"buffer handle, absolute or cwd-relative path, file:// URI, or unique filename; not needed with an anchor"`
+	Anchor      string     `json:"anchor,omitempty" jsonschema:"name of an anchor whose buffer and lines (lines) or range (range) the edit targets"`
+	Kind        string     `json:"kind" jsonschema:"lines, range or replace"`
+	StartLine   int        `json:"start_line,omitempty" jsonschema:"lines: first line to replace (1-based)"`
+	EndLine     int        `json:"end_line,omitempty" jsonschema:"lines: last line to replace (1-based, inclusive), start_line - 1 inserts before start_line"`
+	Lines       []string   `json:"lines,omitempty" jsonschema:"lines: new lines, empty to delete"`
+	Range       *TextRange `json:"range,omitempty" jsonschema:"range: characters to replace"`
+	Text        string     `json:"text,omitempty" jsonschema:"range: new text, may contain newlines"`
+	Encoding    string     `json:"encoding,omitempty" jsonschema:"range: unit of the columns: byte (default), codepoint or utf-16"`
+	OldText     string     `json:"old_text,omitempty" jsonschema:"replace: exact text that must occur once in the buffer"`
+	NewText     string     `json:"new_text,omitempty" jsonschema:"replace: text to insert in place of old_text"`
+
+	ExpectedVersion int `json:"expected_version,omitempty" jsonschema:"buffer version returned by a read tool, the transaction fails with a version conflict if the buffer changed since"`
+}
+
+// EditedBuffer reports the edits ApplyEdits made to one buffer
+type EditedBuffer struct {
+	Buffer  nvim.Buffer `json:"buffer" jsonschema:"buffer handle"`
+	Name    string      `json:"name" jsonschema:"buffer name"`
+	Edits   int         `json:"edits" jsonschema:"number of edits applied to the buffer"`
+	Version int         `json:"version" jsonschema:"buffer version after the edits"`
+}
+
+// ApplyEditsResult describes the buffers changed by ApplyEdits
+type ApplyEditsResult struct {
+	Applied int            `json:"applied" jsonschema:"number of applied edits"`
+	Buffers []EditedBuffer `json:"buffers" jsonschema:"edited buffers in the order of their first edit"`
+}
+
+// Location kinds of FindLocations, each is an LSP textDocument request
+const (
+	LocationDefinition     = "definition"
+	LocationReferences     = "references"
+	LocationImplementation = "implementation"
+	LocationTypeDefinition = "type_definition"
+	LocationDeclaration    = "declaration"
+)
+
+// LocationOptions selects the position FindLocations, Hover and SignatureHelp ask the
+// language servers about: a workspace symbol, else a position of the buffer, else the cursor
+type LocationOptions struct {
+	Line               int           // 1-based line, 0 for the cursor of a window showing the buffer
+	Column             int           // 1-based column in Encoding, 0 for the line start or the cursor
+	Encoding           string        // unit of Column: byte (default), codepoint or utf-16
+	Symbol             string        // name of a workspace symbol to ask about instead of a position
+	IncludeDeclaration bool          // references: also return the declaration
+	Timeout            time.Duration // how long to wait for the language servers, 0 for the default
+}
+
+// Location is a range of a file returned by a language server
+type Location struct {
+	Path   string    `json:"path" jsonschema:"absolute path of the file"`
+	Range  TextRange `json:"range" jsonschema:"range of the location, columns count bytes"`
+	Line   string    `json:"line" jsonschema:"source line where the location starts"`
+	Client string    `json:"client" jsonschema:"name of the language server that returned the location"`
+}
+
+// LSPClientStatus tells whether a language server answered a request
+type LSPClientStatus struct {
+	Name     string `json:"name" jsonschema:"language server name"`
+	Answered bool   `json:"answered" jsonschema:"whether the server answered before the timeout"`
+	Error    string `json:"error,omitempty" jsonschema:"error returned by the server"`
+}
+
+// LocationResults lists the locations of FindLocations
+type LocationResults struct {
+	Locations []Location        `json:"locations" jsonschema:"locations in the order the servers returned them"`
+	Clients   []LSPClientStatus `json:"clients" jsonschema:"language servers asked"`
+	TimedOut  bool              `json:"timed_out" jsonschema:"whether the servers did not all answer in time, their locations are then missing"`
+}
+
+// Hover is the hover information a language server returned for a position
+type Hover struct {
+	Client   string `json:"client" jsonschema:"name of the language server"`
+	Contents string `json:"contents" jsonschema:"hover contents as markdown"`
+}
+
+// HoverResult lists the hover information of Hover
+type HoverResult struct {
+	Hovers   []Hover           `json:"hovers" jsonschema:"non-empty hover information of each server that answered"`
+	Clients  []LSPClientStatus `json:"clients" jsonschema:"language servers asked"`
+	TimedOut bool              `json:"timed_out" jsonschema:"whether the servers did not all answer in time"`
+}
+
+// SignatureParameter is a parameter of a callable signature
+type SignatureParameter struct {
+	Label         string `json:"label" jsonschema:"parameter label, a part of the signature label"`
+	Documentation string `json:"documentation,omitempty" jsonschema:"parameter documentation as markdown"`
+}
+
+// Signature is a signature of a callable returned by a language server
+type Signature struct {
+	Label         string               `json:"label" jsonschema:"signature label"`
+	Documentation string               `json:"documentation,omitempty" jsonschema:"signature documentation as markdown"`
+	Parameters    []SignatureParameter `json:"parameters" jsonschema:"signature parameters"`
+}
+
+// SignatureHelp is the signature help a language server returned for a position
+type SignatureHelp struct {
+	Client          string      `json:"client" jsonschema:"name of the language server"`
+	Signatures      []Signature `json:"signatures" jsonschema:"signatures of the callable, overloads included"`
+	ActiveSignature int         `json:"active_signature" jsonschema:"index of the active signature"`
+	ActiveParameter int         `json:"active_parameter" jsonschema:"index of the active parameter of the active signature, -1 for none"`
+	Signature       string      `json:"signature" jsonschema:"label of the active signature"`
+	Parameter       string      `json:"parameter,omitempty" jsonschema:"label of the active parameter"`
+}
+
+// SignatureHelpResult lists the signature help of SignatureHelp
+type SignatureHelpResult struct {
+	Help     []SignatureHelp   `json:"help" jsonschema:"signature help of each server that answered with signatures"`
+	Clients  []LSPClientStatus `json:"clients" jsonschema:"language servers asked"`
+	TimedOut bool              `json:"timed_out" jsonschema:"whether the servers did not all answer in time"`
+}
+
+// Anchor is a named range of a buffer backed by an extmark, it moves with the text
+// as lines are inserted or deleted around it
+type Anchor struct {
+	Name    string      `json:"name" jsonschema:"anchor name"`
+	Buffer  nvim.Buffer `json:"buffer" jsonschema:"handle of the anchored buffer"`
+	Path    string      `json:"path" jsonschema:"name of the anchored buffer"`
+	Range   TextRange   `json:"range" jsonschema:"current range of the anchor, columns count bytes"`
+	Lines   []string    `json:"lines" jsonschema:"current content of the lines spanned by the anchor"`
+	Version int         `json:"version" jsonschema:"buffer version the anchor was resolved at"`
+}
+
+// Methods of FeedKeys
+const (
+	FeedKeysNormal    = "normal"
+	FeedKeysTypeahead = "feedkeys"
+)
+
+// Editor states reported by FeedKeys
+const (
+	EditorStateNormal   = "normal"
+	EditorStatePending  = "pending"
+	EditorStateInsert   = "insert"
+	EditorStateVisual   = "visual"
+	EditorStateCmdline  = "cmdline"
+	EditorStateTerminal = "terminal"
+	EditorStateBlocked  = "blocked"
+)
+
+// FeedKeysOptions controls how FeedKeys runs keys. The normal method runs them with
+// :normal, which can also repeat them over a range of lines, and the feedkeys method
+// puts them in the typeahead and executes it.
+type FeedKeysOptions struct {
+	Method    string      // FeedKeysNormal (default) or FeedKeysTypeahead
+	Remap     bool        // apply mappings to the keys
+	Escape    bool        // end the keys with <Esc> to finish incomplete commands
+	Window    nvim.Window // window to run the keys in, 0 for the current window
+	StartLine int         // first line of the range of the normal method, 0 for none
+	EndLine   int         // last line of the range
+}
+
+// FeedKeysResult reports the modes around FeedKeys. State is the state the keys left
+// neovim in, neovim is then put back in normal mode.
+type FeedKeysResult struct {
+	ModeBefore string `json:"mode_before" jsonschema:"mode before the keys, as reported by mode()"`
+	ModeAfter  string `json:"mode_after" jsonschema:"mode the keys left neovim in"`
+	State      string `json:"state" jsonschema:"state the keys left neovim in: normal, pending, insert, visual, cmdline, terminal or blocked"`
+	Restored   bool   `json:"restored" jsonschema:"whether neovim had to be put back in normal mode"`
+	Mode       string `json:"mode" jsonschema:"mode neovim is in now"`
 }
 
 // WindowInfo contains information about a Neovim window
@@ -76,7 +452,102 @@ type WindowInfo struct {
 	Height int         `json:"height" jsonschema:"window height in rows"`
 }
 
+// Diagnostic represents a single vim.diagnostic entry (1-based positions)
+type Diagnostic struct {
+	Severity    string `json:"severity" jsonschema:"diagnostic severity: error, warning, info or hint"`
+	Source      string `json:"source,omitempty" jsonschema:"diagnostic source, usually the language server name"`
+	Code        string `json:"code,omitempty" jsonschema:"diagnostic code reported by the source"`
+	Message     string `json:"message" jsonschema:"diagnostic message"`
+	StartLine   int    `json:"start_line" jsonschema:"starting line number (1-based, inclusive)"`
+	StartColumn int    `json:"start_column" jsonschema:"starting column number (1-based, inclusive)"`
+	EndLine     int    `json:"end_line" jsonschema:"ending line number (1-based, inclusive)"`
+	EndColumn   int    `json:"end_column" jsonschema:"ending column number (1-based, exclusive)"`
+}
+
+// BufferDiagnostics groups the diagnostics reported for a single buffer
+type BufferDiagnostics struct {
+	Handle      nvim.Buffer  `json:"handle" jsonschema:"buffer handle/ID"`
+	Path        string       `json:"path" jsonschema:"full path to the file"`
+	Diagnostics []Diagnostic `json:"diagnostics" jsonschema:"diagnostics reported for the buffer"`
+}
+
+// EditorVersion describes the running Neovim version and API level
+type EditorVersion struct {
+	Version       string `json:"version" jsonschema:"neovim version string"`
+	Major         int    `json:"major" jsonschema:"major version number"`
+	Minor         int    `json:"minor" jsonschema:"minor version number"`
+	Patch         int    `json:"patch" jsonschema:"patch version number"`
+	Prerelease    bool   `json:"prerelease" jsonschema:"whether neovim is a prerelease build"`
+	APILevel      int    `json:"api_level" jsonschema:"current API level"`
+	APICompatible int    `json:"api_compatible" jsonschema:"lowest API level still compatible"`
+}
+
+// EditorConfig describes the configuration of the running Neovim instance
+type EditorConfig struct {
+	Version       EditorVersion  `json:"version" jsonschema:"neovim version information"`
+	InitFile      string         `json:"init_file" jsonschema:"path to the loaded init file ($MYVIMRC)"`
+	Runtimepath   []string       `json:"runtimepath" jsonschema:"runtimepath directories"`
+	ConfigDir     string         `json:"config_dir" jsonschema:"stdpath('config') directory"`
+	DataDir       string         `json:"data_dir" jsonschema:"stdpath('data') directory"`
+	StateDir      string         `json:"state_dir" jsonschema:"stdpath('state') directory"`
+	Leader        string         `json:"leader" jsonschema:"leader key (mapleader)"`
+	LocalLeader   string         `json:"local_leader" jsonschema:"local leader key (maplocalleader)"`
+	Cwd           string         `json:"cwd" jsonschema:"effective working directory of the current window"`
+	Buffer        string         `json:"buffer" jsonschema:"path of the current buffer"`
+	GlobalOptions map[string]any `json:"global_options" jsonschema:"global option values by name"`
+	BufferOptions map[string]any `json:"buffer_options" jsonschema:"current buffer local option values by name"`
+}
+
+// PluginInfo describes a plugin installed in the Neovim instance
+type PluginInfo struct {
+	Name     string   `json:"name" jsonschema:"plugin name"`
+	Path     string   `json:"path" jsonschema:"plugin install directory"`
+	Manager  string   `json:"manager" jsonschema:"how the plugin was found: lazy.nvim, packer, vim.pack, pack or runtimepath"`
+	Loaded   bool     `json:"loaded" jsonschema:"whether the plugin is loaded"`
+	Lazy     bool     `json:"lazy" jsonschema:"whether the plugin is lazy-loaded"`
+	Triggers []string `json:"triggers" jsonschema:"lazy-load triggers as kind:value, e.g. event:BufReadPre or cmd:Telescope"`
+	Version  string   `json:"version,omitempty" jsonschema:"version, tag or branch the plugin is pinned to"`
+	Commit   string   `json:"commit,omitempty" jsonschema:"checked out git commit"`
+	URL      string   `json:"url,omitempty" jsonschema:"plugin source url"`
+}
+
+// PluginInventory lists the detected plugin managers and installed plugins
+type PluginInventory struct {
+	Managers []string     `json:"managers" jsonschema:"detected plugin managers"`
+	Plugins  []PluginInfo `json:"plugins" jsonschema:"installed plugins"`
+}
+
+// ConnectionStatus describes the state and health of the connection to Neovim
+type ConnectionStatus struct {
+	State       string    `json:"state" jsonschema:"connection state: connected, connecting, disconnected or closed"`
+	Address     string    `json:"address" jsonschema:"neovim socket address"`
+	Responsive  bool      `json:"responsive" jsonschema:"whether neovim answered the last health check in time"`
+	ConnectedAt time.Time `json:"connected_at,omitzero" jsonschema:"when the current connection was established"`
+	LastCheck   time.Time `json:"last_check,omitzero" jsonschema:"when the last health check ran"`
+	Attempts    int       `json:"attempts" jsonschema:"failed connection attempts since the connection was lost"`
+	Reconnects  int       `json:"reconnects" jsonschema:"number of times the connection was re-established"`
+	LastError   string    `json:"last_error,omitempty" jsonschema:"last connection or health check error"`
+}
+
+// Instance is a named Neovim instance served by the MCP server
+type Instance struct {
+	Name    string
+	Address string
+	Client  NeovimClient
+}
+
+// InstanceInfo describes a Neovim instance to MCP clients
+type InstanceInfo struct {
+	Name       string           `json:"name" jsonschema:"instance name, passed as the instance argument of tools"`
+	Address    string           `json:"address" jsonschema:"neovim socket address"`
+	Selected   bool             `json:"selected" jsonschema:"whether tools use this instance when no instance is given"`
+	Connection ConnectionStatus `json:"connection" jsonschema:"state and health of the connection"`
+}
+
 // ServerMeta holds server-level metadata passed to tool handlers
 type ServerMeta struct {
-	NvimClient NeovimClient
+	// Instances lists the neovim instances in registration order
+	Instances []*Instance
+	// Selected is the name of the instance used when a request names none
+	Selected string
 }